@@ -0,0 +1,43 @@
+// Package browser opens web UI in the default browser of the user.
+package browser
+
+import (
+	"log/slog"
+	"os/exec"
+)
+
+// Opener opens url in a browser.
+type Opener interface {
+	Open(url string) error
+}
+
+// New returns opener for current platform or opener that does nothing when headless is true,
+// e.g. when app is running as a shared server without a desktop.
+func New(headless bool) Opener {
+	if headless {
+		return noop{}
+	}
+	return system{}
+}
+
+type noop struct{}
+
+func (noop) Open(url string) error {
+	slog.Info("headless mode, browser is not opened", "addr", url)
+	return nil
+}
+
+// system opens url with platform specific command from openCommand.
+type system struct{}
+
+func (system) Open(url string) error {
+	name, args := openCommand(url)
+	slog.Info("opening browser", "addr", url, "command", name)
+	// Start instead of Run, because some openers wait until the browser is closed.
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,5 @@
+package browser
+
+func openCommand(url string) (string, []string) {
+	return "open", []string{url}
+}
@@ -0,0 +1,7 @@
+//go:build !windows && !darwin
+
+package browser
+
+func openCommand(url string) (string, []string) {
+	return "xdg-open", []string{url}
+}
@@ -0,0 +1,6 @@
+package browser
+
+// rundll32 is used instead of "cmd /C start", because start treats & in url as command separator.
+func openCommand(url string) (string, []string) {
+	return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+}
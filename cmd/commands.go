@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/s-588/BOMViewer/cmd/config"
+	"github.com/s-588/BOMViewer/cmd/instance"
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	// Assigned in init because help command refers to commands map.
+	commands = map[string]command{
+		"serve": {
+			usage: "serve [--no-browser]",
+			help:  "start web server (default command)",
+			run:   serve,
+		},
+		"migrate": {
+			usage: "migrate up|down|status",
+			help:  "apply, roll back one or show database migrations",
+			run:   migrate,
+		},
+		"backup": {
+			usage: "backup [file]",
+			help:  "write copy of database to file, by default to backups folder in base directory",
+			run:   backup,
+		},
+		"restore": {
+			usage: "restore <file>",
+			help:  "replace database with backup, server must be stopped",
+			run:   restore,
+		},
+		"export": {
+			usage: "export [file]",
+			help:  "write catalog to JSON file or to stdout",
+			run:   exportCatalog,
+		},
+		"import": {
+			usage: "import [--replace] <file>",
+			help:  "load catalog from JSON file, --replace is refused while stock movements or orders exist",
+			run:   importCatalog,
+		},
+		"set-password": {
+			usage: "set-password [--clear]",
+			help:  "read web UI password from stdin and save its hash to config",
+			run:   setPassword,
+		},
+		"reindex-search": {
+			usage: "reindex-search",
+			help:  "rebuild full text search index",
+			run:   reindexSearch,
+		},
+		"check": {
+			usage: "check",
+			help:  "check database integrity",
+			run:   check,
+		},
+		"help": {
+			usage: "help",
+			help:  "show this message",
+			run: func(context.Context, *config.Config, []string) error {
+				usage()
+				return nil
+			},
+		},
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-28s %s\n", commands[name].usage, commands[name].help)
+	}
+}
+
+func migrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: " + commands["migrate"].usage)
+	}
+	return db.Migrate(ctx, cfg, args[0])
+}
+
+func backup(ctx context.Context, cfg *config.Config, args []string) error {
+	var path string
+	switch len(args) {
+	case 0:
+		dir := filepath.Join(cfg.BaseDirectory, "backups")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("can't create backups folder: %w", err)
+		}
+		path = filepath.Join(dir, fmt.Sprintf("backup_%s.db", time.Now().Format("2006-01-02_15-04-05")))
+	case 1:
+		path = args[0]
+	default:
+		return errors.New("usage: " + commands["backup"].usage)
+	}
+
+	repo, err := db.NewRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	if err := repo.Backup(ctx, path); err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+func restore(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: " + commands["restore"].usage)
+	}
+	// Hold the lock, so server can't be started while database file is replaced.
+	lock, port, err := instance.Acquire(cfg.BaseDirectory, "restore")
+	if err != nil {
+		if errors.Is(err, instance.ErrRunning) {
+			return fmt.Errorf("server is running on port %d, stop it before restore", port)
+		}
+		return err
+	}
+	defer lock.Release()
+
+	return db.Restore(ctx, cfg, args[0])
+}
+
+func exportCatalog(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: " + commands["export"].usage)
+	}
+
+	repo, err := db.NewRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	catalog, err := repo.ExportCatalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if len(args) == 1 {
+		out, err = os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
+
+func importCatalog(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	replace := flags.Bool("replace", false, "delete current data before loading")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: " + commands["import"].usage)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var catalog models.Catalog
+	if err := json.NewDecoder(file).Decode(&catalog); err != nil {
+		return fmt.Errorf("can't decode catalog: %w", err)
+	}
+
+	repo, err := db.NewRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return repo.ImportCatalog(ctx, catalog, *replace)
+}
+
+func setPassword(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("set-password", flag.ExitOnError)
+	clear := flags.Bool("clear", false, "remove password and disable authentication")
+	flags.Parse(args)
+
+	if *clear {
+		return cfg.SetPassword("")
+	}
+
+	fmt.Fprint(os.Stderr, "New password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("can't read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("empty password, use --clear to remove password")
+	}
+	return cfg.SetPassword(password)
+}
+
+func reindexSearch(ctx context.Context, cfg *config.Config, args []string) error {
+	repo, err := db.NewRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return repo.ReindexSearch(ctx)
+}
+
+func check(ctx context.Context, cfg *config.Config, args []string) error {
+	repo, err := db.NewRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	problems, err := repo.Check(ctx)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+	fmt.Println("ok")
+	return nil
+}
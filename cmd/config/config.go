@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
+	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,12 +23,31 @@ const (
 // 		database_name.db
 // 		uploads/
 
+// mu guards fields of config, settings page changes them while server goroutines read them.
+// Config is copied by value, so the mutex isn't its field.
+var mu sync.RWMutex
+
 type Config struct {
 	BaseDirectory string       `yaml:"base_directory,omitempty"`
 	WebUIPassword string       `yaml:"web_ui_password,omitempty"`
 	ServerCfg     ServerConfig `yaml:"server,omitempty"`
 	DBCfg         DBConfig     `yaml:"database,omitempty"`
 	LogCfg        LogConfig    `yaml:"log,omitempty"`
+	DigestCfg     DigestConfig `yaml:"reorder_digest,omitempty"`
+}
+
+// DigestConfig is daily digest of materials that have to be ordered. Digest is written to File
+// and posted as JSON to Webhook, it's off when both are empty.
+type DigestConfig struct {
+	File    string `yaml:"file,omitempty"`
+	Webhook string `yaml:"webhook,omitempty"`
+	// Time is local time of day in form HH:MM when digest is made.
+	Time string `yaml:"time,omitempty"`
+}
+
+// Enabled reports whether digest has a destination.
+func (c DigestConfig) Enabled() bool {
+	return c.File != "" || c.Webhook != ""
 }
 
 type LogConfig struct {
@@ -37,10 +58,16 @@ type ServerConfig struct {
 	// ServerPort is a port where server is running. Default is 0 and port is choosing by OS.
 	ServerPort int    `yaml:"server_port"`
 	UploadsDir string `yaml:"uploads_directory,omitempty"`
+	// Headless disables opening of browser on start and shutdown by closing of the browser tab.
+	// Set it when app is running as a shared server.
+	Headless bool `yaml:"headless"`
 }
 
 type DBConfig struct {
 	DBName string `yaml:"database_name,omitempty"`
+	// AppIndexing makes the app maintain search index instead of database triggers.
+	// Indexed text then also includes unit, products of material and attached file names.
+	AppIndexing bool `yaml:"app_search_indexing"`
 }
 
 var DefaultConfig = Config{
@@ -56,6 +83,9 @@ var DefaultConfig = Config{
 	LogCfg: LogConfig{
 		LogLevel: "INFO",
 	},
+	DigestCfg: DigestConfig{
+		Time: "08:00",
+	},
 }
 
 func NewConfig(cfgPath string) (*Config, error) {
@@ -138,11 +168,17 @@ func (cfg *Config) setDefaults() bool {
 		changed = true
 	}
 
+	if cfg.DigestCfg.Time == "" {
+		cfg.DigestCfg.Time = "08:00"
+		changed = true
+	}
+
 	return changed
 }
 
 func (cfg *Config) Save() error {
-	data, err := yaml.Marshal(cfg)
+	snapshot := cfg.Snapshot()
+	data, err := yaml.Marshal(snapshot)
 	if err != nil {
 		return fmt.Errorf("can't save config: %w", err)
 	}
@@ -150,12 +186,53 @@ func (cfg *Config) Save() error {
 	if err != nil {
 		return fmt.Errorf("can't save config: %w", err)
 	}
-	slog.Info("new config saved", "config", cfg)
+	slog.Info("new config saved", "config", snapshot)
 	return nil
 }
 
+// Snapshot returns copy of config that can be read while config is changed.
+func (cfg *Config) Snapshot() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return *cfg
+}
+
+// Digest returns current settings of reorder digest, it's safe to call from background workers.
+func (cfg *Config) Digest() DigestConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	return cfg.DigestCfg
+}
+
 func (cfg *Config) UpdateConfig(newCfg Config) error {
+	mu.Lock()
 	*cfg = newCfg
+	mu.Unlock()
+	return cfg.Save()
+}
+
+// HashPassword returns bcrypt hash of web UI password in form stored in WebUIPassword field.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("can't hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// SetPassword sets new web UI password and saves config. Empty password disables authentication.
+func (cfg *Config) SetPassword(password string) error {
+	var hash string
+	if password != "" {
+		var err error
+		hash, err = HashPassword(password)
+		if err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	cfg.WebUIPassword = hash
+	mu.Unlock()
 	return cfg.Save()
 }
 
@@ -168,32 +245,49 @@ func (cfg *Config) ResetConfig() error {
 
 func (cfg *Config) ResetField(field string) error {
 	slog.Debug("reseting config value", "field", field)
+	mu.Lock()
+	known := true
 	switch strings.ToLower(strings.TrimSpace(field)) {
 
 	case "base_directory":
 		cfg.BaseDirectory = DefaultConfig.BaseDirectory
-		return cfg.Save()
 
 	case "web_ui_password":
 		cfg.WebUIPassword = DefaultConfig.WebUIPassword
-		return cfg.Save()
 
 	case "log_level":
 		cfg.LogCfg.LogLevel = DefaultConfig.LogCfg.LogLevel
-		return cfg.Save()
 
 	case "server_port":
 		cfg.ServerCfg.ServerPort = DefaultConfig.ServerCfg.ServerPort
-		return cfg.Save()
+
+	case "headless":
+		cfg.ServerCfg.Headless = DefaultConfig.ServerCfg.Headless
 
 	case "uploads_directory":
 		cfg.ServerCfg.UploadsDir = DefaultConfig.ServerCfg.UploadsDir
-		return cfg.Save()
 
 	case "database_name":
 		cfg.DBCfg.DBName = DefaultConfig.DBCfg.DBName
-		return cfg.Save()
 
+	case "app_search_indexing":
+		cfg.DBCfg.AppIndexing = DefaultConfig.DBCfg.AppIndexing
+
+	case "digest_file":
+		cfg.DigestCfg.File = DefaultConfig.DigestCfg.File
+
+	case "digest_webhook":
+		cfg.DigestCfg.Webhook = DefaultConfig.DigestCfg.Webhook
+
+	case "digest_time":
+		cfg.DigestCfg.Time = DefaultConfig.DigestCfg.Time
+
+	default:
+		known = false
 	}
-	return nil
+	mu.Unlock()
+	if !known {
+		return nil
+	}
+	return cfg.Save()
 }
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// AttributesPageHandler renders page for managing attribute definitions.
+func (h *Handler) AttributesPageHandler(w http.ResponseWriter, r *http.Request) {
+	attributes, categories, err := h.attributesWithCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов: "+err.Error(), "error getting attributes", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.AttributesPage(attributes, categories)); err != nil {
+		slog.Error("cannot render attributes page", "error", err, "where", "AttributesPageHandler")
+	}
+}
+
+// AttributeNewHandler creates attribute and returns updated page.
+func (h *Handler) AttributeNewHandler(w http.ResponseWriter, r *http.Request) {
+	attribute, err := attributeFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid attribute", "error", err)
+		return
+	}
+	attribute, err = h.db.InsertAttribute(r.Context(), attribute)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка создания атрибута: "+err.Error(), "error creating attribute", "error", err)
+		return
+	}
+	h.renderAttributes(w, r, "атрибут «"+attribute.Name+"» создан")
+}
+
+// AttributeUpdateHandler changes attribute definition, values already set are kept.
+func (h *Handler) AttributeUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор атрибута", "invalid attribute ID", "error", err)
+		return
+	}
+	attribute, err := attributeFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid attribute", "error", err)
+		return
+	}
+	attribute.ID = id
+	if err := h.db.UpdateAttribute(r.Context(), attribute); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка изменения атрибута: "+err.Error(), "error updating attribute", "error", err)
+		return
+	}
+	h.renderAttributes(w, r, "атрибут «"+attribute.Name+"» сохранён")
+}
+
+// AttributeDeleteHandler deletes attribute with its values.
+func (h *Handler) AttributeDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор атрибута", "invalid attribute ID", "error", err)
+		return
+	}
+	if err := h.db.DeleteAttribute(r.Context(), id); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления атрибута: "+err.Error(), "error deleting attribute", "error", err)
+		return
+	}
+	h.renderAttributes(w, r, "атрибут удалён")
+}
+
+// AttributeFieldsHandler renders attribute inputs of material form for selected category,
+// values of material are kept for attributes that still apply.
+func (h *Handler) AttributeFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	attributes, categories, err := h.attributesWithCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов: "+err.Error(), "error getting attributes", "error", err)
+		return
+	}
+	categoryID, _ := strconv.ParseInt(r.URL.Query().Get("category_id"), 10, 64)
+	var values []models.AttributeValue
+	if materialID, err := strconv.ParseInt(r.URL.Query().Get("material_id"), 10, 64); err == nil && materialID != 0 {
+		material, err := h.db.GetMaterialByID(r.Context(), materialID)
+		if err != nil {
+			slog.Error("can't get material", "error", err, "where", "AttributeFieldsHandler")
+		}
+		values = material.Attributes
+	}
+	err = templates.AttributeFields(helpers.ApplicableAttributes(attributes, categories, categoryID), values).Render(r.Context(), w)
+	if err != nil {
+		slog.Error("can't render attribute fields", "error", err)
+	}
+}
+
+// propertiesFromRequest reads tags and values of attributes that apply to category.
+// Values of other attributes are dropped, so changing category removes attributes of old category.
+func (h *Handler) propertiesFromRequest(ctx context.Context, r *http.Request, categoryID int64) ([]string, []models.AttributeValue, error) {
+	attributes, categories, err := h.attributesWithCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var values []models.AttributeValue
+	for _, a := range helpers.ApplicableAttributes(attributes, categories, categoryID) {
+		value, err := helpers.ParseAttributeValue(a, r.FormValue(fmt.Sprintf("attr_%d", a.ID)))
+		if err != nil {
+			return nil, nil, err
+		}
+		if value != "" {
+			values = append(values, models.AttributeValue{AttributeID: a.ID, Value: value})
+		}
+	}
+	return helpers.ParseTags(r.FormValue("tags")), values, nil
+}
+
+func (h *Handler) attributesWithCategories(ctx context.Context) ([]models.Attribute, []models.Category, error) {
+	attributes, err := h.db.GetAllAttributes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	categories, err := h.db.GetAllCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attributes, categories, nil
+}
+
+func attributeFromRequest(r *http.Request) (models.Attribute, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.Attribute{}, errors.New("ошибка обработки формы")
+	}
+	attribute := models.Attribute{
+		Name:    strings.TrimSpace(r.PostFormValue("name")),
+		Kind:    r.PostFormValue("kind"),
+		Unit:    strings.TrimSpace(r.PostFormValue("unit")),
+		Options: helpers.ParseAttributeOptions(r.PostFormValue("options")),
+	}
+	if value := r.PostFormValue("category_id"); value != "" {
+		categoryID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return models.Attribute{}, errors.New("неверная категория")
+		}
+		attribute.CategoryID = categoryID
+	}
+	// Unit and options make sense only for their kinds, they aren't kept for others.
+	if attribute.Kind != models.AttributeNumber {
+		attribute.Unit = ""
+	}
+	if attribute.Kind != models.AttributeEnum {
+		attribute.Options = nil
+	}
+	return attribute, helpers.ValidateAttribute(attribute)
+}
+
+func (h *Handler) renderAttributes(w http.ResponseWriter, r *http.Request, msg string) {
+	attributes, categories, err := h.attributesWithCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов: "+err.Error(), "error getting attributes", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "attributes changed")
+	if err := templates.AttributesPage(attributes, categories).Render(r.Context(), w); err != nil {
+		slog.Error("can't render attributes", "error", err)
+	}
+}
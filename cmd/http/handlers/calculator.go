@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -68,33 +71,15 @@ func (h *Handler) CalculatorProductMaterialsHandler(w http.ResponseWriter, r *ht
 
 	if len(calculableMaterials) == 0 && len(nonCalculableMaterials) == 0 {
 		slog.Debug("no materials for calculator", "calculable materials", calculableMaterials, "non calculable materials", nonCalculableMaterials, "materials", product.Materials)
-		templates.CalculatorResults([]models.CalculationResult{}, []models.Material{}, productID, desiredQuantity).Render(r.Context(), w)
+		templates.CalculatorResults([]models.CalculationResult{}, []models.Material{}, nil, productID, desiredQuantity).Render(r.Context(), w)
 		return
 	}
 
-	// Parse remaining quantities from query parameters (this is the fix)
-	remainingQuantities := make(map[int64]float64)
-	for _, material := range calculableMaterials {
-		// Try to get remaining quantity from query params
-		remainingStr := r.URL.Query().Get("remaining_" + strconv.FormatInt(material.ID, 10))
-		if remainingStr != "" {
-			if remaining, err := strconv.ParseFloat(remainingStr, 64); err == nil && remaining >= 0 {
-				remainingQuantities[material.ID] = remaining
-				continue
-			}
-		}
-		// Default to 0 if not provided or invalid
-		remainingQuantities[material.ID] = 0
-	}
-
-	// Process calculable materials
-	var calculableResults []models.CalculationResult
-	if len(calculableMaterials) > 0 {
-		calculableResults = h.calculateMaterialRequirements(calculableMaterials, desiredQuantity, remainingQuantities, productID)
-	}
+	// Remaining quantities are read from query parameters
+	calculableResults := h.calculate(r, calculableMaterials, productID, desiredQuantity)
 
 	slog.Debug("results of calculator", "calculable results", calculableMaterials, "non calculable materials", nonCalculableMaterials, "calculable results", calculableResults)
-	templates.CalculatorResults(calculableResults, nonCalculableMaterials, productID, desiredQuantity).Render(r.Context(), w)
+	templates.CalculatorResults(calculableResults, nonCalculableMaterials, h.categoryRollups(r.Context(), calculableResults), productID, desiredQuantity).Render(r.Context(), w)
 }
 
 func (h *Handler) CalculatorCalculateHandler(w http.ResponseWriter, r *http.Request) {
@@ -109,12 +94,8 @@ func (h *Handler) CalculatorCalculateHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	dq := r.FormValue("desired_quantity")
-	if dq == "" {
-		dq = "1"
-	}
-	desiredQuantity, err := strconv.ParseInt(dq, 10, 64)
-	if err != nil || desiredQuantity <= 0 {
+	desiredQuantity, err := parseDesiredQuantity(r)
+	if err != nil {
 		helpers.SetAndLogError(w, http.StatusBadRequest, "Неверное желаемое количество", "invalid desired quantity in calculator calculate handler", "error", err)
 		return
 	}
@@ -129,24 +110,113 @@ func (h *Handler) CalculatorCalculateHandler(w http.ResponseWriter, r *http.Requ
 	// Split materials into calculable and non-calculable
 	calculableMaterials, nonCalculableMaterials := h.splitMaterialsByCalculability(materials)
 
-	// Parse remaining quantities from form (only for calculable materials)
+	// Calculate results only for calculable materials, remaining quantities are read from form
+	calculableResults := h.calculate(r, calculableMaterials, productID, desiredQuantity)
+
+	templates.CalculatorResults(calculableResults, nonCalculableMaterials, h.categoryRollups(r.Context(), calculableResults), productID, desiredQuantity).Render(r.Context(), w)
+}
+
+// calculate computes requirements of calculable materials for desired quantity of product,
+// remaining quantities are read from request.
+func (h *Handler) calculate(r *http.Request, materials []models.Material, productID, desiredQuantity int64) []models.CalculationResult {
+	if len(materials) == 0 {
+		return nil
+	}
+	// Remaining in other unit is converted by mass
+	masses := h.materialMasses(r.Context(), materials)
+	remainingQuantities, remainingUnits := h.parseRemaining(r, materials, masses)
+	results := h.calculateMaterialRequirements(materials, desiredQuantity, remainingQuantities, productID)
+	h.applyNesting(results, materials, desiredQuantity, remainingQuantities, masses)
+	h.addConversions(results, masses, remainingUnits)
+	return results
+}
+
+// parseDesiredQuantity reads desired quantity of products, missing quantity is 1.
+func parseDesiredQuantity(r *http.Request) (int64, error) {
+	dq := r.FormValue("desired_quantity")
+	if dq == "" {
+		return 1, nil
+	}
+	desiredQuantity, err := strconv.ParseInt(dq, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if desiredQuantity <= 0 {
+		return 0, errors.New("desired quantity must be positive")
+	}
+	return desiredQuantity, nil
+}
+
+// categoryRollups sums results by categories, rollups are omitted when categories can't be loaded.
+func (h *Handler) categoryRollups(ctx context.Context, results []models.CalculationResult) []helpers.CategoryRollup {
+	categories, err := h.db.GetAllCategories(ctx)
+	if err != nil {
+		slog.Error("can't get categories for calculator", "error", err)
+		return nil
+	}
+	return helpers.CategoryRollups(categories, results)
+}
+
+// parseRemaining reads remaining quantities in units of materials, invalid remaining is 0.
+// Remaining that isn't sent at all, e.g. when product is just chosen, is current balance of stock.
+// Remaining entered in other unit (remaining_unit_<id>) is converted by theoretical mass of material.
+func (h *Handler) parseRemaining(r *http.Request, materials []models.Material, masses map[int64]helpers.Mass) (map[int64]float64, map[int64]string) {
 	remainingQuantities := make(map[int64]float64)
-	for _, material := range calculableMaterials {
-		remainingStr := r.FormValue("remaining_" + strconv.FormatInt(material.ID, 10))
-		if remaining, err := strconv.ParseFloat(remainingStr, 64); err == nil && remaining >= 0 {
-			remainingQuantities[material.ID] = remaining
-		} else {
-			remainingQuantities[material.ID] = 0
+	remainingUnits := make(map[int64]string)
+	if err := r.ParseForm(); err != nil {
+		slog.Warn("can't parse remaining quantities", "error", err)
+	}
+	var stock map[int64]float64
+	for _, material := range materials {
+		id := strconv.FormatInt(material.ID, 10)
+		if _, sent := r.Form["remaining_"+id]; !sent {
+			if stock == nil {
+				stock = h.materialStock(r.Context(), materials)
+			}
+			remainingQuantities[material.ID] = stock[material.ID]
+			continue
+		}
+		remaining, err := h.parseQuantity(r.FormValue("remaining_" + id))
+		if err != nil || remaining < 0 {
+			remaining = 0
 		}
+		unit := r.FormValue("remaining_unit_" + id)
+		if unit != "" && unit != material.Unit.Name {
+			converted, ok := masses[material.ID].Convert(remaining, unit, material.Unit.Name)
+			if ok {
+				remaining = converted
+				remainingUnits[material.ID] = unit
+			}
+		}
+		remainingQuantities[material.ID] = remaining
 	}
+	return remainingQuantities, remainingUnits
+}
 
-	// Calculate results only for calculable materials
-	var calculableResults []models.CalculationResult
-	if len(calculableMaterials) > 0 {
-		calculableResults = h.calculateMaterialRequirements(calculableMaterials, desiredQuantity, remainingQuantities, productID)
+// addConversions converts totals of results to other units by theoretical mass,
+// remaining is shown in unit it was entered in.
+func (h *Handler) addConversions(results []models.CalculationResult, masses map[int64]helpers.Mass, remainingUnits map[int64]string) {
+	for i := range results {
+		result := &results[i]
+		mass, ok := masses[result.MaterialID]
+		units := mass.Units(result.Unit)
+		if !ok || len(units) == 0 {
+			continue
+		}
+		result.RemainingUnits = append([]string{result.Unit}, units...)
+		for _, unit := range units {
+			total, _ := mass.Convert(result.Total, result.Unit, unit)
+			shortage, _ := mass.Convert(result.Shortage, result.Unit, unit)
+			result.Conversions = append(result.Conversions, models.Conversion{Unit: unit, Total: total, Shortage: shortage})
+		}
+		if unit, ok := remainingUnits[result.MaterialID]; ok {
+			remaining, _ := h.parseQuantity(result.Remaining)
+			remaining, _ = mass.Convert(remaining, result.Unit, unit)
+			result.RemainingUnit = unit
+			// Round trip through unit of material must give back entered value.
+			result.Remaining = h.formatQuantity(math.Round(remaining*1000) / 1000)
+		}
 	}
-
-	templates.CalculatorResults(calculableResults, nonCalculableMaterials, productID, desiredQuantity).Render(r.Context(), w)
 }
 
 // splitMaterialsByCalculability separates materials into calculable (numeric quantities) and non-calculable (text quantities)
@@ -187,6 +257,7 @@ func (h *Handler) calculateMaterialRequirements(materials []models.Material, des
 				MaterialID:       material.ID,
 				MaterialName:     material.PrimaryName,
 				RequiredPerUnit:  material.Quantity,
+				RequiredNet:      "N/A",
 				RequiredTotal:    "N/A",
 				Remaining:        h.formatQuantity(remainingQuantities[material.ID]),
 				CanProduce:       0,
@@ -194,12 +265,16 @@ func (h *Handler) calculateMaterialRequirements(materials []models.Material, des
 				Unit:             material.Unit.Name,
 				ProductID:        productID,
 				IsCalculable:     false,
+				CategoryID:       material.Category.ID,
 			}
 			continue
 		}
 
 		remaining := remainingQuantities[material.ID]
-		totalRequired := requiredPerUnit * float64(desiredQuantity)
+		netRequired := requiredPerUnit * float64(desiredQuantity)
+		// Gross requirement covers technological waste of line or material.
+		yield := helpers.LineYield(material)
+		totalRequired := netRequired / yield
 		additionalNeeded := totalRequired - remaining
 		if additionalNeeded < 0 {
 			additionalNeeded = 0
@@ -208,20 +283,27 @@ func (h *Handler) calculateMaterialRequirements(materials []models.Material, des
 		// Calculate how many products can be produced with this material alone
 		canProduce := 0
 		if requiredPerUnit > 0 {
-			canProduce = int(remaining / requiredPerUnit)
+			canProduce = int(remaining * yield / requiredPerUnit)
 		}
 
 		results[i] = models.CalculationResult{
 			MaterialID:       material.ID,
 			MaterialName:     material.PrimaryName,
 			RequiredPerUnit:  material.Quantity,
+			RequiredNet:      h.formatQuantity(netRequired),
 			RequiredTotal:    h.formatQuantity(totalRequired),
+			YieldPercent:     math.Round(yield*10000) / 100,
 			Remaining:        h.formatQuantity(remaining),
 			CanProduce:       canProduce,
 			AdditionalNeeded: h.formatQuantity(additionalNeeded),
 			Unit:             material.Unit.Name,
 			ProductID:        productID,
 			IsCalculable:     true,
+			CategoryID:       material.Category.ID,
+			Net:              netRequired,
+			Total:            totalRequired,
+			Stock:            remaining,
+			Shortage:         additionalNeeded,
 		}
 	}
 
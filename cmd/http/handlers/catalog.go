@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// maxCatalogSize limits size of uploaded catalog document.
+const maxCatalogSize = 64 * 1024 * 1024
+
+func (h *Handler) CatalogExportHandler(w http.ResponseWriter, r *http.Request) {
+	catalog, err := h.db.ExportCatalog(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка выгрузки данных", "error exporting catalog", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=catalog_%s.json",
+		time.Now().Format("2006-01-02_15-04-05")))
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(catalog); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка выгрузки данных", "error encoding catalog", "error", err)
+	}
+}
+
+func (h *Handler) CatalogImportHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxCatalogSize); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing catalog import form", "error", err)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "файл выгрузки не выбран", "error getting catalog file from form", "error", err)
+		return
+	}
+	defer file.Close()
+
+	var catalog models.Catalog
+	if err := json.NewDecoder(file).Decode(&catalog); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "файл не является выгрузкой каталога", "error decoding catalog", "error", err)
+		return
+	}
+
+	err = h.db.ImportCatalog(r.Context(), catalog, r.FormValue("replace") == "1")
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка загрузки данных: "+err.Error(), "error importing catalog", "error", err)
+		return
+	}
+
+	helpers.SetAndLogSuccess(w, fmt.Sprintf("загружено материалов: %d, изделий: %d, файлов: %d",
+		len(catalog.Materials), len(catalog.Products), len(catalog.Files)), "catalog imported from web UI")
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+const maxCategoryNameLength = 100
+
+// CategoriesPageHandler renders page for managing category tree.
+func (h *Handler) CategoriesPageHandler(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.db.GetAllCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка категорий: "+err.Error(), "error getting categories", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.CategoriesPage(categories)); err != nil {
+		slog.Error("cannot render categories page", "error", err, "where", "CategoriesPageHandler")
+	}
+}
+
+// CategoryNewHandler creates category and returns updated page.
+func (h *Handler) CategoryNewHandler(w http.ResponseWriter, r *http.Request) {
+	category, err := categoryFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid category", "error", err)
+		return
+	}
+	category, err = h.db.InsertCategory(r.Context(), category.ParentID, category.Name)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка создания категории: "+err.Error(), "error creating category", "error", err)
+		return
+	}
+	h.renderCategories(w, r, "категория «"+category.Name+"» создана")
+}
+
+// CategoryUpdateHandler renames category or moves it to another parent.
+func (h *Handler) CategoryUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор категории", "invalid category ID", "error", err)
+		return
+	}
+	category, err := categoryFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid category", "error", err)
+		return
+	}
+	category.ID = id
+	err = h.db.UpdateCategory(r.Context(), category)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrCategoryCycle) {
+			status = http.StatusBadRequest
+		}
+		helpers.SetAndLogError(w, status, "ошибка изменения категории: "+err.Error(), "error updating category", "error", err)
+		return
+	}
+	h.renderCategories(w, r, "категория «"+category.Name+"» сохранена")
+}
+
+// CategoryDeleteHandler deletes category without subcategories.
+func (h *Handler) CategoryDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор категории", "invalid category ID", "error", err)
+		return
+	}
+	err = h.db.DeleteCategory(r.Context(), id)
+	if errors.Is(err, db.ErrInUse) {
+		helpers.SetAndLogError(w, http.StatusConflict, "сначала удалите или перенесите подкатегории", "category has subcategories", "category", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления категории: "+err.Error(), "error deleting category", "error", err)
+		return
+	}
+	h.renderCategories(w, r, "категория удалена")
+}
+
+// CategoryAssignHandler puts materials selected in search results into category,
+// empty category makes them uncategorized.
+func (h *Handler) CategoryAssignHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing form", "error", err)
+		return
+	}
+	materialIDs, err := helpers.StringToInt64Slice(r.PostForm["material_ids"])
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор материала", "invalid material ID", "error", err)
+		return
+	}
+	if len(materialIDs) == 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "не выбрано ни одного материала", "no materials selected", "error", errors.New("empty material_ids"))
+		return
+	}
+	var categoryID int64
+	if value := r.PostFormValue("category_id"); value != "" {
+		categoryID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор категории", "invalid category ID", "error", err)
+			return
+		}
+	}
+
+	err = h.db.AssignCategory(r.Context(), categoryID, materialIDs)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка назначения категории: "+err.Error(), "error assigning category", "error", err)
+		return
+	}
+	msg := fmt.Sprintf("категория назначена материалам: %d", len(materialIDs))
+	if categoryID == 0 {
+		msg = fmt.Sprintf("категория снята с материалов: %d", len(materialIDs))
+	}
+	helpers.SetAndLogSuccess(w, msg, "category assigned", "category", categoryID, "materials", materialIDs)
+}
+
+func categoryFromRequest(r *http.Request) (models.Category, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.Category{}, errors.New("ошибка обработки формы")
+	}
+	category := models.Category{Name: strings.TrimSpace(r.PostFormValue("name"))}
+	if category.Name == "" || utf8.RuneCountInString(category.Name) > maxCategoryNameLength {
+		return models.Category{}, errors.New("название должно быть от 1 до 100 символов")
+	}
+	if value := r.PostFormValue("parent_id"); value != "" {
+		parentID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return models.Category{}, errors.New("неверная родительская категория")
+		}
+		category.ParentID = parentID
+	}
+	return category, nil
+}
+
+func (h *Handler) renderCategories(w http.ResponseWriter, r *http.Request, msg string) {
+	categories, err := h.db.GetAllCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка категорий: "+err.Error(), "error getting categories", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "categories changed")
+	if err := templates.CategoriesPage(categories).Render(r.Context(), w); err != nil {
+		slog.Error("can't render categories", "error", err)
+	}
+}
@@ -4,18 +4,22 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/s-588/BOMViewer/cmd/config"
 	"github.com/s-588/BOMViewer/internal/helpers"
 	"github.com/s-588/BOMViewer/web/templates"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func (h *Handler) ConfigPageHandler(w http.ResponseWriter, r *http.Request) {
-	templates.SettingsForm(h.cfg).Render(r.Context(), w)
+	cfg := h.cfg.Snapshot()
+	templates.SettingsForm(&cfg).Render(r.Context(), w)
+	templates.CatalogTransfer().Render(r.Context(), w)
 }
 
 func (h *Handler) UpdateConfigHandler(w http.ResponseWriter, r *http.Request) {
@@ -33,32 +37,40 @@ func (h *Handler) UpdateConfigHandler(w http.ResponseWriter, r *http.Request) {
 		helpers.SetAndLogError(w, http.StatusBadRequest, "Недопустимый уровень логирования", "invalid log level in update config handler", errors.New("Недопустимый уровень логирования"))
 		return
 	}
+	digest, err := digestConfig(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid digest config in update config handler", "error", err)
+		return
+	}
 	cfg := config.Config{
 		BaseDirectory: filepath.Clean(r.FormValue("base_directory")),
 		ServerCfg: config.ServerConfig{
 			ServerPort: int(port),
 			UploadsDir: filepath.Clean(r.FormValue("server.uploads_directory")),
+			Headless:   r.FormValue("server.headless") == "1",
 		},
 		DBCfg: config.DBConfig{
-			DBName: filepath.Clean(r.FormValue("database.database_name")),
+			DBName:      filepath.Clean(r.FormValue("database.database_name")),
+			AppIndexing: r.FormValue("database.app_search_indexing") == "1",
 		},
 		LogCfg: config.LogConfig{
 			LogLevel: r.FormValue("log.log_level"),
 		},
+		DigestCfg: digest,
 	}
 	if r.FormValue("web_ui_password") != "" {
 		if r.FormValue("web_ui_password") != r.FormValue("web_ui_password_confirm") {
 			helpers.SetAndLogError(w, http.StatusBadRequest, "Пароли не совпадают", "passwords do not match in update config handler", errors.New("Пароли не совпадают"))
 			return
 		}
-		passHash, err := bcrypt.GenerateFromPassword([]byte(r.FormValue("web_ui_password")), bcrypt.DefaultCost)
+		passHash, err := config.HashPassword(r.FormValue("web_ui_password"))
 		if err != nil {
 			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка хеширования пароля", "error hashing password in update config handler", err)
 			return
 		}
-		cfg.WebUIPassword = string(passHash)
+		cfg.WebUIPassword = passHash
 	}
-	slog.Debug("config before updating", "cfg", h.cfg)
+	slog.Debug("config before updating", "cfg", h.cfg.Snapshot())
 	err = h.cfg.UpdateConfig(cfg)
 	if err != nil {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка обновления конфигурации", "error updating config in update config handler", err)
@@ -76,7 +88,8 @@ func (h *Handler) ResetConfigHandler(w http.ResponseWriter, r *http.Request) {
 			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сброса поля конфигурации", "error resetting config field in reset config handler", err)
 			return
 		}
-		templates.SettingsField(field, h.cfg).Render(r.Context(), w)
+		cfg := h.cfg.Snapshot()
+		templates.SettingsField(field, &cfg).Render(r.Context(), w)
 		return
 	}
 	err := h.cfg.ResetConfig()
@@ -85,5 +98,31 @@ func (h *Handler) ResetConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	slog.Info("configuration reseted")
-	templates.SettingsForm(h.cfg).Render(r.Context(),w)
+	cfg := h.cfg.Snapshot()
+	templates.SettingsForm(&cfg).Render(r.Context(),w)
+}
+
+// digestConfig reads settings of reorder digest. Webhook must be http or https URL, time is HH:MM.
+func digestConfig(r *http.Request) (config.DigestConfig, error) {
+	digest := config.DigestConfig{
+		File:    strings.TrimSpace(r.FormValue("digest.file")),
+		Webhook: strings.TrimSpace(r.FormValue("digest.webhook")),
+		Time:    strings.TrimSpace(r.FormValue("digest.time")),
+	}
+	if digest.File != "" {
+		digest.File = filepath.Clean(digest.File)
+	}
+	if digest.Webhook != "" {
+		u, err := url.Parse(digest.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return config.DigestConfig{}, errors.New("адрес вебхука должен начинаться с http:// или https://")
+		}
+	}
+	if digest.Time == "" {
+		digest.Time = config.DefaultConfig.DigestCfg.Time
+	}
+	if _, err := time.Parse("15:04", digest.Time); err != nil {
+		return config.DigestConfig{}, errors.New("время сводки должно быть в формате ЧЧ:ММ")
+	}
+	return digest, nil
 }
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// defaultKerf is width of saw cut in mm.
+const defaultKerf = 3
+
+// CuttingPageHandler renders cutting optimizer, lines of selected product that are cut from bars
+// can be given cut lists and stock lengths, sheet lines can be given blanks and size of sheet.
+func (h *Handler) CuttingPageHandler(w http.ResponseWriter, r *http.Request) {
+	products, err := h.db.GetAllProducts(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка изделий", "can't get products for cutting page", "error", err)
+		return
+	}
+	var product models.Product
+	var linear, sheets []models.Material
+	if id := r.URL.Query().Get("product_id"); id != "" {
+		productID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор изделия", "invalid product ID in cutting page", "error", err)
+			return
+		}
+		product, err = h.db.GetProductByID(r.Context(), productID)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product for cutting page", "error", err)
+			return
+		}
+		linear, sheets = linearMaterials(product.Materials), sheetMaterials(product.Materials)
+	}
+	if err := renderPage(w, r, templates.CuttingPage(products, product, linear, sheets)); err != nil {
+		slog.Error("cannot render cutting page", "error", err, "where", "CuttingPageHandler")
+	}
+}
+
+// CuttingPlanHandler saves cut lists and blanks of product and renders cutting plans for batch.
+func (h *Handler) CuttingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор изделия", "invalid product ID in cutting plan", "error", err)
+		return
+	}
+	product, err := h.db.GetProductByID(r.Context(), productID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product for cutting plan", "error", err)
+		return
+	}
+	// Whole form is validated before cut lists are saved
+	batch, _, err := cuttingParams(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid cutting parameters", "error", err)
+		return
+	}
+	materials := linearMaterials(product.Materials)
+	cuts := make([][]models.Cut, len(materials))
+	for i, material := range materials {
+		cuts[i], err = helpers.ParseCuts(r.FormValue(fmt.Sprintf("cuts_%d", material.ID)))
+		if err == nil {
+			_, err = stockLengths(r, material)
+		}
+		if err == nil {
+			_, err = helpers.CutPieces(cuts[i], batch)
+		}
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": "+err.Error(), "invalid cut list", "error", err)
+			return
+		}
+	}
+	sheets := sheetMaterials(product.Materials)
+	blanks := make([][]models.Blank, len(sheets))
+	for i, material := range sheets {
+		blanks[i], err = helpers.ParseBlanks(r.FormValue(fmt.Sprintf("blanks_%d", material.ID)))
+		if err == nil {
+			_, _, err = sheetSize(r, material)
+		}
+		if err == nil {
+			_, err = helpers.BlankPieces(blanks[i], batch)
+		}
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": "+err.Error(), "invalid blanks", "error", err)
+			return
+		}
+	}
+	for i := range materials {
+		if helpers.FormatCuts(cuts[i]) == helpers.FormatCuts(materials[i].Cuts) {
+			continue
+		}
+		if err := h.db.SetProductCuts(r.Context(), productID, materials[i].ID, cuts[i]); err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения раскроя: "+err.Error(), "can't save cut list", "error", err)
+			return
+		}
+		materials[i].Cuts = cuts[i]
+	}
+	for i := range sheets {
+		if helpers.FormatBlanks(blanks[i]) == helpers.FormatBlanks(sheets[i].Blanks) {
+			continue
+		}
+		if err := h.db.SetProductBlanks(r.Context(), productID, sheets[i].ID, blanks[i]); err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения заготовок: "+err.Error(), "can't save blanks", "error", err)
+			return
+		}
+		sheets[i].Blanks = blanks[i]
+	}
+	h.renderCuttingPlans(w, r, product, materials, sheets, false)
+}
+
+// CuttingPrintHandler renders printable cutting chart of saved cut lists.
+func (h *Handler) CuttingPrintHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор изделия", "invalid product ID in cutting chart", "error", err)
+		return
+	}
+	product, err := h.db.GetProductByID(r.Context(), productID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product for cutting chart", "error", err)
+		return
+	}
+	h.renderCuttingPlans(w, r, product, linearMaterials(product.Materials), sheetMaterials(product.Materials), true)
+}
+
+// renderCuttingPlans plans cutting of lines with cut lists and nesting of lines with blanks,
+// batch, kerf, stock lengths and sizes of sheets are read from request.
+func (h *Handler) renderCuttingPlans(w http.ResponseWriter, r *http.Request, product models.Product, materials, sheets []models.Material, print bool) {
+	batch, kerf, err := cuttingParams(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid cutting parameters", "error", err)
+		return
+	}
+	query := url.Values{}
+	query.Set("batch", strconv.FormatInt(batch, 10))
+	query.Set("kerf", r.FormValue("kerf"))
+
+	var plans []helpers.MaterialCutting
+	for _, material := range materials {
+		if len(material.Cuts) == 0 {
+			continue
+		}
+		lengths, err := stockLengths(r, material)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": "+err.Error(), "invalid stock lengths", "error", err)
+			return
+		}
+		pieces, err := helpers.CutPieces(material.Cuts, batch)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": "+err.Error(), "too many pieces to cut", "error", err)
+			return
+		}
+		query.Set(fmt.Sprintf("stock_%d", material.ID), r.FormValue(fmt.Sprintf("stock_%d", material.ID)))
+		plans = append(plans, helpers.MaterialCutting{
+			Material: material,
+			Plan:     helpers.PlanCutting(pieces, lengths, kerf),
+		})
+	}
+	var nestings []helpers.MaterialNesting
+	for _, material := range sheets {
+		if len(material.Blanks) == 0 {
+			continue
+		}
+		width, length, err := sheetSize(r, material)
+		if err == nil {
+			var plan helpers.NestingPlan
+			plan, err = helpers.NestBlanks(material.Blanks, batch, width, length, kerf)
+			nestings = append(nestings, helpers.MaterialNesting{Material: material, Plan: plan})
+		}
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": "+err.Error(), "can't nest blanks", "error", err)
+			return
+		}
+		query.Set(fmt.Sprintf("sheet_%d", material.ID), r.FormValue(fmt.Sprintf("sheet_%d", material.ID)))
+	}
+	masses := h.materialMasses(r.Context(), slices.Concat(materials, sheets))
+
+	if print {
+		err = templates.CuttingChart(product, plans, nestings, masses, batch, kerf).Render(r.Context(), w)
+	} else {
+		printURL := fmt.Sprintf("/calculator/cutting/%d/print?%s", product.ID, query.Encode())
+		if len(plans) > 0 || len(nestings) > 0 {
+			helpers.SetAndLogSuccess(w, "раскрой рассчитан", "cutting planned", "product", product.ID, "materials", len(plans), "sheets", len(nestings))
+		}
+		err = templates.CuttingResults(plans, nestings, masses, batch, printURL).Render(r.Context(), w)
+	}
+	if err != nil {
+		slog.Error("can't render cutting plans", "error", err)
+	}
+}
+
+// cuttingParams reads batch size and kerf, missing kerf is defaultKerf.
+// Batch can't be larger than number of pieces of one plan.
+func cuttingParams(r *http.Request) (int64, float64, error) {
+	batch, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("batch")), 10, 64)
+	if err != nil || batch <= 0 || batch > helpers.MaxCuttingPieces {
+		return 0, 0, fmt.Errorf("размер партии должен быть целым числом от 1 до %d", helpers.MaxCuttingPieces)
+	}
+	kerf := float64(defaultKerf)
+	if value := strings.TrimSpace(r.FormValue("kerf")); value != "" {
+		kerf, err = strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil || kerf < 0 {
+			return 0, 0, errors.New("ширина реза должна быть неотрицательным числом")
+		}
+	}
+	return batch, kerf, nil
+}
+
+// stockLengths reads stock lengths of material, missing lengths are helpers.DefaultStockLength.
+func stockLengths(r *http.Request, material models.Material) ([]float64, error) {
+	stock := r.FormValue(fmt.Sprintf("stock_%d", material.ID))
+	if strings.TrimSpace(stock) == "" {
+		stock = strconv.Itoa(helpers.DefaultStockLength)
+	}
+	return helpers.ParseStockLengths(stock)
+}
+
+// sheetSize reads size of sheet of material, missing size is size of the material.
+func sheetSize(r *http.Request, material models.Material) (float64, float64, error) {
+	size := r.FormValue(fmt.Sprintf("sheet_%d", material.ID))
+	if strings.TrimSpace(size) == "" {
+		width, length, _ := helpers.SheetSize(material)
+		return width, length, nil
+	}
+	return helpers.ParseSheetSize(size)
+}
+
+// linearMaterials returns BOM lines that are cut from bars.
+func linearMaterials(materials []models.Material) []models.Material {
+	var linear []models.Material
+	for _, material := range materials {
+		if helpers.IsLinearMaterial(material) {
+			linear = append(linear, material)
+		}
+	}
+	return linear
+}
+
+// sheetMaterials returns BOM lines that are cut from sheets.
+func sheetMaterials(materials []models.Material) []models.Material {
+	var sheets []models.Material
+	for _, material := range materials {
+		if helpers.IsSheetMaterial(material) {
+			sheets = append(sheets, material)
+		}
+	}
+	return sheets
+}
+
+// maxNestingSteps bounds search of number of products that can be made of sheets in stock.
+const maxNestingSteps = 20
+
+// applyNesting replaces norms of sheet lines that have blanks by sheets counted by nesting of blanks.
+// Lines whose unit can't be counted from sheets keep their norm.
+func (h *Handler) applyNesting(results []models.CalculationResult, materials []models.Material, desiredQuantity int64,
+	remainingQuantities map[int64]float64, masses map[int64]helpers.Mass,
+) {
+	byID := make(map[int64]models.Material, len(materials))
+	for _, material := range materials {
+		byID[material.ID] = material
+	}
+	for i := range results {
+		result := &results[i]
+		material := byID[result.MaterialID]
+		if !result.IsCalculable || len(material.Blanks) == 0 {
+			continue
+		}
+		width, length, _ := helpers.SheetSize(material)
+		perSheet, ok := helpers.SheetAmount(1, width, length, masses[material.ID], result.Unit)
+		if !ok {
+			continue
+		}
+		plan, err := helpers.NestBlanks(material.Blanks, desiredQuantity, width, length, defaultKerf)
+		if err != nil || len(plan.Sheets) == 0 {
+			slog.Warn("can't nest blanks for calculator", "material", material.ID, "error", err)
+			continue
+		}
+		remaining := remainingQuantities[material.ID]
+		result.Total = float64(len(plan.Sheets)) * perSheet
+		result.Shortage = max(result.Total-remaining, 0)
+		result.RequiredTotal = h.formatQuantity(result.Total)
+		result.AdditionalNeeded = h.formatQuantity(result.Shortage)
+		result.CanProduce = producibleBySheets(material, math.Floor(remaining/perSheet+1e-9), desiredQuantity, len(plan.Sheets), width, length)
+		result.Sheets = len(plan.Sheets)
+		result.SheetSize = helpers.FormatLength(width) + "×" + helpers.FormatLength(length)
+		result.Utilization = plan.Utilization()
+	}
+}
+
+// producibleBySheets returns number of products whose blanks are nested into sheets in stock.
+// Search starts at estimate from sheets needed for desired quantity. Products whose blanks are more
+// than helpers.MaxCuttingPieces can't be nested, their number is the estimate.
+func producibleBySheets(material models.Material, sheets float64, desiredQuantity int64, desiredSheets int, width, length float64) int {
+	perProduct, err := helpers.BlankPieces(material.Blanks, 1)
+	if err != nil || perProduct == 0 || desiredSheets == 0 {
+		return 0
+	}
+	// Estimate is counted in float64, sheets in stock multiplied by desired quantity may overflow int64.
+	estimate := math.Floor(max(sheets, 0) * float64(desiredQuantity) / float64(desiredSheets))
+	if limit := helpers.MaxCuttingPieces / perProduct; estimate > float64(limit) {
+		return int(min(estimate, math.MaxInt32))
+	}
+	fits := func(n int64) bool {
+		plan, err := helpers.NestBlanks(material.Blanks, n, width, length, defaultKerf)
+		return err == nil && float64(len(plan.Sheets)) <= sheets
+	}
+	n := int64(estimate)
+	for range maxNestingSteps {
+		if n > 0 && !fits(n) {
+			n--
+		} else if fits(n + 1) {
+			n++
+		} else {
+			break
+		}
+	}
+	return int(n)
+}
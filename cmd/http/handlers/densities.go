@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// DensitiesPageHandler renders page for managing densities of steel grades and materials.
+func (h *Handler) DensitiesPageHandler(w http.ResponseWriter, r *http.Request) {
+	densities, err := h.db.GetAllDensities(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка плотностей: "+err.Error(), "error getting densities", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.DensitiesPage(densities)); err != nil {
+		slog.Error("cannot render densities page", "error", err, "where", "DensitiesPageHandler")
+	}
+}
+
+// DensityNewHandler creates density and returns updated page.
+func (h *Handler) DensityNewHandler(w http.ResponseWriter, r *http.Request) {
+	density, err := densityFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid density", "error", err)
+		return
+	}
+	density, err = h.db.InsertDensity(r.Context(), density)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка создания плотности: "+err.Error(), "error creating density", "error", err)
+		return
+	}
+	h.renderDensities(w, r, "плотность «"+density.Name+"» добавлена")
+}
+
+// DensityUpdateHandler changes name and value of density.
+func (h *Handler) DensityUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор плотности", "invalid density ID", "error", err)
+		return
+	}
+	density, err := densityFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid density", "error", err)
+		return
+	}
+	density.ID = id
+	if err := h.db.UpdateDensity(r.Context(), density); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка изменения плотности: "+err.Error(), "error updating density", "error", err)
+		return
+	}
+	h.renderDensities(w, r, "плотность «"+density.Name+"» сохранена")
+}
+
+// DensityDeleteHandler deletes density.
+func (h *Handler) DensityDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор плотности", "invalid density ID", "error", err)
+		return
+	}
+	if err := h.db.DeleteDensity(r.Context(), id); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления плотности: "+err.Error(), "error deleting density", "error", err)
+		return
+	}
+	h.renderDensities(w, r, "плотность удалена")
+}
+
+// materialMasses computes theoretical masses of materials, masses are omitted when densities can't be loaded.
+func (h *Handler) materialMasses(ctx context.Context, materials []models.Material) map[int64]helpers.Mass {
+	densities, err := h.db.GetAllDensities(ctx)
+	if err != nil {
+		slog.Error("can't get densities", "error", err)
+		return nil
+	}
+	return helpers.MaterialMasses(materials, densities)
+}
+
+func densityFromRequest(r *http.Request) (models.Density, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.Density{}, errors.New("ошибка обработки формы")
+	}
+	density := models.Density{Name: strings.TrimSpace(r.PostFormValue("name"))}
+	if density.Name == "" {
+		return models.Density{}, errors.New("название марки или материала не заполнено")
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(r.PostFormValue("density")), ",", "."), 64)
+	if err != nil || value <= 0 {
+		return models.Density{}, errors.New("плотность должна быть положительным числом")
+	}
+	density.Density = value
+	return density, nil
+}
+
+func (h *Handler) renderDensities(w http.ResponseWriter, r *http.Request, msg string) {
+	densities, err := h.db.GetAllDensities(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка плотностей: "+err.Error(), "error getting densities", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "densities changed")
+	if err := templates.DensitiesPage(densities).Render(r.Context(), w); err != nil {
+		slog.Error("can't render densities", "error", err)
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/s-588/BOMViewer/internal/extract"
 	"github.com/s-588/BOMViewer/internal/helpers"
 	"github.com/s-588/BOMViewer/web/templates"
 )
@@ -77,6 +80,9 @@ func (h *Handler) ProductFileUploadHandler(w http.ResponseWriter, r *http.Reques
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка привязки файла к изделию", "error linking file to product", "error", err)
 		return
 	}
+	if uploadedFile.FileType == "document" {
+		h.Go(func() { h.indexFileText(context.Background(), fileID, uploadedFile.Path) })
+	}
 
 	// Return updated product view
 	h.ProductViewHandler(w, r)
@@ -175,3 +181,40 @@ func (h *Handler) FileDownload(w http.ResponseWriter, r *http.Request) {
 
 	http.ServeFile(w, r, file.Path)
 }
+
+// ExtractFileTexts indexes documents which text is not extracted yet, like files uploaded
+// before search in attachments was added. It runs in background and stops when ctx is done.
+func (h *Handler) ExtractFileTexts(ctx context.Context) {
+	h.Go(func() {
+		files, err := h.db.GetFilesWithoutText(ctx)
+		if err != nil {
+			slog.Error("can't get files without extracted text", "error", err)
+			return
+		}
+		if len(files) > 0 {
+			slog.Info("extracting text of attached documents", "count", len(files))
+		}
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			h.indexFileText(ctx, file.ID, file.Path)
+		}
+	})
+}
+
+// indexFileText extracts text of document and saves it to search index.
+// Empty text is saved for documents without extractable text, so they are not read again on every start.
+// Document that failed for other reason, e.g. locked or damaged file, is left unindexed and retried on next start.
+func (h *Handler) indexFileText(ctx context.Context, fileID int64, path string) {
+	text, err := extract.Text(path)
+	if errors.Is(err, extract.ErrUnsupported) {
+		slog.Debug("document has no extractable text", "file", fileID, "path", path)
+	} else if err != nil {
+		slog.Error("can't extract text of document", "file", fileID, "path", path, "error", err)
+		return
+	}
+	if err := h.db.SaveFileText(ctx, fileID, text); err != nil {
+		slog.Error("can't save text of document", "file", fileID, "error", err)
+	}
+}
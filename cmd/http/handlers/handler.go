@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 
+	"github.com/a-h/templ"
 	"github.com/s-588/BOMViewer/cmd/config"
 	"github.com/s-588/BOMViewer/internal/db"
 	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
 	"github.com/s-588/BOMViewer/web/templates"
 )
 
@@ -14,37 +19,20 @@ type Handler struct {
 	db         *db.Repository
 	fileUpload *helpers.FileUploadConfig
 	cfg        *config.Config
+	// workers tracks goroutines that outlive request, see Go.
+	workers sync.WaitGroup
 }
 
 func (h *Handler) RootPage(w http.ResponseWriter, r *http.Request) {
-	// Get materials for the main page (you might want to limit this or show featured items)
-	materials, err := h.db.GetAllMaterials(r.Context())
+	// Main page shows materials table in its default state.
+	materials, tableArgs, err := h.materialTable(r.Context(), helpers.TableView{Sort: "name", Page: 1})
 	if err != nil {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов", "error getting materials", "error", err)
 		return
 	}
-	sortCfg := helpers.ParseSortString("name")
-	helpers.SortMaterials(materials, sortCfg)
-
-	products, err := h.db.GetAllProducts(r.Context())
-	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products", "error", err)
-		return
-	}
-
-	// Get units and products for the table controls
-	units, err := h.db.GetAllUnits(r.Context())
+	tableArgs.SavedViews, err = h.db.GetSavedViews(r.Context(), models.ViewMaterials)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения единиц измерения", "error getting units", "error", err)
-		return
-	}
-
-	// Create proper TableControlsArgs
-	tableArgs := templates.MaterialTableArgs{
-		Action:      "/materials/table",
-		Sort:        "name",
-		AllUnits:    units,
-		AllProducts: products,
+		slog.Error("can't get saved views", "error", err, "where", "RootPage")
 	}
 
 	err = templates.Index(r.Context(), materials, tableArgs).Render(r.Context(), w)
@@ -53,6 +41,15 @@ func (h *Handler) RootPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// renderPage renders component inside of layout when page is opened by link, reloaded
+// or restored from history by htmx, other htmx requests get only the component.
+func renderPage(w http.ResponseWriter, r *http.Request, component templ.Component) error {
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-History-Restore-Request") != "true" {
+		return component.Render(r.Context(), w)
+	}
+	return templates.Layout().Render(templ.WithChildren(r.Context(), component), w)
+}
+
 func NewHandler(db *db.Repository, cfg *config.Config) *Handler {
 	return &Handler{
 		db:         db,
@@ -60,3 +57,28 @@ func NewHandler(db *db.Repository, cfg *config.Config) *Handler {
 		cfg:        cfg,
 	}
 }
+
+// Go runs f in background. Use it instead of bare go statement for work
+// that must be finished before database is closed.
+func (h *Handler) Go(f func()) {
+	h.workers.Add(1)
+	go func() {
+		defer h.workers.Done()
+		f()
+	}()
+}
+
+// Wait blocks until all background workers are finished or ctx is done.
+func (h *Handler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers are not finished: %w", ctx.Err())
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -14,118 +15,103 @@ import (
 	"github.com/s-588/BOMViewer/web/templates"
 )
 
+// MaterialPageHandler renders materials page, state of table is restored from query parameters,
+// so the page can be opened by link or reloaded.
 func (h *Handler) MaterialPageHandler(w http.ResponseWriter, r *http.Request) {
-	units, err := h.db.GetAllUnits(r.Context())
+	materials, args, err := h.materialTable(r.Context(), helpers.ParseTableView(r.URL.Query()))
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка единиц измерения для фильтров", "error getting units", "error", err)
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов: "+err.Error(), "error getting materials table", "error", err)
 		return
 	}
-
-	products, err := h.db.GetAllProducts(r.Context())
+	args.SavedViews, err = h.db.GetSavedViews(r.Context(), models.ViewMaterials)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов для фильтров", "error getting products", "error", err)
-		return
+		slog.Error("can't get saved views", "error", err, "where", "MaterialPageHandler")
 	}
 
-	// Get initial materials (unfiltered)
-	materials, err := h.db.GetAllMaterials(r.Context())
+	err = renderPage(w, r, templates.MainMaterialPage(materials, args))
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов", "error getting materials", "error", err)
-		return
+		slog.Error("cannot render materials page", "error", err, "where", "MaterialPageHandler")
 	}
-
-	filteredMaterials := helpers.FilterMaterials(materials, helpers.MaterialFilterArgs{})
-	sortConfig := helpers.ParseSortString("name")
-	helpers.SortMaterials(filteredMaterials, sortConfig)
-
-	templates.MainMaterialPage(filteredMaterials, templates.MaterialTableArgs{
-		Action:      "/materials/table",
-		Sort:        "name",
-		AllUnits:    units,
-		AllProducts: products,
-		Selected:    make(map[int64]bool),
-		Quantities:  make(map[int64]string),
-	}).Render(r.Context(), w)
 }
 
+// MaterialTableHandler renders table with state from controls form. URL of materials page
+// with the same state is pushed to browser history, so it can be shared or reloaded.
 func (h *Handler) MaterialTableHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse form data (includes both URL query and form parameters)
-	if err := r.ParseForm(); err != nil {
-		// handle error
+	view := helpers.ParseTableView(r.URL.Query())
+	materials, args, err := h.materialTable(r.Context(), view)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов: "+err.Error(), "error getting materials table", "error", err)
+		return
 	}
+	slog.Debug("material table args", "view", args.View, "total", args.Total)
 
-	// Get all parameters from form data
-	sort := r.FormValue("sort")
-	primaryOnly := r.FormValue("primary_only") == "1"
-
-	var filtersUnits, filtersProducts []int64
-	if units := r.Form["units"]; len(units) > 0 {
-		filtersUnits, _ = helpers.StringToInt64Slice(units)
-	}
-	if products := r.Form["products"]; len(products) > 0 {
-		filtersProducts, _ = helpers.StringToInt64Slice(products)
+	w.Header().Set("HX-Push-Url", "/materials?"+args.View.Values().Encode())
+	err = templates.MainMaterialList(materials, args).Render(r.Context(), w)
+	if err != nil {
+		slog.Error("cannot render material list", "error", err, "where", "MaterialTableHandler")
 	}
+}
 
-	// Get all materials and apply filters
+// materialTable returns page of materials filtered and sorted according to view,
+// page of view is corrected when it's out of range.
+func (h *Handler) materialTable(ctx context.Context, view helpers.TableView) ([]models.Material, templates.MaterialTableArgs, error) {
 	var allMaterials []models.Material
 	var err error
-	if primaryOnly {
-		allMaterials, err = h.db.GetAllMaterialsWithPrimaryNames(r.Context())
-		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов", "error getting materials", "error",	 err)
-			return
-		}
+	if view.PrimaryOnly {
+		allMaterials, err = h.db.GetAllMaterialsWithPrimaryNames(ctx)
 	} else {
-		allMaterials, err = h.db.GetAllMaterials(r.Context())
-		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов", "error getting materials", "error", err)
-			return
-		}
+		allMaterials, err = h.db.GetAllMaterials(ctx)
 	}
-
-	// Apply filtering and sorting using your helper functions
-	filteredMaterials := helpers.FilterMaterials(allMaterials, helpers.MaterialFilterArgs{
-		ProductIDs: filtersProducts,
-		UnitIDs:    filtersUnits,
-	})
-
-	// Apply sorting
-	sortConfig := helpers.ParseSortString(sort)
-	helpers.SortMaterials(filteredMaterials, sortConfig)
-
-	allUnits, err := h.db.GetAllUnits(r.Context())
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка единиц измерения для фильтров", "error getting units", "error", err)
-		return
+		return nil, templates.MaterialTableArgs{}, err
 	}
 
-	allProducts, err := h.db.GetAllProducts(r.Context())
+	allCategories, err := h.db.GetAllCategories(ctx)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов для фильтров", "error getting products", "error", err)
-		return
+		return nil, templates.MaterialTableArgs{}, err
 	}
 
-	args := templates.MaterialTableArgs{
-		Action:          "/materials/table",
-		Sort:            sort,
-		FiltersUnits:    filtersUnits,
-		FiltersProducts: filtersProducts,
-		PrimaryOnly:     primaryOnly,
-		AllUnits:        allUnits,
-		AllProducts:     allProducts,
-		Quantities:      make(map[int64]string),
-		Selected:        make(map[int64]bool),
+	allAttributes, err := h.db.GetAllAttributes(ctx)
+	if err != nil {
+		return nil, templates.MaterialTableArgs{}, err
 	}
 
-	slog.Debug("materia table args", "args", args)
-
-	slog.Debug("materila table filtered materials", "materials", filteredMaterials)
+	filteredMaterials := helpers.FilterMaterials(allMaterials, helpers.MaterialFilterArgs{
+		ProductIDs:  view.ProductIDs,
+		UnitIDs:     view.UnitIDs,
+		CategoryIDs: helpers.CategorySubtree(allCategories, view.CategoryIDs),
+		Tags:        view.Tags,
+		Attributes:  view.Attributes,
+	})
+	helpers.SortMaterials(filteredMaterials, helpers.ParseSortString(view.Sort))
+	materials := helpers.Paginate(filteredMaterials, &view)
 
-	// Return only the table, not the full page
-	err = templates.MainMaterialList(filteredMaterials, args).Render(r.Context(), w)
+	allUnits, err := h.db.GetAllUnits(ctx)
 	if err != nil {
-		slog.Error("cannot render material list", "error", err, "where", "MaterialTableHandler")
+		return nil, templates.MaterialTableArgs{}, err
+	}
+	allProducts, err := h.db.GetAllProducts(ctx)
+	if err != nil {
+		return nil, templates.MaterialTableArgs{}, err
 	}
+	densities, err := h.db.GetAllDensities(ctx)
+	if err != nil {
+		return nil, templates.MaterialTableArgs{}, err
+	}
+
+	return materials, templates.MaterialTableArgs{
+		Action:        "/materials/table",
+		View:          view,
+		Total:         len(filteredMaterials),
+		AllUnits:      allUnits,
+		AllProducts:   allProducts,
+		AllCategories: allCategories,
+		AllAttributes: allAttributes,
+		AllTags:       helpers.AllTags(allMaterials),
+		Masses:        helpers.MaterialMasses(materials, densities),
+		Quantities:    make(map[int64]string),
+		Selected:      make(map[int64]bool),
+	}, nil
 }
 
 func (h *Handler) MaterialNewHandler(w http.ResponseWriter, r *http.Request) {
@@ -175,6 +161,19 @@ func (h *Handler) MaterialNewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	categoryID, _ := strconv.ParseInt(r.FormValue("category_id"), 10, 64)
+	tags, values, err := h.propertiesFromRequest(r.Context(), r, categoryID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка в атрибутах: "+err.Error(), "invalid material attributes", "error", err)
+		return
+	}
+
+	yield, err := parseYieldPercent(r.FormValue("yield_percent"))
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid material yield", "error", err)
+		return
+	}
+
 	material := models.Material{
 		Names:       names,
 		PrimaryName: primaryName,
@@ -190,6 +189,20 @@ func (h *Handler) MaterialNewHandler(w http.ResponseWriter, r *http.Request) {
 
 	slog.Info("new material successfully created", "material_id", material.ID, "names", material.Names)
 
+	if categoryID != 0 {
+		if err := h.db.SetMaterialCategory(r.Context(), material.ID, categoryID); err != nil {
+			slog.Error("set material category", "error", err, "where", "MaterialNewHandler")
+		}
+	}
+	if err := h.db.SetMaterialProperties(r.Context(), material.ID, tags, values); err != nil {
+		slog.Error("set material tags and attributes", "error", err, "where", "MaterialNewHandler")
+	}
+	if yield != 100 {
+		if err := h.db.SetMaterialYield(r.Context(), material.ID, yield); err != nil {
+			slog.Error("set material yield", "error", err, "where", "MaterialNewHandler")
+		}
+	}
+
 	// Handle product associations
 	productsIds := r.Form["product_ids"]
 	slog.Debug("product associations", "product_ids", productsIds)
@@ -208,7 +221,7 @@ func (h *Handler) MaterialNewHandler(w http.ResponseWriter, r *http.Request) {
 		if quantity != "" {
 			slog.Debug("adding product material", "product_id", productID, "quantity", quantity)
 
-			err = h.db.AddProductMaterial(r.Context(), productID, material.ID, quantity)
+			err = h.db.AddProductMaterial(r.Context(), productID, material.ID, quantity, nil)
 			if err != nil {
 				slog.Error("can't add product to material", "error", err, "product_id", productID, "where", "MaterialNewHandler")
 				continue
@@ -223,6 +236,19 @@ func (h *Handler) MaterialNewHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/materials", http.StatusSeeOther)
 }
 
+// parseYieldPercent parses default yield of material, empty value means no waste.
+func parseYieldPercent(value string) (float64, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+	if value == "" {
+		return 100, nil
+	}
+	yield, err := strconv.ParseFloat(value, 64)
+	if err != nil || yield <= 0 || yield > 100 {
+		return 0, fmt.Errorf("выход годного %q должен быть числом больше 0 и не больше 100", value)
+	}
+	return yield, nil
+}
+
 func parseUnit(unit string) (models.Unit, error) {
 	if unit == "" {
 		return models.Unit{}, errors.New("единица измерения обязательна")
@@ -293,6 +319,19 @@ func (h *Handler) MaterialUpdateHandler(w http.ResponseWriter, r *http.Request)
 	}
 	material.ID = id // Set the ID from URL
 
+	categoryID, _ := strconv.ParseInt(r.FormValue("category_id"), 10, 64)
+	tags, values, err := h.propertiesFromRequest(r.Context(), r, categoryID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка в атрибутах: "+err.Error(), "invalid material attributes", "error", err)
+		return
+	}
+
+	yield, err := parseYieldPercent(r.FormValue("yield_percent"))
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid material yield", "error", err)
+		return
+	}
+
 	slog.Debug("updating material", "id", material.ID, "primary_name", material.PrimaryName,
 		"names", material.Names, "description", material.Description)
 
@@ -309,6 +348,25 @@ func (h *Handler) MaterialUpdateHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Empty category_id makes material uncategorized.
+	if r.Form.Has("category_id") {
+		if err := h.db.SetMaterialCategory(r.Context(), material.ID, categoryID); err != nil {
+			slog.Error("update material category", "error", err, "where", "MaterialUpdateHandler")
+		}
+	}
+
+	if r.Form.Has("tags") {
+		if err := h.db.SetMaterialProperties(r.Context(), material.ID, tags, values); err != nil {
+			slog.Error("update material tags and attributes", "error", err, "where", "MaterialUpdateHandler")
+		}
+	}
+
+	if r.Form.Has("yield_percent") {
+		if err := h.db.SetMaterialYield(r.Context(), material.ID, yield); err != nil {
+			slog.Error("update material yield", "error", err, "where", "MaterialUpdateHandler")
+		}
+	}
+
 	if len(material.Names) != 0 {
 		if err := h.db.UpdateMaterialNames(r.Context(), material.ID, material.PrimaryName, material.Names); err != nil {
 			slog.Error("update material names", "error", err, "where", "MaterialUpdateHandler")
@@ -446,6 +504,9 @@ func (h *Handler) MaterialFileUploadHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	slog.Info("file linked to material", "materialID", materialID, "fileID", fileID)
+	if fileType == "document" {
+		h.Go(func() { h.indexFileText(context.Background(), fileID, uploadedFile.Path) })
+	}
 
 	// Get updated file list and return the files section
 	files, err := h.db.GetMaterialFiles(r.Context(), materialID)
@@ -511,7 +572,7 @@ func (h *Handler) MaterialDeleteHandler(w http.ResponseWriter, r *http.Request)
 	}
 	err = h.db.DeleteMaterial(r.Context(), id)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления материала", "error deleting material", "error", err)
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления материала: "+err.Error(), "error deleting material", "error", err)
 		return
 	}
 	http.Redirect(w, r, "/materials", http.StatusSeeOther)
@@ -541,7 +602,17 @@ func (h *Handler) MaterialEditHandler(w http.ResponseWriter, r *http.Request) {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products list", "error", err)
 		return
 	}
-	templates.MaterialForm(material, units, products, "edit").Render(r.Context(), w)
+	categories, err := h.db.GetAllCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка категорий", "error getting categories list", "error", err)
+		return
+	}
+	attributes, err := h.db.GetAllAttributes(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов", "error getting attributes list", "error", err)
+		return
+	}
+	templates.MaterialForm(material, units, products, categories, attributes, "edit").Render(r.Context(), w)
 }
 
 func (h *Handler) MaterialCreateHandler(w http.ResponseWriter, r *http.Request) {
@@ -555,22 +626,36 @@ func (h *Handler) MaterialCreateHandler(w http.ResponseWriter, r *http.Request)
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products list", "error", err)
 		return
 	}
-	templates.MaterialForm(models.Material{}, units, products, "new").Render(r.Context(), w)
+	categories, err := h.db.GetAllCategories(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка категорий", "error getting categories list", "error", err)
+		return
+	}
+	attributes, err := h.db.GetAllAttributes(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов", "error getting attributes list", "error", err)
+		return
+	}
+	templates.MaterialForm(models.Material{}, units, products, categories, attributes, "new").Render(r.Context(), w)
 }
 
 func (h *Handler) MaterialsPicker(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 
-	materials, _, err := h.db.SearchAll(r.Context(), q, 10)
+	page, err := h.db.Search(r.Context(), q, "material", models.SearchFilter{}, 10, 0)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	rows := make([]templates.ProductMaterialRow, 0, len(materials))
-	for _, material := range materials {
+	rows := make([]templates.ProductMaterialRow, 0, len(page.Results))
+	for _, result := range page.Results {
+		var name strings.Builder
+		for _, fragment := range result.Name {
+			name.WriteString(fragment.Text)
+		}
 		rows = append(rows, templates.ProductMaterialRow{
-			Material: material,
+			Material: models.Material{ID: result.ID, PrimaryName: name.String()},
 		})
 	}
 	templates.MaterialTableForProduct(rows, templates.MaterialTableArgs{}).Render(r.Context(), w)
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// OrdersPageHandler renders production orders and form of new order.
+func (h *Handler) OrdersPageHandler(w http.ResponseWriter, r *http.Request) {
+	h.renderOrders(w, r, "")
+}
+
+// OrderNewHandler creates order with norms of product BOM exploded for quantity of order and renders it.
+func (h *Handler) OrderNewHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing order form", "error", err)
+		return
+	}
+	productID, err := strconv.ParseInt(r.PostFormValue("product_id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "изделие не выбрано", "invalid product of order", "error", err)
+		return
+	}
+	quantity, err := strconv.ParseInt(r.PostFormValue("quantity"), 10, 64)
+	if err != nil || quantity <= 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "количество должно быть целым положительным числом", "invalid quantity of order", "quantity", r.PostFormValue("quantity"))
+		return
+	}
+	locationID, _ := strconv.ParseInt(r.PostFormValue("location"), 10, 64)
+	locations, err := h.db.GetLocations(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения мест хранения: "+err.Error(), "can't get locations", "error", err)
+		return
+	}
+	i := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == locationID })
+	if i < 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "место хранения не выбрано", "invalid location of order", "location", locationID)
+		return
+	}
+	date, comment, err := movementDateAndComment(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid order", "error", err)
+		return
+	}
+	product, err := h.db.GetProductByID(r.Context(), productID)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "изделие не найдено", "product of order not found", "product", productID)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product of order", "error", err)
+		return
+	}
+	if len(product.Materials) == 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "у изделия нет материалов", "product of order has no materials", "product", productID)
+		return
+	}
+	order := models.ProductionOrder{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Quantity:    quantity,
+		Location:    locations[i],
+		CreatedOn:   date,
+		Comment:     comment,
+		Lines:       helpers.OrderLines(product, quantity),
+	}
+	id, err := h.db.CreateProductionOrder(r.Context(), order)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка создания заказа: "+err.Error(), "can't create production order", "error", err)
+		return
+	}
+	w.Header().Set("HX-Push-Url", "/orders/"+strconv.FormatInt(id, 10))
+	h.renderOrder(w, r, id, "заказ №"+strconv.FormatInt(id, 10)+" создан")
+}
+
+// OrderPageHandler renders order: completion form of open order or norm vs actual report of completed one.
+func (h *Handler) OrderPageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор заказа", "invalid order ID", "error", err)
+		return
+	}
+	h.renderOrder(w, r, id, "")
+}
+
+// OrderCompleteHandler records actual consumption of order lines and issues it from location of order.
+// Empty actual consumption isn't issued.
+func (h *Handler) OrderCompleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор заказа", "invalid order ID", "error", err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing order completion form", "error", err)
+		return
+	}
+	date, _, err := movementDateAndComment(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid order completion", "error", err)
+		return
+	}
+	order, err := h.db.GetProductionOrder(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "заказ не найден", "production order not found", "order", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения заказа: "+err.Error(), "can't get production order", "error", err)
+		return
+	}
+	for i, line := range order.Lines {
+		value := strings.TrimSpace(r.PostFormValue("actual_" + strconv.FormatInt(line.ID, 10)))
+		if value == "" {
+			continue
+		}
+		actual, err := h.parseQuantity(value)
+		if err != nil || actual < 0 {
+			helpers.SetAndLogError(w, http.StatusBadRequest, line.MaterialName+": расход должен быть неотрицательным числом", "invalid actual consumption", "line", line.ID, "value", value)
+			return
+		}
+		order.Lines[i].Actual = &actual
+	}
+	err = h.db.CompleteProductionOrder(r.Context(), order, date)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "заказ не найден", "production order not found", "order", id)
+		return
+	}
+	if errors.Is(err, db.ErrInsufficientStock) || errors.Is(err, db.ErrOrderCompleted) {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка выполнения заказа: "+err.Error(), "can't complete production order", "error", err)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка выполнения заказа: "+err.Error(), "can't complete production order", "error", err)
+		return
+	}
+	h.renderOrder(w, r, id, "заказ №"+strconv.FormatInt(id, 10)+" выполнен, материалы списаны")
+}
+
+// OrderDeleteHandler deletes open order and returns list of orders.
+func (h *Handler) OrderDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор заказа", "invalid order ID", "error", err)
+		return
+	}
+	err = h.db.DeleteProductionOrder(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "заказ не найден", "production order not found", "order", id)
+		return
+	}
+	if errors.Is(err, db.ErrOrderCompleted) {
+		helpers.SetAndLogError(w, http.StatusConflict, "выполненный заказ нельзя удалить", "production order is completed", "order", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления заказа: "+err.Error(), "can't delete production order", "error", err)
+		return
+	}
+	w.Header().Set("HX-Push-Url", "/orders")
+	h.renderOrders(w, r, "заказ №"+strconv.FormatInt(id, 10)+" удалён")
+}
+
+// renderOrders renders list of orders, message is shown when it isn't empty.
+func (h *Handler) renderOrders(w http.ResponseWriter, r *http.Request, msg string) {
+	orders, err := h.db.GetProductionOrders(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения заказов: "+err.Error(), "can't get production orders", "error", err)
+		return
+	}
+	products, err := h.db.GetAllProducts(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделий: "+err.Error(), "can't get products", "error", err)
+		return
+	}
+	slices.SortFunc(products, func(a, b models.Product) int { return strings.Compare(a.Name, b.Name) })
+	locations, err := h.db.GetLocations(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения мест хранения: "+err.Error(), "can't get locations", "error", err)
+		return
+	}
+	if msg != "" {
+		helpers.SetAndLogSuccess(w, msg, "production orders changed")
+	}
+	args := templates.OrdersPageArgs{
+		Orders:    orders,
+		Products:  products,
+		Locations: locations,
+		Today:     time.Now().Format(time.DateOnly),
+	}
+	if err := renderPage(w, r, templates.OrdersPage(args)); err != nil {
+		slog.Error("cannot render orders page", "error", err, "where", "renderOrders")
+	}
+}
+
+// renderOrder renders order by ID, message is shown when it isn't empty.
+func (h *Handler) renderOrder(w http.ResponseWriter, r *http.Request, id int64, msg string) {
+	order, err := h.db.GetProductionOrder(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "заказ не найден", "production order not found", "order", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения заказа: "+err.Error(), "can't get production order", "error", err)
+		return
+	}
+	if msg != "" {
+		helpers.SetAndLogSuccess(w, msg, "production order changed", "order", id)
+	}
+	if err := renderPage(w, r, templates.OrderPage(order, time.Now().Format(time.DateOnly))); err != nil {
+		slog.Error("cannot render order page", "error", err, "where", "renderOrder")
+	}
+}
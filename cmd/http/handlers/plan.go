@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+const (
+	// planLines is number of empty product lines of new plan.
+	planLines = 3
+	// defaultNextSets is number of sets shortage is computed for after maximum is reached.
+	defaultNextSets = 1
+)
+
+// ProductionPlanPageHandler renders plan calculator for several products sharing one stock.
+// Saved scenario is opened with its plan and stock already calculated.
+func (h *Handler) ProductionPlanPageHandler(w http.ResponseWriter, r *http.Request) {
+	products, err := h.db.GetAllProducts(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка изделий", "can't get products for plan page", "error", err)
+		return
+	}
+	page := templates.PlanPageArgs{Lines: make([]helpers.PlanLine, planLines)}
+	if id := r.URL.Query().Get("scenario"); id != "" {
+		scenarioID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор сценария", "invalid scenario ID in plan page", "error", err)
+			return
+		}
+		page.Scenario, err = h.db.GetScenario(r.Context(), scenarioID)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценария: "+err.Error(), "can't get scenario for plan page", "error", err)
+			return
+		}
+		lines, stock, removed, err := h.rerunScenario(r.Context(), page.Scenario)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка расчёта сценария: "+err.Error(), "can't rerun scenario", "error", err)
+			return
+		}
+		plan := helpers.PlanProduction(lines, stock)
+		page.Lines, page.Plan = lines, &plan
+		page.Removed = helpers.RemovedProducts(page.Scenario.Result, removed)
+		if len(page.Lines) == 0 {
+			page.Lines = make([]helpers.PlanLine, planLines)
+		}
+	} else {
+		// Product can be preselected by link from calculator, unknown ID just selects nothing.
+		page.Lines[0].Product.ID, _ = strconv.ParseInt(r.URL.Query().Get("product_id"), 10, 64)
+	}
+	if err := renderPage(w, r, templates.ProductionPlanPage(products, page)); err != nil {
+		slog.Error("cannot render plan page", "error", err, "where", "ProductionPlanPageHandler")
+	}
+}
+
+// ProductionPlanLineHandler returns empty product line of plan.
+func (h *Handler) ProductionPlanLineHandler(w http.ResponseWriter, r *http.Request) {
+	products, err := h.db.GetAllProducts(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка изделий", "can't get products for plan line", "error", err)
+		return
+	}
+	templates.ProductionPlanLine(products, helpers.PlanLine{}).Render(r.Context(), w)
+}
+
+// ProductionPlanHandler sums requirements of planned products and compares them with stock.
+func (h *Handler) ProductionPlanHandler(w http.ResponseWriter, r *http.Request) {
+	lines, stock, ok := h.planFromRequest(w, r)
+	if !ok {
+		return
+	}
+	templates.ProductionPlanResults(helpers.PlanProduction(lines, stock)).Render(r.Context(), w)
+}
+
+// ProductionCapacityHandler computes maximum number of sets of plan stock is enough for,
+// quantities of plan are taken as one set. Bottleneck materials are shown with amounts
+// that would allow next_sets more sets.
+func (h *Handler) ProductionCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	lines, stock, ok := h.planFromRequest(w, r)
+	if !ok {
+		return
+	}
+	next := int64(defaultNextSets)
+	if value := r.FormValue("next_sets"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			helpers.SetAndLogError(w, http.StatusBadRequest, "число следующих комплектов должно быть целым больше 0", "invalid next sets", "error", err, "value", value)
+			return
+		}
+		next = n
+	}
+	templates.ProductionCapacityResults(lines, helpers.ProductionCapacity(lines, stock, next)).Render(r.Context(), w)
+}
+
+// planFromRequest loads products of plan and stock of their materials, error is written when plan is invalid.
+func (h *Handler) planFromRequest(w http.ResponseWriter, r *http.Request) ([]helpers.PlanLine, map[int64]float64, bool) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing form of plan", "error", err)
+		return nil, nil, false
+	}
+	quantities, err := parsePlan(r.Form)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid production plan", "error", err)
+		return nil, nil, false
+	}
+	lines, stock, missing, err := h.loadPlan(r.Context(), quantities, r.Form)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product for plan", "error", err)
+		return nil, nil, false
+	}
+	if len(missing) > 0 {
+		helpers.SetAndLogError(w, http.StatusNotFound, "изделие не найдено", "product of plan not found", "id", missing[0])
+		return nil, nil, false
+	}
+	return lines, stock, true
+}
+
+// loadPlan loads products of plan and reads stock of their materials from form values.
+// Products that don't exist, e.g. deleted after scenario was saved, are left out and their IDs are returned.
+func (h *Handler) loadPlan(ctx context.Context, quantities []planQuantity, values url.Values) ([]helpers.PlanLine, map[int64]float64, []int64, error) {
+	lines := make([]helpers.PlanLine, 0, len(quantities))
+	stock := make(map[int64]float64)
+	var missing []int64
+	for _, q := range quantities {
+		product, err := h.db.GetProductByID(ctx, q.productID)
+		if errors.Is(err, db.ErrNotFound) {
+			missing = append(missing, q.productID)
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		lines = append(lines, helpers.PlanLine{Product: product, Quantity: q.quantity})
+		for _, material := range product.Materials {
+			// Invalid or missing stock is 0 like remaining in calculator.
+			amount, err := h.parseQuantity(values.Get(fmt.Sprintf("stock_%d", material.ID)))
+			if err == nil && amount > 0 {
+				stock[material.ID] = amount
+			}
+		}
+	}
+	return lines, stock, missing, nil
+}
+
+type planQuantity struct {
+	productID int64
+	quantity  int64
+}
+
+// parsePlan reads pairs of plan_product and plan_quantity, lines without product are skipped.
+// Repeated product is planned once with quantities summed.
+func parsePlan(values url.Values) ([]planQuantity, error) {
+	products, quantities := values["plan_product"], values["plan_quantity"]
+	if len(products) != len(quantities) {
+		return nil, errors.New("неполные строки плана")
+	}
+	var plan []planQuantity
+	index := make(map[int64]int)
+	for i, id := range products {
+		if id == "" {
+			continue
+		}
+		productID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, errors.New("неверный идентификатор изделия")
+		}
+		quantity, err := strconv.ParseInt(strings.TrimSpace(quantities[i]), 10, 64)
+		if err != nil || quantity <= 0 {
+			return nil, fmt.Errorf("количество %q должно быть целым числом больше 0", quantities[i])
+		}
+		if j, ok := index[productID]; ok {
+			plan[j].quantity += quantity
+			continue
+		}
+		index[productID] = len(plan)
+		plan = append(plan, planQuantity{productID, quantity})
+	}
+	if len(plan) == 0 {
+		return nil, errors.New("выберите хотя бы одно изделие")
+	}
+	return plan, nil
+}
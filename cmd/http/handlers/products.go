@@ -1,97 +1,81 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/s-588/BOMViewer/internal/helpers"
 	"github.com/s-588/BOMViewer/internal/models"
 	"github.com/s-588/BOMViewer/web/templates"
 )
 
+// ProductPageHandler renders products page, state of table is restored from query parameters,
+// so the page can be opened by link or reloaded.
 func (h *Handler) ProductPageHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse initial filter parameters
-	r.ParseForm()
-
-	sort := r.FormValue("sort")
-
-	// Get all products WITH materials
-	products, err := h.db.GetAllProducts(r.Context())
+	products, args, err := h.productTable(r.Context(), helpers.ParseTableView(r.URL.Query()))
 	if err != nil {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products list", "error", err)
 		return
 	}
-
-	// Apply sorting
-	sortConfig := helpers.ParseSortString(sort)
-	helpers.SortProducts(products, sortConfig)
-
-	// Get materials for filter dropdowns
-	materials, err := h.db.GetAllMaterials(r.Context())
+	args.SavedViews, err = h.db.GetSavedViews(r.Context(), models.ViewProducts)
 	if err != nil {
-		slog.Error("get all materials for filters", "error", err, "where", "ProductPageHandler")
+		slog.Error("can't get saved views", "error", err, "where", "ProductPageHandler")
 	}
 
-	// Parse material filters from form
-	var filtersMaterials []int64
-	if materialIDs := r.Form["materials"]; len(materialIDs) > 0 {
-		filtersMaterials, _ = helpers.StringToInt64Slice(materialIDs)
+	err = renderPage(w, r, templates.MainProductPage(products, args))
+	if err != nil {
+		slog.Error("cannot render products page", "error", err, "where", "ProductPageHandler")
 	}
-
-	templates.MainProductPage(products, templates.ProductTableArgs{
-		Action:           "/products/table",
-		Sort:             sort,
-		FiltersMaterials: filtersMaterials,
-		AllMaterials:     materials,
-	}).Render(r.Context(), w)
 }
 
+// ProductTableHandler renders table with state from controls form and pushes URL
+// of products page with the same state to browser history.
 func (h *Handler) ProductTableHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка обработки формы", "error parsing form", "error", err)
+	products, args, err := h.productTable(r.Context(), helpers.ParseTableView(r.URL.Query()))
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products list", "error", err)
 		return
 	}
 
-	sort := r.FormValue("sort")
-
-	// Get all products WITH materials
-	products, err := h.db.GetAllProducts(r.Context())
+	w.Header().Set("HX-Push-Url", "/products?"+args.View.Values().Encode())
+	err = templates.MainProductList(products, args).Render(r.Context(), w)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка продуктов", "error getting products list", "error", err)
-		return
+		slog.Error("cannot render product list", "error", err, "where", "ProductTableHandler")
 	}
+}
 
-	// Apply sorting
-	sortConfig := helpers.ParseSortString(sort)
-	helpers.SortProducts(products, sortConfig)
+// productTable returns page of products filtered and sorted according to view,
+// page of view is corrected when it's out of range.
+func (h *Handler) productTable(ctx context.Context, view helpers.TableView) ([]models.Product, templates.ProductTableArgs, error) {
+	products, err := h.db.GetAllProducts(ctx)
+	if err != nil {
+		return nil, templates.ProductTableArgs{}, err
+	}
 
 	// Get materials for filter dropdowns
-	materials, err := h.db.GetAllMaterials(r.Context())
+	materials, err := h.db.GetAllMaterials(ctx)
 	if err != nil {
-		slog.Error("get all materials for filters", "error", err, "where", "ProductTableHandler")
+		slog.Error("get all materials for filters", "error", err, "where", "productTable")
 		// Continue without materials
 	}
 
-	// Parse material filters from form
-	var filtersMaterials []int64
-	if materialIDs := r.Form["materials"]; len(materialIDs) > 0 {
-		filtersMaterials, _ = helpers.StringToInt64Slice(materialIDs)
-	}
-
 	filteredProducts := helpers.FilterProducts(products, helpers.ProductFilterArgs{
-		MaterialIDs: filtersMaterials,
+		MaterialIDs: view.MaterialIDs,
 	})
-
-	templates.MainProductList(filteredProducts, templates.ProductTableArgs{
-		Action:           "/products/table",
-		Sort:             sort,
-		FiltersMaterials: filtersMaterials,
-		AllMaterials:     materials,
-	}).Render(r.Context(), w)
+	helpers.SortProducts(filteredProducts, helpers.ParseSortString(view.Sort))
+	products = helpers.Paginate(filteredProducts, &view)
+
+	return products, templates.ProductTableArgs{
+		Action:       "/products/table",
+		View:         view,
+		Total:        len(filteredProducts),
+		AllMaterials: materials,
+	}, nil
 }
 
 func (h *Handler) ProductNewHandler(w http.ResponseWriter, r *http.Request) {
@@ -109,6 +93,18 @@ func (h *Handler) ProductNewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tags, values, err := h.propertiesFromRequest(r.Context(), r, 0)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка в атрибутах: "+err.Error(), "invalid product attributes", "error", err)
+		return
+	}
+
+	materials, err := parseProductMaterials(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка в материалах продукта: "+err.Error(), "invalid product materials", "error", err)
+		return
+	}
+
 	product := models.Product{
 		Name:        name,
 		Description: description,
@@ -120,15 +116,13 @@ func (h *Handler) ProductNewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	materialIDs := r.Form["material_ids"]
-	for _, idStr := range materialIDs {
-		materialID, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "внутренняя ошибка при обработке идентификатора материала", "error parsing material id", "error", err)
-			return
-		}
-		quantity := r.FormValue(fmt.Sprintf("quantity_%d", materialID))
-		err = h.db.AddProductMaterial(r.Context(), productID, materialID, quantity)
+	if err := h.db.SetProductProperties(r.Context(), productID, tags, values); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения тегов и атрибутов продукта: "+err.Error(), "error setting product tags and attributes", "error", err)
+		return
+	}
+
+	for _, material := range materials {
+		err = h.db.AddProductMaterial(r.Context(), productID, material.ID, material.Quantity, material.ScrapPercent)
 		if err != nil {
 			helpers.SetAndLogError(w, http.StatusInternalServerError, "внутренняя ошибка при связывании продукта с материалом", "internal error linking product with material", "error", err)
 			return
@@ -172,7 +166,7 @@ func (h *Handler) ProductViewHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// handle error
 	}
-	templates.ProductView(product, files, &profilePicture).Render(r.Context(), w)
+	templates.ProductView(product, files, &profilePicture, h.materialMasses(r.Context(), product.Materials)).Render(r.Context(), w)
 }
 
 func (h *Handler) ProductUpdateHandler(w http.ResponseWriter, r *http.Request) {
@@ -190,7 +184,13 @@ func (h *Handler) ProductUpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	product, err := getProductFromRequest(r)
 	if err != nil {
-		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения продукта: "+err.Error(), "error getting product from request", "error", err)
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка получения продукта: "+err.Error(), "error getting product from request", "error", err)
+		return
+	}
+
+	tags, values, err := h.propertiesFromRequest(r.Context(), r, 0)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка в атрибутах: "+err.Error(), "invalid product attributes", "error", err)
 		return
 	}
 
@@ -217,6 +217,14 @@ func (h *Handler) ProductUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if r.Form.Has("tags") {
+		err = h.db.SetProductProperties(r.Context(), id, tags, values)
+		if err != nil {
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка обновления тегов и атрибутов продукта: "+err.Error(), "error updating product tags and attributes", "error", err)
+			return
+		}
+	}
+
 	http.Redirect(w, r, fmt.Sprintf("/products/%d", id), http.StatusSeeOther)
 }
 
@@ -228,12 +236,15 @@ func getProductFromRequest(r *http.Request) (models.Product, error) {
 	}
 	product.Name = name
 	product.Description = r.FormValue("description")
-	materials := parseProductMaterials(r)
+	materials, err := parseProductMaterials(r)
+	if err != nil {
+		return product, err
+	}
 	product.Materials = materials
 	return product, nil
 }
 
-func parseProductMaterials(r *http.Request) []models.Material {
+func parseProductMaterials(r *http.Request) ([]models.Material, error) {
 	materialIDs := r.Form["material_ids"] // Changed from "material-ids"
 	materialsList := make([]models.Material, 0, len(materialIDs))
 
@@ -243,12 +254,30 @@ func parseProductMaterials(r *http.Request) []models.Material {
 			continue
 		}
 		quantity := r.FormValue(fmt.Sprintf("quantity_%d", id)) // This matches your form
+		scrap, err := parseScrapPercent(r.FormValue(fmt.Sprintf("scrap_%d", id)))
+		if err != nil {
+			return nil, err
+		}
 		materialsList = append(materialsList, models.Material{
-			ID:       id,
-			Quantity: quantity,
+			ID:           id,
+			Quantity:     quantity,
+			ScrapPercent: scrap,
 		})
 	}
-	return materialsList
+	return materialsList, nil
+}
+
+// parseScrapPercent parses scrap of BOM line, empty value leaves line with default yield of material.
+func parseScrapPercent(value string) (*float64, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+	if value == "" {
+		return nil, nil
+	}
+	scrap, err := strconv.ParseFloat(value, 64)
+	if err != nil || scrap < 0 || scrap >= 100 {
+		return nil, fmt.Errorf("отход %q должен быть числом от 0 до 100", value)
+	}
+	return &scrap, nil
 }
 
 func (h *Handler) ProductFilesListHandler(w http.ResponseWriter, r *http.Request) {
@@ -326,7 +355,12 @@ func (h *Handler) ProductEditHandler(w http.ResponseWriter, r *http.Request) {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов: "+err.Error(), "error getting materials list", "error", err)
 		return
 	}
-	templates.ProductForm(product, materials, "edit").Render(r.Context(), w)
+	attributes, err := h.db.GetAllAttributes(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов: "+err.Error(), "error getting attributes list", "error", err)
+		return
+	}
+	templates.ProductForm(product, materials, helpers.ApplicableAttributes(attributes, nil, 0), fmt.Sprintf("/products/%d", id)).Render(r.Context(), w)
 }
 
 func (h *Handler) ProductCreateHandler(w http.ResponseWriter, r *http.Request) {
@@ -335,7 +369,12 @@ func (h *Handler) ProductCreateHandler(w http.ResponseWriter, r *http.Request) {
 		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка материалов: "+err.Error(), "error getting materials list", "error", err)
 		return
 	}
-	templates.ProductForm(models.Product{}, materials, "new").Render(r.Context(), w)
+	attributes, err := h.db.GetAllAttributes(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения списка атрибутов: "+err.Error(), "error getting attributes list", "error", err)
+		return
+	}
+	templates.ProductForm(models.Product{}, materials, helpers.ApplicableAttributes(attributes, nil, 0), "/products").Render(r.Context(), w)
 }
 
 func (h *Handler) ProductMaterialListHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// MaterialProfilesHandler renders review of attributes parsed from names of steel profiles.
+func (h *Handler) MaterialProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	proposals, err := h.profileProposals(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка разбора наименований: "+err.Error(), "error proposing profiles", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.MaterialProfilesPage(proposals)); err != nil {
+		slog.Error("cannot render profiles page", "error", err, "where", "MaterialProfilesHandler")
+	}
+}
+
+// MaterialProfilesApplyHandler saves values of selected proposals as they were edited on review page.
+// Empty values are skipped, values already set are never overwritten by proposals.
+func (h *Handler) MaterialProfilesApplyHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing form", "error", err)
+		return
+	}
+	materialIDs, err := helpers.StringToInt64Slice(r.PostForm["material_ids"])
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор материала", "invalid material ID", "error", err)
+		return
+	}
+	if len(materialIDs) == 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "не выбрано ни одного материала", "no materials selected", "error", errors.New("empty material_ids"))
+		return
+	}
+	proposals, err := h.profileProposals(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка разбора наименований: "+err.Error(), "error proposing profiles", "error", err)
+		return
+	}
+
+	var changes []models.Material
+	for _, proposal := range proposals {
+		if !slices.Contains(materialIDs, proposal.Material.ID) {
+			continue
+		}
+		change := models.Material{ID: proposal.Material.ID}
+		if proposal.Category.ID != 0 && r.PostFormValue(templates.ProfileCategoryField(proposal.Material.ID)) != "" {
+			change.Category = proposal.Category
+		}
+		for _, proposed := range proposal.Values {
+			raw := r.PostFormValue(templates.ProfileValueField(proposal.Material.ID, proposed.Attribute.ID))
+			value, err := helpers.ParseAttributeValue(proposed.Attribute, raw)
+			if err != nil {
+				msg := fmt.Sprintf("%s: %s", proposal.Material.PrimaryName, err.Error())
+				helpers.SetAndLogError(w, http.StatusBadRequest, msg, "invalid attribute value", "error", err, "material", proposal.Material.ID)
+				return
+			}
+			if value != "" {
+				change.Attributes = append(change.Attributes, models.AttributeValue{AttributeID: proposed.Attribute.ID, Value: value})
+			}
+		}
+		if change.Category.ID != 0 || len(change.Attributes) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	if err := h.db.UpdateMaterialAttributes(r.Context(), changes); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения атрибутов: "+err.Error(), "error applying profiles", "error", err)
+		return
+	}
+
+	proposals, err = h.profileProposals(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка разбора наименований: "+err.Error(), "error proposing profiles", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, fmt.Sprintf("атрибуты заполнены у материалов: %d", len(changes)), "profiles applied", "materials", len(changes))
+	if err := templates.MaterialProfilesPage(proposals).Render(r.Context(), w); err != nil {
+		slog.Error("can't render profiles page", "error", err)
+	}
+}
+
+func (h *Handler) profileProposals(r *http.Request) ([]helpers.ProfileProposal, error) {
+	materials, err := h.db.GetAllMaterialsWithPrimaryNames(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	attributes, categories, err := h.attributesWithCategories(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ProposeProfiles(materials, categories, attributes), nil
+}
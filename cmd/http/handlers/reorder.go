@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// webhookTimeout limits time of posting digest to webhook.
+const webhookTimeout = 10 * time.Second
+
+var errDigestDisabled = errors.New("не указаны файл или вебхук сводки в настройках")
+
+// StockLevelsPageHandler renders form of minimum stock, reorder point and reorder quantity of materials.
+func (h *Handler) StockLevelsPageHandler(w http.ResponseWriter, r *http.Request) {
+	h.renderStockLevels(w, r, "")
+}
+
+// StockLevelsHandler saves levels of all materials of form, empty levels are zero and zero levels are deleted.
+func (h *Handler) StockLevelsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing stock levels form", "error", err)
+		return
+	}
+	materials, _, err := h.stockMaterials(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения материалов: "+err.Error(), "can't get materials for stock levels", "error", err)
+		return
+	}
+	levels := make([]models.StockLevel, 0, len(materials))
+	for _, material := range materials {
+		id := strconv.FormatInt(material.ID, 10)
+		if _, ok := r.PostForm["minimum_"+id]; !ok {
+			continue
+		}
+		level := models.StockLevel{MaterialID: material.ID}
+		for _, field := range []struct {
+			key   string
+			value *float64
+		}{
+			{"minimum_", &level.Minimum},
+			{"reorder_point_", &level.ReorderPoint},
+			{"reorder_quantity_", &level.ReorderQuantity},
+		} {
+			value := strings.TrimSpace(r.PostFormValue(field.key + id))
+			if value == "" {
+				continue
+			}
+			*field.value, err = h.parseQuantity(value)
+			if err != nil || *field.value < 0 {
+				helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": уровни запаса должны быть неотрицательными числами", "invalid stock level", "material", material.ID, "value", value)
+				return
+			}
+		}
+		levels = append(levels, level)
+	}
+	if err := h.db.SaveStockLevels(r.Context(), levels); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения уровней запаса: "+err.Error(), "can't save stock levels", "error", err)
+		return
+	}
+	h.renderStockLevels(w, r, "уровни запаса сохранены")
+}
+
+// ReorderPageHandler renders materials below minimum or reorder point with demand of open orders and scenarios.
+// Scenarios are taken from query, planned scenarios are taken when form wasn't submitted. All materials with levels
+// are listed when all is set.
+func (h *Handler) ReorderPageHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var selected []int64
+	if query.Has("filter") {
+		selected = make([]int64, 0, len(query["scenario"]))
+		for _, value := range query["scenario"] {
+			if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+				selected = append(selected, id)
+			}
+		}
+	}
+	lines, scenarios, err := h.reorderLines(r.Context(), selected)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка расчёта запасов: "+err.Error(), "can't get reorder lines", "error", err)
+		return
+	}
+	if selected == nil {
+		for _, scenario := range scenarios {
+			if scenario.Planned {
+				selected = append(selected, scenario.ID)
+			}
+		}
+	}
+	all := query.Get("all") == "1"
+	if !all {
+		lines = helpers.ReorderAlerts(lines)
+	}
+	args := templates.ReorderPageArgs{
+		Lines:     lines,
+		Scenarios: scenarios,
+		Selected:  selected,
+		All:       all,
+		Digest:    h.cfg.Digest().Enabled(),
+	}
+	if err := renderPage(w, r, templates.ReorderPage(args)); err != nil {
+		slog.Error("cannot render reorder page", "error", err, "where", "ReorderPageHandler")
+	}
+}
+
+// ReorderWidgetHandler renders alert of main page with number of materials to order with planned scenarios,
+// it's empty without alerts.
+func (h *Handler) ReorderWidgetHandler(w http.ResponseWriter, r *http.Request) {
+	lines, _, err := h.reorderLines(r.Context(), nil)
+	if err != nil {
+		slog.Error("can't get reorder lines for widget", "error", err)
+		return
+	}
+	if err := templates.ReorderWidget(helpers.ReorderAlerts(lines)).Render(r.Context(), w); err != nil {
+		slog.Error("can't render reorder widget", "error", err)
+	}
+}
+
+// ReorderDigestHandler makes digest now and sends it to destinations of config.
+func (h *Handler) ReorderDigestHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := h.sendReorderDigest(r.Context())
+	if errors.Is(err, errDigestDisabled) {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "reorder digest is disabled")
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка отправки сводки: "+err.Error(), "can't send reorder digest", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, "сводка отправлена, материалов к заказу: "+strconv.Itoa(n), "reorder digest sent", "alerts", n)
+}
+
+// ReorderDigest makes digest every day at time of config while ctx isn't done. Digest that is due is made
+// right after start, so it isn't missed when app is started later in the day. Date of last digest is kept
+// in database, so restarted app doesn't send it twice a day. Config is read on every check, changed
+// settings apply without restart.
+func (h *Handler) ReorderDigest(ctx context.Context) {
+	h.Go(func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		var last string
+		for {
+			now := time.Now()
+			today := now.Format(time.DateOnly)
+			if cfg := h.cfg.Digest(); cfg.Enabled() && last != today {
+				at, err := time.ParseInLocation("15:04", cfg.Time, now.Location())
+				due := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+				if err == nil && !now.Before(due) {
+					h.runReorderDigest(ctx, today)
+					last = today
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// runReorderDigest sends digest of today unless database tells it was already sent. Date is stored before
+// sending, failed digest isn't retried every minute.
+func (h *Handler) runReorderDigest(ctx context.Context, today string) {
+	sentOn, err := h.db.GetDigestSentOn(ctx)
+	if err != nil {
+		slog.Error("can't get date of last reorder digest", "error", err)
+		return
+	}
+	if sentOn == today {
+		slog.Debug("reorder digest was already sent today")
+		return
+	}
+	if err := h.db.SetDigestSentOn(ctx, today); err != nil {
+		slog.Error("can't store date of reorder digest", "error", err)
+		return
+	}
+	if n, err := h.sendReorderDigest(ctx); err != nil {
+		slog.Error("can't send reorder digest", "error", err)
+	} else {
+		slog.Info("reorder digest sent", "alerts", n)
+	}
+}
+
+// sendReorderDigest writes digest of materials to order with planned scenarios to file of config and posts it
+// to webhook of config. It returns number of materials to order.
+func (h *Handler) sendReorderDigest(ctx context.Context) (int, error) {
+	cfg := h.cfg.Digest()
+	if !cfg.Enabled() {
+		return 0, errDigestDisabled
+	}
+	lines, _, err := h.reorderLines(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	alerts := helpers.ReorderAlerts(lines)
+	digest := helpers.NewDigest(alerts, time.Now())
+	var errs []error
+	if cfg.File != "" {
+		if err := os.WriteFile(cfg.File, []byte(digest.Text), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("can't write digest file: %w", err))
+		}
+	}
+	if cfg.Webhook != "" {
+		if err := postDigest(ctx, cfg.Webhook, digest); err != nil {
+			errs = append(errs, fmt.Errorf("can't post digest to webhook: %w", err))
+		}
+	}
+	return len(alerts), errors.Join(errs...)
+}
+
+func postDigest(ctx context.Context, webhook string, digest helpers.Digest) error {
+	body, err := json.Marshal(digest)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// reorderLines compares stock of materials that have levels with demand of open orders and scenarios
+// with IDs from selected, planned scenarios are taken when selected is nil. All scenarios are returned too.
+func (h *Handler) reorderLines(ctx context.Context, selected []int64) ([]helpers.ReorderLine, []models.Scenario, error) {
+	materials, balances, err := h.stockMaterials(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	levels, err := h.db.GetStockLevels(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	demand, err := h.db.GetOpenOrderDemand(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	scenarios, err := h.db.GetScenarios(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	included := slices.DeleteFunc(slices.Clone(scenarios), func(s models.Scenario) bool {
+		if selected == nil {
+			return !s.Planned
+		}
+		return !slices.Contains(selected, s.ID)
+	})
+	stock := helpers.MaterialStock(balances, materials, 0)
+	return helpers.ReorderLines(materials, levels, stock, demand, included), scenarios, nil
+}
+
+// renderStockLevels renders form of levels, message is shown when it isn't empty.
+func (h *Handler) renderStockLevels(w http.ResponseWriter, r *http.Request, msg string) {
+	materials, balances, err := h.stockMaterials(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения остатков: "+err.Error(), "can't get stock for levels", "error", err)
+		return
+	}
+	levels, err := h.db.GetStockLevels(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения уровней запаса: "+err.Error(), "can't get stock levels", "error", err)
+		return
+	}
+	byMaterial := make(map[int64]models.StockLevel, len(levels))
+	for _, level := range levels {
+		byMaterial[level.MaterialID] = level
+	}
+	if msg != "" {
+		helpers.SetAndLogSuccess(w, msg, "stock levels saved")
+	}
+	args := templates.StockLevelsArgs{
+		Materials: materials,
+		Levels:    byMaterial,
+		Stock:     helpers.MaterialStock(balances, materials, 0),
+	}
+	if err := renderPage(w, r, templates.StockLevelsPage(args)); err != nil {
+		slog.Error("cannot render stock levels page", "error", err, "where", "renderStockLevels")
+	}
+}
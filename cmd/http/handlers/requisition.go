@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// CalculatorExportHandler exports calculator results of product with non-calculable materials as CSV or XLSX,
+// desired quantity and remaining quantities are read from query like in calculator.
+func (h *Handler) CalculatorExportHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "csv" && format != "xlsx" {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неизвестный формат выгрузки", "invalid calculator export format", "format", format)
+		return
+	}
+	product, desiredQuantity, results, nonCalculable, ok := h.calculatorResults(w, r)
+	if !ok {
+		return
+	}
+	table := helpers.CalculationTable(product.Name, desiredQuantity, results, nonCalculable)
+	filename := fmt.Sprintf("materials_%d_%s.%s", product.ID, time.Now().Format("2006-01-02"), format)
+
+	var err error
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+		err = helpers.WriteCSV(w, table)
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+		err = helpers.WriteXLSX(w, "Материалы", table)
+	}
+	if err != nil {
+		slog.Error("can't write calculator export", "format", format, "error", err)
+	}
+}
+
+// CalculatorRequisitionHandler renders printable material requisition from calculator results,
+// it's opened in new tab without layout.
+func (h *Handler) CalculatorRequisitionHandler(w http.ResponseWriter, r *http.Request) {
+	product, desiredQuantity, results, nonCalculable, ok := h.calculatorResults(w, r)
+	if !ok {
+		return
+	}
+	page := templates.MaterialRequisition(product, desiredQuantity, results, nonCalculable, time.Now().Format("02.01.2006"))
+	if err := page.Render(r.Context(), w); err != nil {
+		slog.Error("cannot render material requisition", "error", err, "where", "CalculatorRequisitionHandler")
+	}
+}
+
+// calculatorResults calculates requirements of product from path for desired quantity and remaining quantities of request.
+// Error is written to response when false is returned.
+func (h *Handler) calculatorResults(w http.ResponseWriter, r *http.Request) (models.Product, int64, []models.CalculationResult, []models.Material, bool) {
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "Неверный идентификатор изделия", "invalid product ID for calculator results", "error", err)
+		return models.Product{}, 0, nil, nil, false
+	}
+	desiredQuantity, err := parseDesiredQuantity(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "Неверное желаемое количество", "invalid desired quantity for calculator results", "error", err)
+		return models.Product{}, 0, nil, nil, false
+	}
+	product, err := h.db.GetProductByID(r.Context(), productID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения изделия: "+err.Error(), "can't get product for calculator results", "error", err)
+		return models.Product{}, 0, nil, nil, false
+	}
+	calculable, nonCalculable := h.splitMaterialsByCalculability(product.Materials)
+	return product, desiredQuantity, h.calculate(r, calculable, productID, desiredQuantity), nonCalculable, true
+}
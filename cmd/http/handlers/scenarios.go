@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+// ScenariosPageHandler renders saved scenarios, each marked whether BOM of its products changed since saving.
+func (h *Handler) ScenariosPageHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.scenarioRows(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценариев: "+err.Error(), "can't get scenarios", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.ScenariosPage(rows)); err != nil {
+		slog.Error("cannot render scenarios page", "error", err, "where", "ScenariosPageHandler")
+	}
+}
+
+// ScenarioSaveHandler saves plan form under a name with result of plan and revision of BOM it was computed against.
+func (h *Handler) ScenarioSaveHandler(w http.ResponseWriter, r *http.Request) {
+	lines, stock, ok := h.planFromRequest(w, r)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(r.PostFormValue("scenario_name"))
+	if name == "" || utf8.RuneCountInString(name) > maxViewNameLength {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "название сценария должно быть от 1 до 100 символов", "invalid scenario name", "name", name)
+		return
+	}
+	scenario, err := h.db.SaveScenario(r.Context(), models.Scenario{
+		Name:     name,
+		Query:    planValues(r.PostForm).Encode(),
+		Revision: helpers.BOMRevision(lines),
+		Result:   helpers.ScenarioResultOf(helpers.PlanProduction(lines, stock)),
+	})
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения сценария: "+err.Error(), "can't save scenario", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, "сценарий «"+scenario.Name+"» сохранён", "scenario saved", "id", scenario.ID)
+}
+
+// ScenarioDuplicateHandler copies scenario under a free name and returns updated list.
+func (h *Handler) ScenarioDuplicateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор сценария", "invalid scenario ID", "error", err)
+		return
+	}
+	scenario, err := h.db.GetScenario(r.Context(), id)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценария: "+err.Error(), "can't get scenario to duplicate", "error", err)
+		return
+	}
+	scenarios, err := h.db.GetScenarios(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценариев: "+err.Error(), "can't get scenarios", "error", err)
+		return
+	}
+	scenario.Name = copyName(scenario.Name, scenarios)
+	if _, err := h.db.SaveScenario(r.Context(), scenario); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка копирования сценария: "+err.Error(), "can't duplicate scenario", "error", err)
+		return
+	}
+	h.renderScenarioList(w, r, "сценарий скопирован как «"+scenario.Name+"»")
+}
+
+// ScenarioPlannedHandler marks scenario as planned demand when planned is set in form, unmarks otherwise,
+// and returns updated list.
+func (h *Handler) ScenarioPlannedHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор сценария", "invalid scenario ID", "error", err)
+		return
+	}
+	planned := r.PostFormValue("planned") == "1"
+	err = h.db.SetScenarioPlanned(r.Context(), id, planned)
+	if errors.Is(err, db.ErrNotFound) {
+		helpers.SetAndLogError(w, http.StatusNotFound, "сценарий не найден", "scenario not found", "id", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка изменения сценария: "+err.Error(), "can't set scenario planned", "error", err)
+		return
+	}
+	if planned {
+		h.renderScenarioList(w, r, "сценарий учитывается в потребности")
+	} else {
+		h.renderScenarioList(w, r, "сценарий не учитывается в потребности")
+	}
+}
+
+// ScenarioDeleteHandler deletes scenario and returns updated list.
+func (h *Handler) ScenarioDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор сценария", "invalid scenario ID", "error", err)
+		return
+	}
+	if err := h.db.DeleteScenario(r.Context(), id); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления сценария: "+err.Error(), "can't delete scenario", "error", err)
+		return
+	}
+	h.renderScenarioList(w, r, "сценарий удалён")
+}
+
+// ScenarioCompareHandler re-runs scenario on current BOM and shows it side by side with saved result.
+func (h *Handler) ScenarioCompareHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор сценария", "invalid scenario ID", "error", err)
+		return
+	}
+	scenario, err := h.db.GetScenario(r.Context(), id)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценария: "+err.Error(), "can't get scenario to compare", "error", err)
+		return
+	}
+	lines, stock, _, err := h.rerunScenario(r.Context(), scenario)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка расчёта сценария: "+err.Error(), "can't rerun scenario", "error", err)
+		return
+	}
+	// Deleted products are missing in re-run, comparison shows them as removed.
+	current := helpers.ScenarioResultOf(helpers.PlanProduction(lines, stock))
+	products, materials := helpers.CompareScenario(scenario.Result, current)
+	page := templates.ScenarioComparePage(scenario, helpers.BOMRevision(lines), products, materials)
+	if err := renderPage(w, r, page); err != nil {
+		slog.Error("cannot render scenario comparison", "error", err, "where", "ScenarioCompareHandler")
+	}
+}
+
+// rerunScenario loads products of scenario plan with their current BOM.
+// Products deleted since scenario was saved are left out and their IDs are returned.
+func (h *Handler) rerunScenario(ctx context.Context, scenario models.Scenario) ([]helpers.PlanLine, map[int64]float64, []int64, error) {
+	values, err := url.ParseQuery(scenario.Query)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	quantities, err := parsePlan(values)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return h.loadPlan(ctx, quantities, values)
+}
+
+// scenarioRows returns scenarios with revisions of BOM of their products now.
+// Scenario whose products can't be loaded or were deleted has empty current revision.
+func (h *Handler) scenarioRows(ctx context.Context) ([]templates.ScenarioRow, error) {
+	scenarios, err := h.db.GetScenarios(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]templates.ScenarioRow, len(scenarios))
+	for i, scenario := range scenarios {
+		rows[i].Scenario = scenario
+		lines, _, removed, err := h.rerunScenario(ctx, scenario)
+		if err != nil {
+			slog.Warn("can't rerun scenario", "scenario", scenario.ID, "error", err)
+			continue
+		}
+		if len(removed) > 0 {
+			continue
+		}
+		rows[i].Revision = helpers.BOMRevision(lines)
+	}
+	return rows, nil
+}
+
+func (h *Handler) renderScenarioList(w http.ResponseWriter, r *http.Request, msg string) {
+	rows, err := h.scenarioRows(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сценариев: "+err.Error(), "can't get scenarios", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "scenarios changed")
+	if err := templates.ScenarioList(rows).Render(r.Context(), w); err != nil {
+		slog.Error("can't render scenarios", "error", err)
+	}
+}
+
+// planValues keeps fields of plan form that make scenario, empty stock is dropped.
+func planValues(form url.Values) url.Values {
+	values := url.Values{
+		"plan_product":  form["plan_product"],
+		"plan_quantity": form["plan_quantity"],
+	}
+	for key, value := range form {
+		if strings.HasPrefix(key, "stock_") && strings.TrimSpace(value[0]) != "" {
+			values[key] = value[:1]
+		}
+	}
+	return values
+}
+
+// copyName returns name of copy of scenario that isn't taken by other scenarios.
+func copyName(name string, scenarios []models.Scenario) string {
+	taken := func(name string) bool {
+		return slices.ContainsFunc(scenarios, func(s models.Scenario) bool { return s.Name == name })
+	}
+	candidate := name + " (копия)"
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s (копия %d)", name, n)
+	}
+	return candidate
+}
@@ -7,65 +7,91 @@ import (
 	"strings"
 
 	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
 	"github.com/s-588/BOMViewer/web/templates"
 )
 
+const (
+	suggestionsLimit = 10
+	searchPageSize   = 20
+)
+
+// SearchHandler renders suggestions for search bar, or page of results when view is "page".
+// Page with offset contains only results, they are appended to the previous page.
+// Results on page are narrowed by the same parameters as material and product tables use.
 func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
-	q := cleanFTS5Query(r.URL.Query().Get("q"))
-	// view := r.URL.Query().Get("view")
+	// Query is converted to FTS5 syntax by repository, see package search.
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	view := r.URL.Query().Get("view")
 	dataType := r.URL.Query().Get("type")
-	limit, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
-	if err != nil {
-		limit = 10
-	}
 	if len(q) == 0 {
 		return
 	}
 	slog.Debug("search query:", "query", q)
 
-	switch dataType {
-	case "materials":
-		_, err := h.db.SearchMaterials(r.Context(), q, limit)
+	if view != "page" {
+		page, err := h.db.Search(r.Context(), q, "", models.SearchFilter{}, suggestionsLimit, 0)
 		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка поиска материалов: "+err.Error(), "error searching materials", "error", err)
+			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка поиска: "+err.Error(), "error searching", "error", err)
 			return
 		}
-	case "products":
-		_, err := h.db.SearchProducts(r.Context(), q, limit)
+		err = templates.SearchSuggestions(q, page).Render(r.Context(), w)
 		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка поиска продуктов: "+err.Error(), "error searching products", "error", err)
-			return
-		}
-	default:
-		materials, products, err := h.db.SearchAll(r.Context(), q, limit)
-		if err != nil {
-			helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка поиска: "+err.Error(), "error searching all", "error", err)
-			return
-		}
-
-		slog.Debug("search results:", "materials", materials, "products", products)
-		err = templates.SearchResults("all", "main", materials, products).Render(r.Context(), w)
-		if err != nil {
-			slog.Error("can't render search results", "error", err)
+			slog.Error("can't render search suggestions", "error", err)
 		}
+		return
 	}
-}
 
-// Helper function to clean FTS5 query
-func cleanFTS5Query(query string) string {
-	if query == "" {
-		return ""
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	var itemType string
+	switch dataType {
+	case "materials":
+		itemType = "material"
+	case "products":
+		itemType = "product"
+	default:
+		dataType = "all"
 	}
 
-	specialChars := `"'\,.?!@#$%^&*()-+={}[]|:;<>`
-	var result strings.Builder
+	filter := helpers.ParseSearchFilter(r.URL.Query())
+	page, err := h.db.Search(r.Context(), q, itemType, filter, searchPageSize, offset)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка поиска: "+err.Error(), "error searching", "error", err)
+		return
+	}
+	total := page.MaterialCount + page.ProductCount
+	switch itemType {
+	case "material":
+		total = page.MaterialCount
+	case "product":
+		total = page.ProductCount
+	}
+	args := templates.SearchPageArgs{
+		Query:      q,
+		Type:       dataType,
+		Filter:     filter,
+		Page:       page,
+		NextOffset: offset + int64(len(page.Results)),
+	}
+	args.HasMore = args.NextOffset < total
 
-	for _, char := range query {
-		if strings.ContainsRune(specialChars, char) {
-			continue
+	if offset > 0 {
+		err = templates.SearchResultItems(args).Render(r.Context(), w)
+	} else {
+		args.SavedViews, err = h.db.GetSavedViews(r.Context(), models.ViewSearch)
+		if err != nil {
+			slog.Error("can't get saved searches", "error", err)
+		}
+		args.Categories, err = h.db.GetAllCategories(r.Context())
+		if err != nil {
+			slog.Error("can't get categories", "error", err)
 		}
-		result.WriteRune(char)
+		err = renderPage(w, r, templates.SearchResultsPage(args))
+	}
+	if err != nil {
+		slog.Error("can't render search results", "error", err)
 	}
-
-	return result.String()
 }
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/s-588/BOMViewer/internal/db"
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+const (
+	// stockMovementsLimit is number of latest movements shown on stock page.
+	stockMovementsLimit = 100
+	maxMovementComment  = 500
+	// stockCountComment is default comment of adjustments made by stock-taking.
+	stockCountComment = "Инвентаризация"
+)
+
+// StockPageHandler renders balances by location, form of new movement, latest movements and locations.
+// Movements are filtered by material from query.
+func (h *Handler) StockPageHandler(w http.ResponseWriter, r *http.Request) {
+	materialID, _ := strconv.ParseInt(r.URL.Query().Get("material"), 10, 64)
+	args, err := h.stockPageArgs(r.Context(), materialID)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения остатков: "+err.Error(), "can't get stock", "error", err)
+		return
+	}
+	if err := renderPage(w, r, templates.StockPage(args)); err != nil {
+		slog.Error("cannot render stock page", "error", err, "where", "StockPageHandler")
+	}
+}
+
+// StockMovementHandler records receipt, issue, transfer or adjustment of material and returns updated page.
+func (h *Handler) StockMovementHandler(w http.ResponseWriter, r *http.Request) {
+	movement, err := h.movementFromRequest(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid stock movement", "error", err)
+		return
+	}
+	if err := h.db.AddStockMovements(r.Context(), []models.StockMovement{movement}); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrInsufficientStock) {
+			status = http.StatusBadRequest
+		}
+		helpers.SetAndLogError(w, status, "ошибка проведения движения: "+err.Error(), "can't add stock movement", "error", err)
+		return
+	}
+	h.renderStock(w, r, "проведено движение «"+templates.MovementKindName(movement.Kind)+"»: "+movement.MaterialName)
+}
+
+// LocationNewHandler creates location and returns updated page.
+func (h *Handler) LocationNewHandler(w http.ResponseWriter, r *http.Request) {
+	name, err := locationName(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid location", "error", err)
+		return
+	}
+	if _, err := h.db.InsertLocation(r.Context(), name); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка создания места хранения: "+err.Error(), "can't create location", "error", err)
+		return
+	}
+	h.renderStock(w, r, "место хранения «"+name+"» добавлено")
+}
+
+// LocationUpdateHandler renames location.
+func (h *Handler) LocationUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор места хранения", "invalid location ID", "error", err)
+		return
+	}
+	name, err := locationName(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid location", "error", err)
+		return
+	}
+	if err := h.db.UpdateLocation(r.Context(), models.Location{ID: id, Name: name}); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка изменения места хранения: "+err.Error(), "can't update location", "error", err)
+		return
+	}
+	h.renderStock(w, r, "место хранения «"+name+"» сохранено")
+}
+
+// LocationDeleteHandler deletes location without movements and orders.
+func (h *Handler) LocationDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор места хранения", "invalid location ID", "error", err)
+		return
+	}
+	err = h.db.DeleteLocation(r.Context(), id)
+	if errors.Is(err, db.ErrInUse) {
+		helpers.SetAndLogError(w, http.StatusConflict, "по месту хранения есть движения или заказы, его нельзя удалить", "location is in use", "location", id)
+		return
+	}
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления места хранения: "+err.Error(), "can't delete location", "error", err)
+		return
+	}
+	h.renderStock(w, r, "место хранения удалено")
+}
+
+// StockCountPageHandler renders stock-taking of location from query, first location is taken by default.
+func (h *Handler) StockCountPageHandler(w http.ResponseWriter, r *http.Request) {
+	locationID, _ := strconv.ParseInt(r.URL.Query().Get("location"), 10, 64)
+	h.renderStockCount(w, r, locationID, "")
+}
+
+// StockCountHandler compares counted quantities with balances at location and records differences as adjustments.
+// Materials without counted quantity keep their balances.
+func (h *Handler) StockCountHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing stock count form", "error", err)
+		return
+	}
+	locationID, _ := strconv.ParseInt(r.PostFormValue("location"), 10, 64)
+	locations, err := h.db.GetLocations(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения мест хранения: "+err.Error(), "can't get locations", "error", err)
+		return
+	}
+	i := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == locationID })
+	if i < 0 {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "место хранения не выбрано", "invalid location of stock count", "location", locationID)
+		return
+	}
+	location := locations[i]
+	date, comment, err := movementDateAndComment(r)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, err.Error(), "invalid stock count", "error", err)
+		return
+	}
+	if comment == "" {
+		comment = stockCountComment
+	}
+	materials, balances, err := h.stockMaterials(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения остатков: "+err.Error(), "can't get stock for count", "error", err)
+		return
+	}
+	stock := helpers.MaterialStock(balances, materials, location.ID)
+
+	var adjustments []models.StockMovement
+	for _, material := range materials {
+		value := strings.TrimSpace(r.PostFormValue("counted_" + strconv.FormatInt(material.ID, 10)))
+		if value == "" {
+			continue
+		}
+		counted, err := h.parseQuantity(value)
+		if err != nil || counted < 0 {
+			helpers.SetAndLogError(w, http.StatusBadRequest, material.PrimaryName+": количество должно быть неотрицательным числом", "invalid counted quantity", "material", material.ID, "value", value)
+			return
+		}
+		difference := counted - stock[material.ID]
+		if math.Abs(difference) < 1e-9 {
+			continue
+		}
+		adjustment := models.StockMovement{
+			MaterialID:   material.ID,
+			MaterialName: material.PrimaryName,
+			Unit:         material.Unit.Name,
+			Kind:         models.MovementAdjustment,
+			Quantity:     math.Abs(difference),
+			Date:         date,
+			Comment:      comment,
+		}
+		if difference > 0 {
+			adjustment.To = location
+		} else {
+			adjustment.From = location
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	if err := h.db.AddStockMovements(r.Context(), adjustments); err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка проведения инвентаризации: "+err.Error(), "can't add stock count adjustments", "error", err)
+		return
+	}
+	msg := "расхождений с учётом нет"
+	if len(adjustments) > 0 {
+		msg = "проведено корректировок: " + strconv.Itoa(len(adjustments))
+	}
+	h.renderStockCount(w, r, location.ID, msg)
+}
+
+// materialStock returns balances of materials over all locations, stock is empty when balances can't be loaded.
+func (h *Handler) materialStock(ctx context.Context, materials []models.Material) map[int64]float64 {
+	balances, err := h.db.GetStockBalances(ctx)
+	if err != nil {
+		slog.Error("can't get stock balances", "error", err)
+		return nil
+	}
+	return helpers.MaterialStock(balances, materials, 0)
+}
+
+// stockMaterials returns materials ordered by name and balances of stock.
+func (h *Handler) stockMaterials(ctx context.Context) ([]models.Material, []models.StockBalance, error) {
+	materials, err := h.db.GetAllMaterialsWithPrimaryNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.SortFunc(materials, func(a, b models.Material) int { return strings.Compare(a.PrimaryName, b.PrimaryName) })
+	balances, err := h.db.GetStockBalances(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return materials, balances, nil
+}
+
+func (h *Handler) stockPageArgs(ctx context.Context, materialID int64) (templates.StockPageArgs, error) {
+	materials, balances, err := h.stockMaterials(ctx)
+	if err != nil {
+		return templates.StockPageArgs{}, err
+	}
+	locations, err := h.db.GetLocations(ctx)
+	if err != nil {
+		return templates.StockPageArgs{}, err
+	}
+	movements, err := h.db.GetStockMovements(ctx, materialID, stockMovementsLimit)
+	if err != nil {
+		return templates.StockPageArgs{}, err
+	}
+	return templates.StockPageArgs{
+		Materials:  materials,
+		Locations:  locations,
+		Rows:       helpers.StockRows(balances),
+		Movements:  movements,
+		Limited:    len(movements) == stockMovementsLimit,
+		MaterialID: materialID,
+		Today:      time.Now().Format(time.DateOnly),
+	}, nil
+}
+
+func (h *Handler) renderStock(w http.ResponseWriter, r *http.Request, msg string) {
+	args, err := h.stockPageArgs(r.Context(), 0)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения остатков: "+err.Error(), "can't get stock", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "stock changed")
+	if err := templates.StockPage(args).Render(r.Context(), w); err != nil {
+		slog.Error("can't render stock", "error", err)
+	}
+}
+
+// renderStockCount renders stock-taking of location, message is shown when it isn't empty.
+func (h *Handler) renderStockCount(w http.ResponseWriter, r *http.Request, locationID int64, msg string) {
+	materials, balances, err := h.stockMaterials(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения остатков: "+err.Error(), "can't get stock for count", "error", err)
+		return
+	}
+	locations, err := h.db.GetLocations(r.Context())
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения мест хранения: "+err.Error(), "can't get locations", "error", err)
+		return
+	}
+	if locationID == 0 && len(locations) > 0 {
+		locationID = locations[0].ID
+	}
+	args := templates.StockCountArgs{
+		Materials:  materials,
+		Locations:  locations,
+		LocationID: locationID,
+		Stock:      helpers.MaterialStock(balances, materials, locationID),
+		Today:      time.Now().Format(time.DateOnly),
+	}
+	if msg != "" {
+		helpers.SetAndLogSuccess(w, msg, "stock counted", "location", locationID)
+	}
+	if err := renderPage(w, r, templates.StockCountPage(args)); err != nil {
+		slog.Error("cannot render stock count page", "error", err, "where", "renderStockCount")
+	}
+}
+
+// movementFromRequest reads movement of material between locations required by its kind.
+// Adjustment takes one location: destination adds surplus, source writes off shortage.
+func (h *Handler) movementFromRequest(r *http.Request) (models.StockMovement, error) {
+	if err := r.ParseForm(); err != nil {
+		return models.StockMovement{}, errors.New("ошибка обработки формы")
+	}
+	movement := models.StockMovement{Kind: r.PostFormValue("kind")}
+	if !slices.Contains(templates.MovementKinds, movement.Kind) {
+		return models.StockMovement{}, errors.New("неизвестный вид движения")
+	}
+	materialID, err := strconv.ParseInt(r.PostFormValue("material_id"), 10, 64)
+	if err != nil {
+		return models.StockMovement{}, errors.New("материал не выбран")
+	}
+	material, err := h.db.GetMaterialByID(r.Context(), materialID)
+	if err != nil {
+		return models.StockMovement{}, errors.New("материал не найден")
+	}
+	movement.MaterialID, movement.MaterialName, movement.Unit = material.ID, material.PrimaryName, material.Unit.Name
+
+	movement.Quantity, err = h.parseQuantity(r.PostFormValue("quantity"))
+	if err != nil || movement.Quantity <= 0 {
+		return models.StockMovement{}, errors.New("количество должно быть положительным числом")
+	}
+	movement.Date, movement.Comment, err = movementDateAndComment(r)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	locations, err := h.db.GetLocations(r.Context())
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+	location := func(key string) models.Location {
+		id, _ := strconv.ParseInt(r.PostFormValue(key), 10, 64)
+		i := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == id })
+		if i < 0 {
+			return models.Location{}
+		}
+		return locations[i]
+	}
+	from, to := location("from_location"), location("to_location")
+	switch movement.Kind {
+	case models.MovementReceipt:
+		if to.ID == 0 {
+			return models.StockMovement{}, errors.New("для прихода выберите место «Куда»")
+		}
+		movement.To = to
+	case models.MovementIssue:
+		if from.ID == 0 {
+			return models.StockMovement{}, errors.New("для расхода выберите место «Откуда»")
+		}
+		movement.From = from
+	case models.MovementTransfer:
+		if from.ID == 0 || to.ID == 0 || from.ID == to.ID {
+			return models.StockMovement{}, errors.New("для перемещения выберите два разных места")
+		}
+		movement.From, movement.To = from, to
+	case models.MovementAdjustment:
+		if (from.ID == 0) == (to.ID == 0) {
+			return models.StockMovement{}, errors.New("для корректировки выберите «Куда» для излишка или «Откуда» для недостачи")
+		}
+		movement.From, movement.To = from, to
+	}
+	return movement, nil
+}
+
+// movementDateAndComment reads date of movement, missing date is today.
+func movementDateAndComment(r *http.Request) (string, string, error) {
+	date := strings.TrimSpace(r.PostFormValue("moved_on"))
+	if date == "" {
+		date = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return "", "", errors.New("неверная дата движения")
+	}
+	comment := strings.TrimSpace(r.PostFormValue("comment"))
+	if utf8.RuneCountInString(comment) > maxMovementComment {
+		return "", "", errors.New("комментарий должен быть не длиннее 500 символов")
+	}
+	return date, comment, nil
+}
+
+func locationName(r *http.Request) (string, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", errors.New("ошибка обработки формы")
+	}
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return "", errors.New("название места хранения должно быть от 1 до 100 символов")
+	}
+	return name, nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/s-588/BOMViewer/internal/helpers"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/web/templates"
+)
+
+const maxViewNameLength = 100
+
+// SavedViewSaveHandler saves state of materials or products table, or search query, under a name.
+// State is sent with the same parameters as controls of the page use, and it's stored
+// as query of page URL. Updated menu of saved views is returned.
+func (h *Handler) SavedViewSaveHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "ошибка обработки формы", "error parsing form", "error", err)
+		return
+	}
+
+	view := models.SavedView{
+		Kind: r.PostFormValue("kind"),
+		Name: strings.TrimSpace(r.PostFormValue("view_name")),
+	}
+	if view.Name == "" || utf8.RuneCountInString(view.Name) > maxViewNameLength {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "название должно быть от 1 до 100 символов", "invalid view name", "name", view.Name)
+		return
+	}
+	switch view.Kind {
+	case models.ViewMaterials, models.ViewProducts:
+		state := helpers.ParseTableView(r.PostForm)
+		// View opens the first page, the current one may not exist later.
+		state.Page = 1
+		view.Query = state.Values().Encode()
+	case models.ViewSearch:
+		q := strings.TrimSpace(r.PostFormValue("q"))
+		if q == "" {
+			helpers.SetAndLogError(w, http.StatusBadRequest, "пустой поисковый запрос нельзя сохранить", "empty search query", "error", errors.New("empty query"))
+			return
+		}
+		view.Query = helpers.SearchValues(r.PostFormValue("type"), q, helpers.ParseSearchFilter(r.PostForm)).Encode()
+	default:
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неизвестный тип вида", "unknown saved view kind", "kind", view.Kind)
+		return
+	}
+
+	view, err := h.db.SaveView(r.Context(), view)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка сохранения вида: "+err.Error(), "error saving view", "error", err)
+		return
+	}
+	h.renderSavedViews(w, r, view.Kind, "вид «"+view.Name+"» сохранён")
+}
+
+// SavedViewDeleteHandler deletes saved view and returns updated menu.
+func (h *Handler) SavedViewDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusBadRequest, "неверный идентификатор вида", "invalid view ID", "error", err)
+		return
+	}
+	kind, err := h.db.DeleteSavedView(r.Context(), id)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка удаления вида: "+err.Error(), "error deleting view", "error", err)
+		return
+	}
+	h.renderSavedViews(w, r, kind, "вид удалён")
+}
+
+func (h *Handler) renderSavedViews(w http.ResponseWriter, r *http.Request, kind, msg string) {
+	views, err := h.db.GetSavedViews(r.Context(), kind)
+	if err != nil {
+		helpers.SetAndLogError(w, http.StatusInternalServerError, "ошибка получения сохранённых видов: "+err.Error(), "error getting saved views", "error", err)
+		return
+	}
+	helpers.SetAndLogSuccess(w, msg, "saved views changed", "kind", kind)
+	err = templates.SavedViewsMenu(kind, views).Render(r.Context(), w)
+	if err != nil {
+		slog.Error("can't render saved views", "error", err)
+	}
+}
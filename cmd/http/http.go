@@ -3,10 +3,12 @@ package http
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/s-588/BOMViewer/cmd/config"
 	"github.com/s-588/BOMViewer/cmd/http/handlers"
@@ -19,6 +21,15 @@ var (
 	embededStaticFiles embed.FS
 )
 
+// Timeouts are generous because uploads of big drawings and catalog
+// export can take a while on slow network.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 10 * time.Minute
+	writeTimeout      = 10 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
 type Server struct {
 	ctx         context.Context
 	mux         *http.ServeMux
@@ -26,11 +37,15 @@ type Server struct {
 	handler     *handlers.Handler
 	cfg         *config.Config
 	authManager *middleware.AuthManager
+	srv         *http.Server
+	// headless server is shared between users, so closing of a browser tab must not stop it.
+	headless bool
 }
 
-func NewServer(cancel context.CancelFunc, repo *db.Repository, cfg *config.Config) *Server {
+func NewServer(cancel context.CancelFunc, repo *db.Repository, cfg *config.Config, headless bool) *Server {
 	return &Server{
 		cancel:      cancel,
+		headless:    headless,
 		handler:     handlers.NewHandler(repo, cfg),
 		mux:         http.NewServeMux(),
 		cfg:         cfg,
@@ -46,9 +61,42 @@ func (s *Server) Start(portChan chan int) error {
 	if err != nil {
 		return err
 	}
+	s.srv = &http.Server{
+		Handler:           s.authManager.AuthMiddleware(s.mux),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
 	portChan <- ls.Addr().(*net.TCPAddr).Port
 
-	return http.Serve(ls, s.authManager.AuthMiddleware(s.mux))
+	err = s.srv.Serve(ls)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// ExtractFileTexts starts background extraction of attached documents that are not indexed yet.
+func (s *Server) ExtractFileTexts(ctx context.Context) {
+	s.handler.ExtractFileTexts(ctx)
+}
+
+// ReorderDigest starts daily digest of materials to order configured in settings.
+func (s *Server) ReorderDigest(ctx context.Context) {
+	s.handler.ReorderDigest(ctx)
+}
+
+// Shutdown stops accepting new connections, waits for active requests
+// and then for background workers started by handlers.
+// Repository can be closed safely after it returns.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.srv != nil {
+		if err := s.srv.Shutdown(ctx); err != nil {
+			return fmt.Errorf("can't stop http server: %w", err)
+		}
+	}
+	return s.handler.Wait(ctx)
 }
 
 func (s *Server) setupPaths() {
@@ -58,11 +106,13 @@ func (s *Server) setupPaths() {
 	s.mux.Handle("/static/", http.FileServer(http.FS(embededStaticFiles)))
 	s.mux.HandleFunc("/exit", s.stop)
 
-	s.mux.HandleFunc("GET /search", s.handler.SearchHandler)                                       // return list of materials with checkboxes for forms
+	s.mux.HandleFunc("GET /search", s.handler.SearchHandler)                                       // return search suggestions or page of results
 	s.mux.HandleFunc("GET /materials", s.handler.MaterialPageHandler)                              // Full page
 	s.mux.HandleFunc("GET /materials/table", s.handler.MaterialTableHandler)                       // Table only (for HTMX)
 	s.mux.HandleFunc("GET /materials/picker", s.handler.MaterialsPicker)                           // return list of materials with checkboxes for forms
 	s.mux.HandleFunc("POST /materials", s.handler.MaterialNewHandler)                              // create new material, return new list of materials
+	s.mux.HandleFunc("GET /materials/profiles", s.handler.MaterialProfilesHandler)                 // review attributes parsed from profile names
+	s.mux.HandleFunc("POST /materials/profiles", s.handler.MaterialProfilesApplyHandler)           // apply selected parsed attributes
 	s.mux.HandleFunc("GET /materials/{id}", s.handler.MaterialViewHandler)                         // return material by id
 	s.mux.HandleFunc("POST /materials/{id}", s.handler.MaterialUpdateHandler)                      // update material, return updated material
 	s.mux.HandleFunc("GET /materials/{id}/files", s.handler.MaterialFileListHandler)               // return list of pinned files
@@ -85,6 +135,25 @@ func (s *Server) setupPaths() {
 	s.mux.HandleFunc("GET /products/{id}/edit", s.handler.ProductEditHandler)                    // return form for editing product
 	s.mux.HandleFunc("GET /products/new", s.handler.ProductCreateHandler)                        // return form for creating new product
 
+	s.mux.HandleFunc("GET /categories", s.handler.CategoriesPageHandler)         // page for managing category tree
+	s.mux.HandleFunc("POST /categories", s.handler.CategoryNewHandler)           // create category, return updated tree
+	s.mux.HandleFunc("POST /categories/assign", s.handler.CategoryAssignHandler) // put selected materials into category
+	s.mux.HandleFunc("POST /categories/{id}", s.handler.CategoryUpdateHandler)   // rename or move category, return updated tree
+	s.mux.HandleFunc("DELETE /categories/{id}", s.handler.CategoryDeleteHandler) // delete category, return updated tree
+
+	s.mux.HandleFunc("GET /attributes", s.handler.AttributesPageHandler)          // page for managing attribute definitions
+	s.mux.HandleFunc("POST /attributes", s.handler.AttributeNewHandler)           // create attribute, return updated list
+	s.mux.HandleFunc("GET /attributes/fields", s.handler.AttributeFieldsHandler)  // attribute inputs of material form for category
+	s.mux.HandleFunc("POST /attributes/{id}", s.handler.AttributeUpdateHandler)   // change attribute, return updated list
+	s.mux.HandleFunc("DELETE /attributes/{id}", s.handler.AttributeDeleteHandler) // delete attribute with values, return updated list
+	s.mux.HandleFunc("GET /densities", s.handler.DensitiesPageHandler)            // page for managing densities
+	s.mux.HandleFunc("POST /densities", s.handler.DensityNewHandler)              // create density
+	s.mux.HandleFunc("POST /densities/{id}", s.handler.DensityUpdateHandler)      // update density
+	s.mux.HandleFunc("DELETE /densities/{id}", s.handler.DensityDeleteHandler)    // delete density
+
+	s.mux.HandleFunc("POST /views", s.handler.SavedViewSaveHandler)          // save state of table or search under a name, return menu of saved views
+	s.mux.HandleFunc("DELETE /views/{id}", s.handler.SavedViewDeleteHandler) // delete saved view, return menu of saved views
+
 	s.mux.HandleFunc("GET /files/{id}", s.handler.FileDownload)
 	s.mux.HandleFunc("GET /files/preview/{id}", s.handler.FilePreview)
 	s.mux.HandleFunc("POST /materials/{id}/set-profile-picture/{fileID}", s.handler.SetMaterialProfilePicture)
@@ -95,17 +164,59 @@ func (s *Server) setupPaths() {
 	s.mux.HandleFunc("GET /calculator", s.handler.CalculatorPageHandler)
 	s.mux.HandleFunc("GET /calculator/products/{id}/materials", s.handler.CalculatorProductMaterialsHandler)
 	s.mux.HandleFunc("POST /calculator/calculate", s.handler.CalculatorCalculateHandler)
+	s.mux.HandleFunc("GET /calculator/products/{id}/export", s.handler.CalculatorExportHandler)           // calculator results as CSV or XLSX
+	s.mux.HandleFunc("GET /calculator/products/{id}/requisition", s.handler.CalculatorRequisitionHandler) // printable requisition of materials
+	s.mux.HandleFunc("GET /calculator/cutting", s.handler.CuttingPageHandler)                             // cutting optimizer page
+	s.mux.HandleFunc("POST /calculator/cutting/{id}", s.handler.CuttingPlanHandler)                       // save cut lists and plan cutting
+	s.mux.HandleFunc("GET /calculator/cutting/{id}/print", s.handler.CuttingPrintHandler)                 // printable cutting chart
+	s.mux.HandleFunc("GET /calculator/plan", s.handler.ProductionPlanPageHandler)                         // production plan of several products
+	s.mux.HandleFunc("GET /calculator/plan/line", s.handler.ProductionPlanLineHandler)                    // empty product line of plan
+	s.mux.HandleFunc("POST /calculator/plan", s.handler.ProductionPlanHandler)                            // requirements and shortages of plan
+	s.mux.HandleFunc("POST /calculator/plan/capacity", s.handler.ProductionCapacityHandler)               // maximum sets of plan and bottlenecks
+	s.mux.HandleFunc("GET /calculator/scenarios", s.handler.ScenariosPageHandler)                         // saved scenarios of plan
+	s.mux.HandleFunc("POST /calculator/scenarios", s.handler.ScenarioSaveHandler)                         // save plan form as scenario
+	s.mux.HandleFunc("POST /calculator/scenarios/{id}/duplicate", s.handler.ScenarioDuplicateHandler)     // copy scenario, return list of scenarios
+	s.mux.HandleFunc("GET /calculator/scenarios/{id}/compare", s.handler.ScenarioCompareHandler)          // saved result next to re-run on current BOM
+	s.mux.HandleFunc("POST /calculator/scenarios/{id}/planned", s.handler.ScenarioPlannedHandler)         // mark scenario as planned demand, return list of scenarios
+	s.mux.HandleFunc("DELETE /calculator/scenarios/{id}", s.handler.ScenarioDeleteHandler)                // delete scenario, return list of scenarios
+
+	s.mux.HandleFunc("GET /stock", s.handler.StockPageHandler)                        // balances, movements and locations
+	s.mux.HandleFunc("POST /stock/movements", s.handler.StockMovementHandler)         // record receipt, issue, transfer or adjustment
+	s.mux.HandleFunc("POST /stock/locations", s.handler.LocationNewHandler)           // create location
+	s.mux.HandleFunc("POST /stock/locations/{id}", s.handler.LocationUpdateHandler)   // rename location
+	s.mux.HandleFunc("DELETE /stock/locations/{id}", s.handler.LocationDeleteHandler) // delete location without movements and orders
+	s.mux.HandleFunc("GET /stock/count", s.handler.StockCountPageHandler)             // stock-taking of location
+	s.mux.HandleFunc("POST /stock/count", s.handler.StockCountHandler)                // record differences of stock-taking
+	s.mux.HandleFunc("GET /stock/levels", s.handler.StockLevelsPageHandler)           // minimum stock, reorder point and quantity of materials
+	s.mux.HandleFunc("POST /stock/levels", s.handler.StockLevelsHandler)              // save levels of materials
+	s.mux.HandleFunc("GET /stock/reorder", s.handler.ReorderPageHandler)              // materials below minimum or reorder point
+	s.mux.HandleFunc("GET /stock/reorder/widget", s.handler.ReorderWidgetHandler)     // alert of main page with materials to order
+	s.mux.HandleFunc("POST /stock/reorder/digest", s.handler.ReorderDigestHandler)    // send digest to file and webhook now
+
+	s.mux.HandleFunc("GET /orders", s.handler.OrdersPageHandler)                   // production orders and new order form
+	s.mux.HandleFunc("POST /orders", s.handler.OrderNewHandler)                    // create order with norms of product BOM
+	s.mux.HandleFunc("GET /orders/{id}", s.handler.OrderPageHandler)               // completion form or norm vs actual report
+	s.mux.HandleFunc("POST /orders/{id}/complete", s.handler.OrderCompleteHandler) // record actual consumption and issue it from stock
+	s.mux.HandleFunc("DELETE /orders/{id}", s.handler.OrderDeleteHandler)          // delete open order
 
 	s.mux.HandleFunc("GET /config", s.handler.ConfigPageHandler)
 	s.mux.HandleFunc("POST /config", s.handler.UpdateConfigHandler)
 	s.mux.HandleFunc("DELETE /config", s.handler.ResetConfigHandler)
 	s.mux.HandleFunc("DELETE /config/{field}", s.handler.ResetConfigHandler)
 
+	s.mux.HandleFunc("GET /catalog/export", s.handler.CatalogExportHandler)  // download whole catalog as JSON document
+	s.mux.HandleFunc("POST /catalog/import", s.handler.CatalogImportHandler) // load catalog from JSON document
+
 	s.mux.HandleFunc("GET /login", s.handler.LoginPageHandler)
 	s.mux.HandleFunc("POST /login", s.authManager.LoginHandler)
 	s.mux.HandleFunc("DELETE /login", s.authManager.LogoutHandler)
 }
 
 func (s *Server) stop(w http.ResponseWriter, r *http.Request) {
+	if s.headless {
+		slog.Debug("ignoring exit request in headless mode", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	s.cancel()
 }
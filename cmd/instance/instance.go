@@ -0,0 +1,187 @@
+// Package instance makes sure that only one server works with the same base directory.
+//
+// Running server holds a lock file with its PID, executable and port. Another launch reads the port
+// from the lock, so it can open the browser at the running server instead of starting a new one.
+// Commands that replace the database, like restore, hold the same lock without port.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const lockName = "bomviewer.lock"
+
+// startupWait is how long to wait for the port of instance that is still starting.
+const startupWait = 5 * time.Second
+
+var (
+	ErrRunning = errors.New("another instance is already running")
+	ErrLocked  = errors.New("base directory is used by another command")
+)
+
+type lockInfo struct {
+	PID        int    `json:"pid"`
+	Command    string `json:"command"`
+	Port       int    `json:"port"`
+	Executable string `json:"executable,omitempty"`
+}
+
+// Lock is held by running server, see Acquire.
+type Lock struct {
+	path string
+	info lockInfo
+}
+
+// Acquire creates lock file in dir for command.
+// If lock is held by alive server, ErrRunning is returned together with its port.
+// If it's held by alive process that doesn't serve, like restore, ErrLocked is returned.
+// Lock of process that doesn't exist anymore, or whose PID was reused by another program, is considered
+// stale and taken over, see holder.
+func Acquire(dir, command string) (*Lock, int, error) {
+	l := &Lock{
+		path: filepath.Join(dir, lockName),
+		info: lockInfo{PID: os.Getpid(), Command: command},
+	}
+	if exe, err := os.Executable(); err == nil {
+		l.info.Executable = exe
+	}
+	for range 2 {
+		file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			err = json.NewEncoder(file).Encode(l.info)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(l.path)
+				return nil, 0, fmt.Errorf("can't write lock file: %w", err)
+			}
+			return l, 0, nil
+		}
+		if !os.IsExist(err) {
+			return nil, 0, fmt.Errorf("can't create lock file: %w", err)
+		}
+
+		if info, err := readLock(l.path); err == nil && holder(info) {
+			if port, ok := Running(dir); ok {
+				return nil, port, ErrRunning
+			}
+			return nil, 0, fmt.Errorf("%w: %s, pid %d, lock file %s", ErrLocked, info.Command, info.PID, l.path)
+		}
+		slog.Warn("removing stale lock file", "path", l.path)
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return nil, 0, fmt.Errorf("can't remove stale lock file: %w", err)
+		}
+	}
+	return nil, 0, fmt.Errorf("can't acquire lock file %s", l.path)
+}
+
+// Running reports whether server that holds lock in dir accepts connections and returns its port.
+// Server that is alive but has not recorded port yet is waited for a few seconds.
+// Lock of other commands never has port, they aren't waited for.
+func Running(dir string) (int, bool) {
+	deadline := time.Now().Add(startupWait)
+	for {
+		info, err := readLock(filepath.Join(dir, lockName))
+		if err != nil || !holder(info) {
+			return 0, false
+		}
+		// Locks written before command was recorded were held only by server.
+		if info.Command != "serve" && info.Command != "" {
+			return 0, false
+		}
+		if info.Port != 0 {
+			if !accepts(info.Port) {
+				return 0, false
+			}
+			return info.Port, true
+		}
+		if time.Now().After(deadline) {
+			return 0, false
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// holder reports whether process that wrote lock still runs. PID of exited process may be reused by
+// another program, so executable of process with the PID is compared with executable of lock.
+// When executable can't be found out, server that recorded port must accept connections on it.
+// Lock of other process that can't be checked, like lock written before executable was recorded,
+// is considered held.
+func holder(info lockInfo) bool {
+	if !alive(info.PID) {
+		return false
+	}
+	if exe, ok := executable(info.PID); ok && info.Executable != "" {
+		if sameFile(exe, info.Executable) {
+			return true
+		}
+		slog.Warn("PID of lock is used by another program", "pid", info.PID, "executable", exe, "lock executable", info.Executable)
+		return false
+	}
+	if info.Port != 0 && !accepts(info.Port) {
+		slog.Warn("server of lock doesn't accept connections", "pid", info.PID, "port", info.Port)
+		return false
+	}
+	return true
+}
+
+// sameFile reports whether paths are the same file, or the same path when file doesn't exist anymore.
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	return err == nil && os.SameFile(ia, ib)
+}
+
+// accepts reports whether something listens on port of localhost.
+func accepts(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// SetPort records port of started server in lock file.
+func (l *Lock) SetPort(port int) error {
+	l.info.Port = port
+	data, err := json.Marshal(l.info)
+	if err != nil {
+		return err
+	}
+	// Write to temporary file and rename, so other instance never reads half written lock.
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("can't write lock file: %w", err)
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// Release removes lock file.
+func (l *Lock) Release() error {
+	return os.Remove(l.path)
+}
+
+func readLock(path string) (lockInfo, error) {
+	var info lockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	return info, json.Unmarshal(data, &info)
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// alive reports whether process with pid exists. Signal 0 only checks that process can be signaled,
+// EPERM means that process exists but belongs to another user.
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// executable returns path of executable of process with pid. It's known only where /proc exists
+// and process can be inspected, ok is false otherwise.
+func executable(pid int) (string, bool) {
+	path, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	if err != nil {
+		return "", false
+	}
+	// Executable that was replaced while process runs, e.g. by update, is marked as deleted.
+	return strings.TrimSuffix(path, " (deleted)"), true
+}
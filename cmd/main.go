@@ -2,21 +2,33 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
+	"github.com/s-588/BOMViewer/cmd/browser"
 	"github.com/s-588/BOMViewer/cmd/config"
 	"github.com/s-588/BOMViewer/cmd/http"
+	"github.com/s-588/BOMViewer/cmd/instance"
 	"github.com/s-588/BOMViewer/internal/db"
 	"github.com/s-588/BOMViewer/internal/helpers"
 )
 
 func main() {
+	// Running without arguments (e.g. double click on binary) starts the server.
+	args := os.Args[1:]
+	name := "serve"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+
 	cfg, err := config.NewConfig(config.ConfigName)
 	if err != nil {
 		panic(err)
@@ -26,7 +38,7 @@ func main() {
 		panic(err)
 	}
 
-	err = os.Mkdir(filepath.Join(cfg.BaseDirectory, "logs"), 0644)
+	err = os.Mkdir(filepath.Join(cfg.BaseDirectory, "logs"), 0755)
 	if err != nil && !os.IsExist(err) {
 		panic(err)
 	}
@@ -36,60 +48,122 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	// Other commands may print their results to stdout, so logs must not be mixed with them.
+	logOutput := os.Stderr
+	if name == "serve" {
+		logOutput = os.Stdout
+	}
 	slog.SetDefault(slog.New(slog.NewTextHandler(
-		io.MultiWriter(os.Stdout, logFile), &slog.HandlerOptions{
+		io.MultiWriter(logOutput, logFile), &slog.HandlerOptions{
 			AddSource: true,
 			Level:     helpers.ParseLogLevel(cfg.LogCfg.LogLevel),
 		})))
 
-	ctx, cancel := context.WithCancel(context.Background())
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(context.Background(), cfg, args); err != nil {
+		slog.Error("command failed", "command", name, "error", err)
+		os.Exit(1)
+	}
+}
+
+func serve(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	noBrowser := flags.Bool("no-browser", false, "don't open browser after start and run as shared server, same as server.headless in config")
+	flags.Parse(args)
+	headless := *noBrowser || cfg.ServerCfg.Headless
+
+	lock, port, err := instance.Acquire(cfg.BaseDirectory, "serve")
+	if errors.Is(err, instance.ErrRunning) {
+		slog.Info("app is already running", "port", port)
+		if headless {
+			return err
+		}
+		return browser.New(false).Open(fmt.Sprintf("http://localhost:%d/welcome", port))
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	repo, err := db.NewRepository(ctx, cfg)
 	if err != nil {
-		slog.Info("error creating repository:", "error", err)
-		return
+		return fmt.Errorf("error creating repository: %w", err)
 	}
 	slog.Info("database connected", "dbPath", cfg.DBCfg.DBName)
+	// Deferred calls run in reverse order, so database is closed after server shutdown.
 	defer repo.Close()
 
+	server := http.NewServer(cancel, repo, cfg, headless)
+	server.ExtractFileTexts(ctx)
+	server.ReorderDigest(ctx)
 	portChan := make(chan int)
+	errChan := make(chan error, 1)
 	go func() {
 		slog.Info("starting server", "port", cfg.ServerCfg.ServerPort)
+		errChan <- server.Start(portChan)
+	}()
 
-		err = http.NewServer(cancel, repo, cfg).Start(portChan)
+	// Server error doesn't skip shutdown, background workers are stopped and awaited on every exit path.
+	var serveErr error
+	select {
+	case port := <-portChan:
+		if err := lock.SetPort(port); err != nil {
+			slog.Error("can't record port in lock file", "error", err)
+		}
+		err = browser.New(headless).Open(fmt.Sprintf("http://localhost:%d/welcome", port))
 		if err != nil {
-			slog.Error("error starting server:", "error", err)
-			cancel()
+			slog.Error("can't open browser", "error", err)
 		}
-	}()
-	time.Sleep(100 * time.Millisecond)
-
-	port := <-portChan
-	slog.Info("opening browser", "addr", fmt.Sprintf("http://localhost:%d/welcome", port))
-	err = exec.Command("cmd", "/C", "start", fmt.Sprintf("http://localhost:%d/welcome", port)).Run()
-	if err != nil {
-		slog.Error("can't open browser", "error", err)
+		select {
+		case <-ctx.Done():
+			slog.Info("received shutdown signal, stopping the app")
+		case err := <-errChan:
+			if err != nil {
+				serveErr = fmt.Errorf("server stopped: %w", err)
+			}
+		}
+	case err := <-errChan:
+		serveErr = fmt.Errorf("error starting server: %w", err)
 	}
+	cancel()
 
-	<-ctx.Done()
-	slog.Info("received shutdown signal, stopping the app")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errors.Join(serveErr, err)
+	}
+	if serveErr != nil {
+		return serveErr
+	}
+	slog.Info("server stopped")
+	return nil
 }
 
+// shutdownTimeout limits time for finishing active requests after shutdown signal.
+const shutdownTimeout = 30 * time.Second
+
 func initFolders(cfg *config.Config) error {
-	err := os.MkdirAll(cfg.BaseDirectory, 0644)
+	err := os.MkdirAll(cfg.BaseDirectory, 0755)
 	if err != nil {
 		return fmt.Errorf("can't create base folders: %w", err)
 	}
 
 	uploadsPath := filepath.Join(cfg.BaseDirectory, cfg.ServerCfg.UploadsDir)
-	err = os.MkdirAll(uploadsPath, 0644)
+	err = os.MkdirAll(uploadsPath, 0755)
 	if err != nil {
 		return fmt.Errorf("can't create uploads folders: %w", err)
 	}
 
 	databasePath := filepath.Join(cfg.BaseDirectory, filepath.Dir(cfg.DBCfg.DBName))
-	err = os.MkdirAll(databasePath, 0644)
+	err = os.MkdirAll(databasePath, 0755)
 	if err != nil {
 		return fmt.Errorf("can't create database folders: %w", err)
 	}
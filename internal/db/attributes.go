@@ -0,0 +1,297 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// GetAllAttributes returns global attributes first, then attributes of categories, each group ordered by name.
+func (r *Repository) GetAllAttributes(ctx context.Context) ([]models.Attribute, error) {
+	rows, err := r.queries.GetAllAttributes(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	attributes := make([]models.Attribute, 0, len(rows))
+	for _, row := range rows {
+		attributes = append(attributes, attribute(row))
+	}
+	return attributes, nil
+}
+
+// InsertAttribute creates attribute definition.
+func (r *Repository) InsertAttribute(ctx context.Context, a models.Attribute) (models.Attribute, error) {
+	row, err := r.queries.InsertAttribute(ctx, db.InsertAttributeParams{
+		Name:       a.Name,
+		Kind:       a.Kind,
+		Unit:       a.Unit,
+		Options:    strings.Join(a.Options, "\n"),
+		CategoryID: nullID(a.CategoryID),
+	})
+	if err != nil {
+		return models.Attribute{}, parseError(err)
+	}
+	return attribute(row), nil
+}
+
+// UpdateAttribute changes attribute definition. Stored values are kept as is,
+// so value that doesn't match new kind or options is shown as text until it's edited.
+// Search entries of owners of values are refreshed by trigger, in app indexing mode the index is rebuilt.
+func (r *Repository) UpdateAttribute(ctx context.Context, a models.Attribute) error {
+	err := r.queries.UpdateAttribute(ctx, db.UpdateAttributeParams{
+		Name:        a.Name,
+		Kind:        a.Kind,
+		Unit:        a.Unit,
+		Options:     strings.Join(a.Options, "\n"),
+		CategoryID:  nullID(a.CategoryID),
+		AttributeID: a.ID,
+	})
+	if err != nil {
+		return parseError(err)
+	}
+	if r.appIndexing {
+		if err := r.ReindexSearch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAttribute deletes attribute with all its values.
+func (r *Repository) DeleteAttribute(ctx context.Context, id int64) error {
+	if err := r.queries.DeleteAttribute(ctx, id); err != nil {
+		return parseError(err)
+	}
+	if r.appIndexing {
+		if err := r.ReindexSearch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetMaterialProperties replaces tags and attribute values of material.
+func (r *Repository) SetMaterialProperties(ctx context.Context, materialID int64, tags []string, values []models.AttributeValue) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteMaterialTags(ctx, materialID); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			err := q.InsertMaterialTag(ctx, db.InsertMaterialTagParams{MaterialID: materialID, Tag: tag})
+			if err != nil {
+				return err
+			}
+		}
+		if err := q.DeleteMaterialAttributes(ctx, materialID); err != nil {
+			return err
+		}
+		for _, value := range values {
+			err := q.SetMaterialAttribute(ctx, db.SetMaterialAttributeParams{
+				MaterialID:  materialID,
+				AttributeID: value.AttributeID,
+				Value:       value.Value,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
+}
+
+// SetProductProperties replaces tags and attribute values of product.
+func (r *Repository) SetProductProperties(ctx context.Context, productID int64, tags []string, values []models.AttributeValue) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteProductTags(ctx, productID); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			err := q.InsertProductTag(ctx, db.InsertProductTagParams{ProductID: productID, Tag: tag})
+			if err != nil {
+				return err
+			}
+		}
+		if err := q.DeleteProductAttributes(ctx, productID); err != nil {
+			return err
+		}
+		for _, value := range values {
+			err := q.SetProductAttribute(ctx, db.SetProductAttributeParams{
+				ProductID:   productID,
+				AttributeID: value.AttributeID,
+				Value:       value.Value,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchProduct, productID})
+	return nil
+}
+
+// UpdateMaterialAttributes sets given attribute values of materials in one transaction,
+// other values are kept. Material with Category.ID is also put into the category.
+func (r *Repository) UpdateMaterialAttributes(ctx context.Context, materials []models.Material) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		for _, material := range materials {
+			if material.Category.ID != 0 {
+				err := q.SetMaterialCategory(ctx, db.SetMaterialCategoryParams{
+					MaterialID: material.ID,
+					CategoryID: material.Category.ID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			for _, value := range material.Attributes {
+				err := q.SetMaterialAttribute(ctx, db.SetMaterialAttributeParams{
+					MaterialID:  material.ID,
+					AttributeID: value.AttributeID,
+					Value:       value.Value,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return parseError(err)
+	}
+	items := make([]searchItem, 0, len(materials))
+	for _, material := range materials {
+		items = append(items, searchItem{searchMaterial, material.ID})
+	}
+	r.updateSearchIndex(ctx, items...)
+	return nil
+}
+
+// loadMaterialProperties fills tags and attribute values of material.
+func (r *Repository) loadMaterialProperties(ctx context.Context, material *models.Material) error {
+	tags, err := r.queries.GetMaterialTags(ctx, material.ID)
+	if err != nil {
+		return parseError(err)
+	}
+	rows, err := r.queries.GetMaterialAttributes(ctx, material.ID)
+	if err != nil {
+		return parseError(err)
+	}
+	material.Tags = tags
+	for _, row := range rows {
+		material.Attributes = append(material.Attributes, models.AttributeValue{
+			AttributeID: row.AttributeID,
+			Name:        row.Name,
+			Kind:        row.Kind,
+			Unit:        row.Unit,
+			Value:       row.Value,
+		})
+	}
+	return nil
+}
+
+// attachMaterialProperties fills tags and attribute values of all materials with two queries.
+func (r *Repository) attachMaterialProperties(ctx context.Context, materials map[int64]*models.Material) error {
+	tags, err := r.queries.GetAllMaterialTags(ctx)
+	if err != nil {
+		return parseError(err)
+	}
+	for _, row := range tags {
+		if material, ok := materials[row.MaterialID]; ok {
+			material.Tags = append(material.Tags, row.Tag)
+		}
+	}
+	rows, err := r.queries.GetAllMaterialAttributes(ctx)
+	if err != nil {
+		return parseError(err)
+	}
+	for _, row := range rows {
+		if material, ok := materials[row.MaterialID]; ok {
+			material.Attributes = append(material.Attributes, models.AttributeValue{
+				AttributeID: row.AttributeID,
+				Name:        row.Name,
+				Kind:        row.Kind,
+				Unit:        row.Unit,
+				Value:       row.Value,
+			})
+		}
+	}
+	return nil
+}
+
+// loadProductProperties fills tags and attribute values of product.
+func (r *Repository) loadProductProperties(ctx context.Context, product *models.Product) error {
+	tags, err := r.queries.GetProductTags(ctx, product.ID)
+	if err != nil {
+		return parseError(err)
+	}
+	rows, err := r.queries.GetProductAttributes(ctx, product.ID)
+	if err != nil {
+		return parseError(err)
+	}
+	product.Tags = tags
+	for _, row := range rows {
+		product.Attributes = append(product.Attributes, models.AttributeValue{
+			AttributeID: row.AttributeID,
+			Name:        row.Name,
+			Kind:        row.Kind,
+			Unit:        row.Unit,
+			Value:       row.Value,
+		})
+	}
+	return nil
+}
+
+// attachProductProperties fills tags and attribute values of all products with two queries.
+func (r *Repository) attachProductProperties(ctx context.Context, products map[int64]*models.Product) error {
+	tags, err := r.queries.GetAllProductTags(ctx)
+	if err != nil {
+		return parseError(err)
+	}
+	for _, row := range tags {
+		if product, ok := products[row.ProductID]; ok {
+			product.Tags = append(product.Tags, row.Tag)
+		}
+	}
+	rows, err := r.queries.GetAllProductAttributes(ctx)
+	if err != nil {
+		return parseError(err)
+	}
+	for _, row := range rows {
+		if product, ok := products[row.ProductID]; ok {
+			product.Attributes = append(product.Attributes, models.AttributeValue{
+				AttributeID: row.AttributeID,
+				Name:        row.Name,
+				Kind:        row.Kind,
+				Unit:        row.Unit,
+				Value:       row.Value,
+			})
+		}
+	}
+	return nil
+}
+
+func attribute(row db.Attribute) models.Attribute {
+	a := models.Attribute{
+		ID:         row.AttributeID,
+		Name:       row.Name,
+		Kind:       row.Kind,
+		Unit:       row.Unit,
+		CategoryID: row.CategoryID.Int64,
+	}
+	if row.Options != "" {
+		a.Options = strings.Split(row.Options, "\n")
+	}
+	return a
+}
@@ -0,0 +1,608 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+var (
+	ErrCatalogNotEmpty     = errors.New("база данных уже содержит материалы, изделия или файлы")
+	ErrUnsupportedCatalog  = errors.New("неподдерживаемая версия формата выгрузки")
+	ErrInconsistentCatalog = errors.New("выгрузка содержит ссылки на несуществующие объекты")
+	ErrStockNotEmpty       = errors.New("база данных содержит движения по складу или производственные заказы, замена каталога удалила бы их")
+)
+
+// ExportCatalog collects units, materials, products with BOM lines, file metadata and stock levels into one document.
+// Every list is ordered by ID, so two exports of the same data are identical.
+func (r *Repository) ExportCatalog(ctx context.Context) (models.Catalog, error) {
+	catalog := models.Catalog{
+		FormatVersion: models.CatalogFormatVersion,
+		ExportedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+
+	var err error
+	catalog.Units, err = r.GetAllUnits(ctx)
+	if err != nil {
+		return models.Catalog{}, err
+	}
+	unitsByID := make(map[int64]models.Unit, len(catalog.Units))
+	for _, unit := range catalog.Units {
+		unitsByID[unit.ID] = unit
+	}
+
+	categoryRows, err := r.queries.ExportCategories(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	catalog.Categories = make([]models.Category, 0, len(categoryRows))
+	categoriesByID := make(map[int64]models.Category, len(categoryRows))
+	for _, row := range categoryRows {
+		category := models.Category{ID: row.CategoryID, ParentID: row.ParentID.Int64, Name: row.Name}
+		catalog.Categories = append(catalog.Categories, category)
+		categoriesByID[category.ID] = category
+	}
+
+	catalog.Attributes, err = r.GetAllAttributes(ctx)
+	if err != nil {
+		return models.Catalog{}, err
+	}
+	catalog.Densities, err = r.GetAllDensities(ctx)
+	if err != nil {
+		return models.Catalog{}, err
+	}
+
+	materialRows, err := r.queries.ExportMaterials(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	nameRows, err := r.queries.ExportMaterialNames(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	names := make(map[int64][]db.ExportMaterialNamesRow)
+	for _, row := range nameRows {
+		names[row.MaterialID] = append(names[row.MaterialID], row)
+	}
+	catalog.Materials = make([]models.Material, 0, len(materialRows))
+	for _, row := range materialRows {
+		material := models.Material{
+			ID:           row.MaterialID,
+			Unit:         unitsByID[row.UnitID],
+			Category:     categoriesByID[row.CategoryID.Int64],
+			Description:  row.Description.String,
+			Names:        []string{},
+			YieldPercent: row.YieldPercent,
+		}
+		for _, name := range names[row.MaterialID] {
+			material.Names = append(material.Names, name.Name)
+			if name.IsPrimary {
+				material.PrimaryName = name.Name
+			}
+		}
+		catalog.Materials = append(catalog.Materials, material)
+	}
+	materialsByID := make(map[int64]*models.Material, len(catalog.Materials))
+	for i := range catalog.Materials {
+		materialsByID[catalog.Materials[i].ID] = &catalog.Materials[i]
+	}
+	if err := r.attachMaterialProperties(ctx, materialsByID); err != nil {
+		return models.Catalog{}, err
+	}
+
+	productRows, err := r.queries.ExportProducts(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	bomRows, err := r.queries.ExportProductMaterials(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	cutRows, err := r.queries.GetAllProductCuts(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	type lineKey struct{ product, material int64 }
+	cuts := make(map[lineKey][]models.Cut)
+	for _, row := range cutRows {
+		key := lineKey{row.ProductID, row.MaterialID}
+		cuts[key] = append(cuts[key], models.Cut{Length: row.Length, Quantity: row.Quantity})
+	}
+	blankRows, err := r.queries.GetAllProductBlanks(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	blanks := make(map[lineKey][]models.Blank)
+	for _, row := range blankRows {
+		key := lineKey{row.ProductID, row.MaterialID}
+		blanks[key] = append(blanks[key], models.Blank{Width: row.Width, Height: row.Height, Quantity: row.Quantity})
+	}
+	bom := make(map[int64][]models.Material)
+	for _, row := range bomRows {
+		bom[row.ProductID.Int64] = append(bom[row.ProductID.Int64], models.Material{
+			ID:           row.MaterialID.Int64,
+			Quantity:     formatStoredQuantity(row.Quantity, row.QuantityText),
+			Cuts:         cuts[lineKey{row.ProductID.Int64, row.MaterialID.Int64}],
+			Blanks:       blanks[lineKey{row.ProductID.Int64, row.MaterialID.Int64}],
+			ScrapPercent: nullFloat(row.ScrapPercent),
+		})
+	}
+	catalog.Products = make([]models.Product, 0, len(productRows))
+	for _, row := range productRows {
+		catalog.Products = append(catalog.Products, models.Product{
+			ID:          row.ProductID,
+			Name:        row.Name,
+			Description: row.Description.String,
+			Materials:   bom[row.ProductID],
+		})
+	}
+	productsByID := make(map[int64]*models.Product, len(catalog.Products))
+	for i := range catalog.Products {
+		productsByID[catalog.Products[i].ID] = &catalog.Products[i]
+	}
+	if err := r.attachProductProperties(ctx, productsByID); err != nil {
+		return models.Catalog{}, err
+	}
+
+	fileRows, err := r.queries.ExportFiles(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	catalog.Files = make([]models.File, 0, len(fileRows))
+	for _, row := range fileRows {
+		catalog.Files = append(catalog.Files, models.File{
+			ID:       row.FileID,
+			Name:     row.Name,
+			Path:     row.Path,
+			MimeType: row.MimeType,
+			FileType: row.FileType.String,
+		})
+	}
+
+	materialFiles, err := r.queries.ExportMaterialFiles(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	productFiles, err := r.queries.ExportProductFiles(ctx)
+	if err != nil {
+		return models.Catalog{}, parseError(err)
+	}
+	catalog.FileLinks = make([]models.FileLink, 0, len(materialFiles)+len(productFiles))
+	for _, row := range materialFiles {
+		catalog.FileLinks = append(catalog.FileLinks, models.FileLink{
+			FileID:     row.FileID.Int64,
+			MaterialID: row.MaterialID.Int64,
+		})
+	}
+	for _, row := range productFiles {
+		catalog.FileLinks = append(catalog.FileLinks, models.FileLink{
+			FileID:    row.FileID.Int64,
+			ProductID: row.ProductID.Int64,
+		})
+	}
+
+	catalog.StockLevels, err = r.GetStockLevels(ctx)
+	if err != nil {
+		return models.Catalog{}, err
+	}
+
+	return catalog, nil
+}
+
+// ImportCatalog loads catalog into database in one transaction.
+// The database must not contain materials, products or files unless replace is true,
+// in that case all existing catalog data, including units, is deleted first.
+// Replace is refused while stock movements or production orders exist, the dump doesn't carry them.
+func (r *Repository) ImportCatalog(ctx context.Context, catalog models.Catalog, replace bool) error {
+	if catalog.FormatVersion != models.CatalogFormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedCatalog, catalog.FormatVersion)
+	}
+	if err := validateCatalog(catalog); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return parseError(err)
+	}
+	defer tx.Rollback()
+	q := r.queries.WithTx(tx)
+
+	if replace {
+		total, err := q.CountStockLedger(ctx)
+		if err != nil {
+			return parseError(err)
+		}
+		if total > 0 {
+			return ErrStockNotEmpty
+		}
+		if err := clearCatalog(ctx, q); err != nil {
+			return parseError(err)
+		}
+	} else {
+		total, err := q.CountCatalogItems(ctx)
+		if err != nil {
+			return parseError(err)
+		}
+		if total > 0 {
+			return ErrCatalogNotEmpty
+		}
+		// Units are seeded by migrations, so they are always replaced.
+		if err := q.ClearUnits(ctx); err != nil {
+			return parseError(err)
+		}
+	}
+
+	for _, unit := range catalog.Units {
+		err := q.ImportUnit(ctx, db.ImportUnitParams{UnitID: unit.ID, Unit: unit.Name})
+		if err != nil {
+			return parseError(err)
+		}
+	}
+
+	// Categories are seeded by migrations like units, but old dumps don't have them,
+	// so the seeded tree is replaced only by categories from dump.
+	if catalog.Categories != nil {
+		if err := q.ClearMaterialCategories(ctx); err != nil {
+			return parseError(err)
+		}
+		if err := q.ClearCategories(ctx); err != nil {
+			return parseError(err)
+		}
+		// Parents are set after all categories exist, because dump is ordered by ID, not by tree.
+		for _, category := range catalog.Categories {
+			err := q.ImportCategory(ctx, db.ImportCategoryParams{CategoryID: category.ID, Name: category.Name})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+		for _, category := range catalog.Categories {
+			if category.ParentID == 0 {
+				continue
+			}
+			err := q.UpdateCategory(ctx, db.UpdateCategoryParams{
+				ParentID:   nullID(category.ParentID),
+				Name:       category.Name,
+				CategoryID: category.ID,
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+	}
+
+	// Attributes are seeded and replaced the same way as categories.
+	if catalog.Attributes != nil {
+		if err := q.ClearAttributes(ctx); err != nil {
+			return parseError(err)
+		}
+		for _, a := range catalog.Attributes {
+			err := q.ImportAttribute(ctx, db.ImportAttributeParams{
+				AttributeID: a.ID,
+				Name:        a.Name,
+				Kind:        a.Kind,
+				Unit:        a.Unit,
+				Options:     strings.Join(a.Options, "\n"),
+				CategoryID:  nullID(a.CategoryID),
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+	}
+
+	if catalog.Densities != nil {
+		if err := q.ClearDensities(ctx); err != nil {
+			return parseError(err)
+		}
+		for _, d := range catalog.Densities {
+			err := q.ImportDensity(ctx, db.ImportDensityParams{DensityID: d.ID, Name: d.Name, Density: d.Density})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+	}
+
+	for _, material := range catalog.Materials {
+		// Dumps made before yields were added have no yield, such material has no waste
+		yield := material.YieldPercent
+		if yield == 0 {
+			yield = 100
+		}
+		err := q.ImportMaterial(ctx, db.ImportMaterialParams{
+			MaterialID:   material.ID,
+			UnitID:       material.Unit.ID,
+			Description:  sql.NullString{String: material.Description, Valid: material.Description != ""},
+			YieldPercent: yield,
+		})
+		if err != nil {
+			return parseError(err)
+		}
+		for _, name := range material.Names {
+			_, err := q.InsertMaterialName(ctx, db.InsertMaterialNameParams{
+				MaterialID: material.ID,
+				Name:       name,
+				IsPrimary:  name == material.PrimaryName,
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+		if material.Category.ID != 0 {
+			err := q.SetMaterialCategory(ctx, db.SetMaterialCategoryParams{
+				MaterialID: material.ID,
+				CategoryID: material.Category.ID,
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+		for _, tag := range material.Tags {
+			err := q.InsertMaterialTag(ctx, db.InsertMaterialTagParams{MaterialID: material.ID, Tag: tag})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+		for _, value := range material.Attributes {
+			err := q.SetMaterialAttribute(ctx, db.SetMaterialAttributeParams{
+				MaterialID:  material.ID,
+				AttributeID: value.AttributeID,
+				Value:       value.Value,
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+	}
+
+	for _, product := range catalog.Products {
+		err := q.ImportProduct(ctx, db.ImportProductParams{
+			ProductID:   product.ID,
+			Name:        product.Name,
+			Description: sql.NullString{String: product.Description, Valid: product.Description != ""},
+		})
+		if err != nil {
+			return parseError(err)
+		}
+		for _, line := range product.Materials {
+			args := db.AddProductMaterialParams{
+				ProductID:  sql.NullInt64{Int64: product.ID, Valid: true},
+				MaterialID: sql.NullInt64{Int64: line.ID, Valid: true},
+			}
+			args.Quantity, args.QuantityText = storedQuantity(line.Quantity)
+			args.ScrapPercent = sqlFloat(line.ScrapPercent)
+			if err := q.AddProductMaterial(ctx, args); err != nil {
+				return parseError(err)
+			}
+			if err := insertProductCuts(ctx, q, product.ID, line.ID, line.Cuts); err != nil {
+				return err
+			}
+			if err := insertProductBlanks(ctx, q, product.ID, line.ID, line.Blanks); err != nil {
+				return err
+			}
+		}
+		for _, tag := range product.Tags {
+			err := q.InsertProductTag(ctx, db.InsertProductTagParams{ProductID: product.ID, Tag: tag})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+		for _, value := range product.Attributes {
+			err := q.SetProductAttribute(ctx, db.SetProductAttributeParams{
+				ProductID:   product.ID,
+				AttributeID: value.AttributeID,
+				Value:       value.Value,
+			})
+			if err != nil {
+				return parseError(err)
+			}
+		}
+	}
+
+	for _, file := range catalog.Files {
+		err := q.ImportFile(ctx, db.ImportFileParams{
+			FileID:   file.ID,
+			Name:     file.Name,
+			Path:     file.Path,
+			MimeType: file.MimeType,
+			FileType: sql.NullString{String: file.FileType, Valid: file.FileType != ""},
+		})
+		if err != nil {
+			return parseError(err)
+		}
+	}
+
+	for _, link := range catalog.FileLinks {
+		var err error
+		if link.MaterialID != 0 {
+			_, err = q.InsertMaterialFile(ctx, db.InsertMaterialFileParams{
+				MaterialID: sql.NullInt64{Int64: link.MaterialID, Valid: true},
+				FileID:     sql.NullInt64{Int64: link.FileID, Valid: true},
+			})
+		} else {
+			_, err = q.InsertProductFile(ctx, db.InsertProductFileParams{
+				ProductID: sql.NullInt64{Int64: link.ProductID, Valid: true},
+				FileID:    sql.NullInt64{Int64: link.FileID, Valid: true},
+			})
+		}
+		if err != nil {
+			return parseError(err)
+		}
+	}
+
+	// Levels of replaced materials are deleted with materials, so levels are only inserted.
+	for _, level := range catalog.StockLevels {
+		err := q.SetStockLevel(ctx, db.SetStockLevelParams{
+			MaterialID:      level.MaterialID,
+			Minimum:         level.Minimum,
+			ReorderPoint:    level.ReorderPoint,
+			ReorderQuantity: level.ReorderQuantity,
+		})
+		if err != nil {
+			return parseError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return parseError(err)
+	}
+	if r.appIndexing {
+		if err := r.ReindexSearch(ctx); err != nil {
+			return err
+		}
+	}
+	slog.Info("catalog imported", "units", len(catalog.Units), "materials", len(catalog.Materials),
+		"products", len(catalog.Products), "files", len(catalog.Files), "stockLevels", len(catalog.StockLevels))
+	return nil
+}
+
+func clearCatalog(ctx context.Context, q *db.Queries) error {
+	steps := []func(context.Context) error{
+		q.ClearProductMaterials,
+		q.ClearMaterialFiles,
+		q.ClearProductFiles,
+		q.ClearFiles,
+		q.ClearMaterialNames,
+		q.ClearMaterials,
+		q.ClearProducts,
+		q.ClearUnits,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCatalog checks that every reference inside catalog points to an entity of the same catalog.
+// Foreign keys would reject broken dump too, but without telling which reference is wrong.
+func validateCatalog(catalog models.Catalog) error {
+	units := make(map[int64]bool, len(catalog.Units))
+	for _, unit := range catalog.Units {
+		units[unit.ID] = true
+	}
+	parents := make(map[int64]int64, len(catalog.Categories))
+	for _, category := range catalog.Categories {
+		parents[category.ID] = category.ParentID
+	}
+	for _, category := range catalog.Categories {
+		if _, ok := parents[category.ParentID]; category.ParentID != 0 && !ok {
+			return fmt.Errorf("%w: родительская категория %d категории %d", ErrInconsistentCatalog, category.ParentID, category.ID)
+		}
+		// Walk to the root, a cycle returns to the category before root is reached.
+		steps := 0
+		for id := category.ParentID; id != 0; id = parents[id] {
+			if id == category.ID || steps > len(parents) {
+				return fmt.Errorf("%w: категория %d вложена в саму себя", ErrInconsistentCatalog, category.ID)
+			}
+			steps++
+		}
+	}
+	attributes := make(map[int64]bool, len(catalog.Attributes))
+	for _, a := range catalog.Attributes {
+		if _, ok := parents[a.CategoryID]; a.CategoryID != 0 && catalog.Categories != nil && !ok {
+			return fmt.Errorf("%w: категория %d атрибута %d", ErrInconsistentCatalog, a.CategoryID, a.ID)
+		}
+		attributes[a.ID] = true
+	}
+	// Old dumps have neither attributes nor their values, so values are checked only against dump attributes.
+	checkValues := func(values []models.AttributeValue, owner string, id int64) error {
+		for _, value := range values {
+			if catalog.Attributes != nil && !attributes[value.AttributeID] {
+				return fmt.Errorf("%w: атрибут %d у %s %d", ErrInconsistentCatalog, value.AttributeID, owner, id)
+			}
+		}
+		return nil
+	}
+	materials := make(map[int64]bool, len(catalog.Materials))
+	for _, material := range catalog.Materials {
+		if err := checkValues(material.Attributes, "материала", material.ID); err != nil {
+			return err
+		}
+		if _, ok := parents[material.Category.ID]; material.Category.ID != 0 && catalog.Categories != nil && !ok {
+			return fmt.Errorf("%w: категория %d материала %d", ErrInconsistentCatalog, material.Category.ID, material.ID)
+		}
+		if !units[material.Unit.ID] {
+			return fmt.Errorf("%w: единица измерения %d материала %d", ErrInconsistentCatalog, material.Unit.ID, material.ID)
+		}
+		if len(material.Names) == 0 {
+			return fmt.Errorf("%w: материал %d без названий", ErrInconsistentCatalog, material.ID)
+		}
+		if !slices.Contains(material.Names, material.PrimaryName) {
+			return fmt.Errorf("%w: основное название %q материала %d нет среди названий", ErrInconsistentCatalog, material.PrimaryName, material.ID)
+		}
+		materials[material.ID] = true
+	}
+	products := make(map[int64]bool, len(catalog.Products))
+	for _, product := range catalog.Products {
+		if err := checkValues(product.Attributes, "изделия", product.ID); err != nil {
+			return err
+		}
+		for _, line := range product.Materials {
+			if !materials[line.ID] {
+				return fmt.Errorf("%w: материал %d в изделии %d", ErrInconsistentCatalog, line.ID, product.ID)
+			}
+		}
+		products[product.ID] = true
+	}
+	files := make(map[int64]bool, len(catalog.Files))
+	for _, file := range catalog.Files {
+		files[file.ID] = true
+	}
+	for _, link := range catalog.FileLinks {
+		switch {
+		case !files[link.FileID]:
+			return fmt.Errorf("%w: файл %d", ErrInconsistentCatalog, link.FileID)
+		case link.MaterialID != 0 && !materials[link.MaterialID]:
+			return fmt.Errorf("%w: материал %d у файла %d", ErrInconsistentCatalog, link.MaterialID, link.FileID)
+		case link.MaterialID == 0 && !products[link.ProductID]:
+			return fmt.Errorf("%w: изделие %d у файла %d", ErrInconsistentCatalog, link.ProductID, link.FileID)
+		}
+	}
+	for _, level := range catalog.StockLevels {
+		if !materials[level.MaterialID] {
+			return fmt.Errorf("%w: материал %d уровня запаса", ErrInconsistentCatalog, level.MaterialID)
+		}
+	}
+	return nil
+}
+
+// formatStoredQuantity converts quantity columns of product_materials to the string used by models.
+// Numbers are printed without trailing zeros, so they are parsed back to the same value.
+func formatStoredQuantity(quantity interface{}, text sql.NullString) string {
+	switch qt := quantity.(type) {
+	case int64:
+		return strconv.FormatInt(qt, 10)
+	case float64:
+		return strconv.FormatFloat(qt, 'f', -1, 64)
+	case string:
+		return qt
+	}
+	return text.String
+}
+
+// storedQuantity is a reverse of formatStoredQuantity.
+// Numeric quantities go to quantity column and everything else goes to quantity_text.
+func storedQuantity(quantity string) (interface{}, sql.NullString) {
+	quantity = strings.TrimSpace(quantity)
+	if quantity == "" {
+		return nil, sql.NullString{}
+	}
+	if q, err := strconv.ParseInt(quantity, 10, 64); err == nil {
+		return q, sql.NullString{}
+	}
+	if q, err := strconv.ParseFloat(quantity, 64); err == nil {
+		return q, sql.NullString{}
+	}
+	return nil, sql.NullString{String: quantity, Valid: true}
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+var ErrCategoryCycle = errors.New("категория не может быть вложена в саму себя или в свою подкатегорию")
+
+// GetAllCategories returns all categories ordered by name, tree is built by ParentID.
+func (r *Repository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	rows, err := r.queries.GetAllCategories(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	categories := make([]models.Category, 0, len(rows))
+	for _, row := range rows {
+		categories = append(categories, models.Category{
+			ID:            row.CategoryID,
+			ParentID:      row.ParentID.Int64,
+			Name:          row.Name,
+			MaterialCount: row.MaterialCount,
+		})
+	}
+	return categories, nil
+}
+
+// InsertCategory creates category under parent, parent 0 creates top level category.
+func (r *Repository) InsertCategory(ctx context.Context, parentID int64, name string) (models.Category, error) {
+	row, err := r.queries.InsertCategory(ctx, db.InsertCategoryParams{
+		ParentID: nullID(parentID),
+		Name:     name,
+	})
+	if err != nil {
+		return models.Category{}, parseError(err)
+	}
+	return models.Category{ID: row.CategoryID, ParentID: row.ParentID.Int64, Name: row.Name}, nil
+}
+
+// UpdateCategory renames category and moves it under parent.
+// Category can't be moved into its own subtree, that would detach it from the root.
+func (r *Repository) UpdateCategory(ctx context.Context, category models.Category) error {
+	if category.ParentID != 0 {
+		categories, err := r.GetAllCategories(ctx)
+		if err != nil {
+			return err
+		}
+		parents := make(map[int64]int64, len(categories))
+		for _, c := range categories {
+			parents[c.ID] = c.ParentID
+		}
+		for id := category.ParentID; id != 0; id = parents[id] {
+			if id == category.ID {
+				return ErrCategoryCycle
+			}
+		}
+	}
+	err := r.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+		ParentID:   nullID(category.ParentID),
+		Name:       category.Name,
+		CategoryID: category.ID,
+	})
+	return parseError(err)
+}
+
+// DeleteCategory deletes category without subcategories, its materials become uncategorized.
+func (r *Repository) DeleteCategory(ctx context.Context, id int64) error {
+	err := r.queries.DeleteCategory(ctx, id)
+	if isForeignKeyError(err) {
+		return ErrInUse
+	}
+	return parseError(err)
+}
+
+// SetMaterialCategory puts material into category, category 0 makes material uncategorized.
+func (r *Repository) SetMaterialCategory(ctx context.Context, materialID, categoryID int64) error {
+	if categoryID == 0 {
+		return parseError(r.queries.UnsetMaterialCategory(ctx, materialID))
+	}
+	err := r.queries.SetMaterialCategory(ctx, db.SetMaterialCategoryParams{
+		MaterialID: materialID,
+		CategoryID: categoryID,
+	})
+	return parseError(err)
+}
+
+// AssignCategory puts all materials into category in one transaction.
+func (r *Repository) AssignCategory(ctx context.Context, categoryID int64, materialIDs []int64) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		for _, id := range materialIDs {
+			var err error
+			if categoryID == 0 {
+				err = q.UnsetMaterialCategory(ctx, id)
+			} else {
+				err = q.SetMaterialCategory(ctx, db.SetMaterialCategoryParams{MaterialID: id, CategoryID: categoryID})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return parseError(err)
+}
+
+// category converts LEFT JOIN columns of material query, missing category is a zero value.
+func category(id sql.NullInt64, name sql.NullString) models.Category {
+	if !id.Valid {
+		return models.Category{}
+	}
+	return models.Category{ID: id.Int64, Name: name.String}
+}
+
+// nullID converts optional reference, 0 is stored as NULL.
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
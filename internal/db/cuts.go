@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// attachProductCuts fills cut lists of BOM lines of product.
+func (r *Repository) attachProductCuts(ctx context.Context, productID int64, materials []models.Material) error {
+	rows, err := r.queries.GetProductCuts(ctx, productID)
+	if err != nil {
+		return parseError(err)
+	}
+	cuts := make(map[int64][]models.Cut)
+	for _, row := range rows {
+		cuts[row.MaterialID] = append(cuts[row.MaterialID], models.Cut{Length: row.Length, Quantity: row.Quantity})
+	}
+	for i := range materials {
+		materials[i].Cuts = cuts[materials[i].ID]
+	}
+	return nil
+}
+
+// SetProductCuts replaces cut list of material in product, empty cuts clear it.
+func (r *Repository) SetProductCuts(ctx context.Context, productID, materialID int64, cuts []models.Cut) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		err := q.DeleteProductMaterialCuts(ctx, db.DeleteProductMaterialCutsParams{ProductID: productID, MaterialID: materialID})
+		if err != nil {
+			return parseError(err)
+		}
+		return insertProductCuts(ctx, q, productID, materialID, cuts)
+	})
+}
+
+func insertProductCuts(ctx context.Context, q *db.Queries, productID, materialID int64, cuts []models.Cut) error {
+	for _, cut := range cuts {
+		err := q.InsertProductCut(ctx, db.InsertProductCutParams{
+			ProductID:  productID,
+			MaterialID: materialID,
+			Length:     cut.Length,
+			Quantity:   cut.Quantity,
+		})
+		if err != nil {
+			return parseError(err)
+		}
+	}
+	return nil
+}
+
+// attachProductBlanks fills blanks of sheet BOM lines of product.
+func (r *Repository) attachProductBlanks(ctx context.Context, productID int64, materials []models.Material) error {
+	rows, err := r.queries.GetProductBlanks(ctx, productID)
+	if err != nil {
+		return parseError(err)
+	}
+	blanks := make(map[int64][]models.Blank)
+	for _, row := range rows {
+		blanks[row.MaterialID] = append(blanks[row.MaterialID], models.Blank{Width: row.Width, Height: row.Height, Quantity: row.Quantity})
+	}
+	for i := range materials {
+		materials[i].Blanks = blanks[materials[i].ID]
+	}
+	return nil
+}
+
+// SetProductBlanks replaces blanks of material in product, empty blanks clear them.
+func (r *Repository) SetProductBlanks(ctx context.Context, productID, materialID int64, blanks []models.Blank) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		err := q.DeleteProductMaterialBlanks(ctx, db.DeleteProductMaterialBlanksParams{ProductID: productID, MaterialID: materialID})
+		if err != nil {
+			return parseError(err)
+		}
+		return insertProductBlanks(ctx, q, productID, materialID, blanks)
+	})
+}
+
+func insertProductBlanks(ctx context.Context, q *db.Queries, productID, materialID int64, blanks []models.Blank) error {
+	for _, blank := range blanks {
+		err := q.InsertProductBlank(ctx, db.InsertProductBlankParams{
+			ProductID:  productID,
+			MaterialID: materialID,
+			Width:      blank.Width,
+			Height:     blank.Height,
+			Quantity:   blank.Quantity,
+		})
+		if err != nil {
+			return parseError(err)
+		}
+	}
+	return nil
+}
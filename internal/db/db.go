@@ -7,14 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pressly/goose/v3"
 	"github.com/s-588/BOMViewer/cmd/config"
 	db "github.com/s-588/BOMViewer/internal/db/generate"
 	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/internal/search"
 
 	"modernc.org/sqlite"
 	sqlite3 "modernc.org/sqlite/lib"
@@ -26,6 +28,7 @@ var (
 	ErrAlreadyExist   = errors.New("такой объект уже существует")
 	ErrMustBeFilled   = errors.New("обязательные поля должны быть заполнены")
 	ErrIncorrectValue = errors.New("введено некоректное значение")
+	ErrInUse          = errors.New("объект используется в других записях")
 
 	//go:embed sql/migrations/*.sql
 	embededMigrations embed.FS
@@ -34,11 +37,17 @@ var (
 type Repository struct {
 	queries *db.Queries
 	db      *sql.DB
+	// appIndexing is true when search index is maintained by repository methods instead of triggers.
+	appIndexing bool
 }
 
 func NewRepository(ctx context.Context, cfg *config.Config) (*Repository, error) {
-	connStr := filepath.Join(cfg.BaseDirectory, cfg.DBCfg.DBName)
-	conn, err := sql.Open("sqlite", connStr)
+	err := initDB(ctx, DBPath(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize db: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite", dsn(DBPath(cfg), true))
 	if err != nil {
 		return nil, err
 	}
@@ -46,26 +55,53 @@ func NewRepository(ctx context.Context, cfg *config.Config) (*Repository, error)
 		return nil, fmt.Errorf("can't ping db: %w", err)
 	}
 	r := &Repository{
-		queries: db.New(conn),
-		db:      conn,
+		queries:     db.New(conn),
+		db:          conn,
+		appIndexing: cfg.DBCfg.AppIndexing,
 	}
-	err = r.initDB()
-	if err != nil {
-		return nil, fmt.Errorf("can't initialize db: %w", err)
+	if err := r.setupSearchIndexing(ctx); err != nil {
+		return nil, fmt.Errorf("can't setup search indexing: %w", err)
 	}
 
-	return r, conn.Ping()
+	return r, nil
 }
 
-func (r *Repository) initDB() error {
-	goose.SetBaseFS(embededMigrations)
-	if err := goose.SetDialect("sqlite3"); err != nil {
+// busyTimeout is how long a connection waits for lock held by another connection or process
+// before returning SQLITE_BUSY.
+const busyTimeout = 5 * time.Second
+
+// dsn returns connection string for database at path.
+// Pragmas are applied to every connection in pool, WAL allows reading while other process writes.
+// Foreign keys must be disabled for migrations, because SQLite can change tables only by
+// recreating them and dropping old table would trigger ON DELETE actions.
+func dsn(path string, foreignKeys bool) string {
+	fk := 0
+	if foreignKeys {
+		fk = 1
+	}
+	return fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(%d)",
+		path, busyTimeout.Milliseconds(), fk)
+}
+
+// initDB migrates database to the latest version using separate connection without foreign keys.
+func initDB(ctx context.Context, path string) error {
+	conn, err := sql.Open("sqlite", dsn(path, false))
+	if err != nil {
 		return err
 	}
-	if err := goose.Up(r.db, "sql/migrations"); err != nil {
+	defer conn.Close()
+
+	if err := setupGoose(); err != nil {
 		return err
 	}
-	return nil
+	return goose.UpContext(ctx, conn, migrationsDir)
+}
+
+const migrationsDir = "sql/migrations"
+
+func setupGoose() error {
+	goose.SetBaseFS(embededMigrations)
+	return goose.SetDialect("sqlite3")
 }
 
 // Close function close database connection and assumed to call it before app stop.
@@ -97,10 +133,12 @@ func (r *Repository) GetAllMaterials(ctx context.Context) ([]models.Material, er
 					ID:   row.UnitID,
 					Name: row.Unit,
 				},
-				Description: row.Description.String,
-				PrimaryName: row.MaterialName,
-				Names:       []string{},
-				Products:    []models.Product{},
+				Category:     category(row.CategoryID, row.CategoryName),
+				Description:  row.Description.String,
+				PrimaryName:  row.MaterialName,
+				Names:        []string{},
+				Products:     []models.Product{},
+				YieldPercent: row.YieldPercent,
 			}
 
 			if q, ok := row.Quantity.(string); ok {
@@ -148,6 +186,10 @@ func (r *Repository) GetAllMaterials(ctx context.Context) ([]models.Material, er
 		}
 	}
 
+	if err := r.attachMaterialProperties(ctx, materialsMap); err != nil {
+		return nil, err
+	}
+
 	materials := make([]models.Material, 0, len(materialsMap))
 	for _, material := range materialsMap {
 		materials = append(materials, *material)
@@ -174,10 +216,12 @@ func (r *Repository) GetAllMaterialsWithPrimaryNames(ctx context.Context) ([]mod
 					ID:   row.UnitID,
 					Name: row.Unit,
 				},
-				Description: row.Description.String,
-				PrimaryName: row.MaterialName,
-				Names:       []string{},
-				Products:    []models.Product{},
+				Category:     category(row.CategoryID, row.CategoryName),
+				Description:  row.Description.String,
+				PrimaryName:  row.MaterialName,
+				Names:        []string{},
+				Products:     []models.Product{},
+				YieldPercent: row.YieldPercent,
 			}
 
 			if q, ok := row.Quantity.(string); ok {
@@ -209,6 +253,10 @@ func (r *Repository) GetAllMaterialsWithPrimaryNames(ctx context.Context) ([]mod
 		}
 	}
 
+	if err := r.attachMaterialProperties(ctx, materialsMap); err != nil {
+		return nil, err
+	}
+
 	materials := make([]models.Material, 0, len(materialsMap))
 	for _, material := range materialsMap {
 		materials = append(materials, *material)
@@ -240,6 +288,7 @@ func (r *Repository) InsertMaterial(ctx context.Context, material models.Materia
 			return models.Material{}, parseError(err)
 		}
 	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, material.ID})
 
 	return material, nil
 }
@@ -293,17 +342,23 @@ func (r *Repository) GetMaterialByID(ctx context.Context, id int64) (models.Mate
 		products = append(products, product)
 	}
 
-	return models.Material{
+	material := models.Material{
 		ID:          materialRow.MaterialID,
 		Names:       names,
 		PrimaryName: primaryName,
+		Category:    category(materialRow.CategoryID, materialRow.CategoryName),
 		Description: materialRow.Description.String,
 		Unit: models.Unit{
 			ID:   materialRow.UnitID,
 			Name: materialRow.Unit,
 		},
-		Products: products,
-	}, nil
+		Products:     products,
+		YieldPercent: materialRow.YieldPercent,
+	}
+	if err := r.loadMaterialProperties(ctx, &material); err != nil {
+		return models.Material{}, err
+	}
+	return material, nil
 }
 
 func (r *Repository) GetMaterialByName(ctx context.Context, name string) (models.Material, error) {
@@ -372,7 +427,11 @@ func (r *Repository) UpdateMaterialUnit(ctx context.Context, id int64, unit stri
 		MaterialID: id,
 		Unit:       unit,
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, id})
+	return nil
 }
 
 func (r *Repository) UpdateMaterialNames(ctx context.Context, id int64, primaryName string, names []string) error {
@@ -390,6 +449,7 @@ func (r *Repository) UpdateMaterialNames(ctx context.Context, id int64, primaryN
 			return parseError(err)
 		}
 	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, id})
 	return nil
 }
 
@@ -446,7 +506,11 @@ func (r *Repository) InsertMaterialFile(ctx context.Context, materialID, fileID
 		MaterialID: sql.NullInt64{Int64: materialID, Valid: true},
 		FileID:     sql.NullInt64{Int64: fileID, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
 }
 
 func (r *Repository) InsertFile(ctx context.Context, file models.File) (int64, error) {
@@ -479,15 +543,35 @@ func (r *Repository) GetFileByID(ctx context.Context, id int64) (models.File, er
 }
 
 func (r *Repository) DeleteFile(ctx context.Context, id int64) error {
-	return parseError(r.queries.DeleteFile(ctx, id))
+	owners := r.fileSearchItems(ctx, id)
+	if err := r.queries.DeleteFile(ctx, id); err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, owners...)
+	return nil
 }
 
+// DeleteMaterial deletes material with its names and file links.
+// Material that is used in products or has stock movements can't be deleted and ErrInUse is returned.
 func (r *Repository) DeleteMaterial(ctx context.Context, id int64) error {
-	return parseError(r.queries.DeleteMaterial(ctx, id))
+	err := r.queries.DeleteMaterial(ctx, id)
+	if isForeignKeyError(err) {
+		return ErrInUse
+	}
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, id})
+	return nil
 }
 
 func (r *Repository) DeleteProduct(ctx context.Context, id int64) error {
-	return parseError(r.queries.DeleteProduct(ctx, id))
+	items := r.productSearchItems(ctx, id)
+	if err := r.queries.DeleteProduct(ctx, id); err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, items...)
+	return nil
 }
 
 func (r *Repository) GetAllProducts(ctx context.Context) ([]models.Product, error) {
@@ -542,6 +626,10 @@ func (r *Repository) GetAllProducts(ctx context.Context) ([]models.Product, erro
 		}
 	}
 
+	if err := r.attachProductProperties(ctx, productsMap); err != nil {
+		return nil, err
+	}
+
 	// Convert map to slice
 	products := make([]models.Product, 0, len(productsMap))
 	for _, product := range productsMap {
@@ -559,6 +647,7 @@ func (r *Repository) InsertProduct(ctx context.Context, product models.Product)
 	if err != nil {
 		return 0, parseError(err)
 	}
+	r.updateSearchIndex(ctx, searchItem{searchProduct, row.ProductID})
 	return row.ProductID, nil
 }
 
@@ -593,18 +682,35 @@ func (r *Repository) GetProductByID(ctx context.Context, id int64) (models.Produ
 				ID:   row.UnitID,
 				Name: row.Unit,
 			},
-			Description: row.Description.String,
-			PrimaryName: row.MaterialName,
-			Quantity:    quantity,
+			Category:     category(row.CategoryID, row.CategoryName),
+			Description:  row.Description.String,
+			PrimaryName:  row.MaterialName,
+			Quantity:     quantity,
+			YieldPercent: row.YieldPercent,
+			ScrapPercent: nullFloat(row.ScrapPercent),
 		})
 	}
 
-	return models.Product{
+	if err := r.attachLineProperties(ctx, materials); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachProductCuts(ctx, productRow.ProductID, materials); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachProductBlanks(ctx, productRow.ProductID, materials); err != nil {
+		return models.Product{}, err
+	}
+
+	product := models.Product{
 		ID:          productRow.ProductID,
 		Name:        productRow.Name,
 		Description: productRow.Description.String,
 		Materials:   materials,
-	}, nil
+	}
+	if err := r.loadProductProperties(ctx, &product); err != nil {
+		return models.Product{}, err
+	}
+	return product, nil
 }
 
 func (r *Repository) GetProductMaterials(ctx context.Context, id int64) ([]models.Material, error) {
@@ -633,15 +739,34 @@ func (r *Repository) GetProductMaterials(ctx context.Context, id int64) ([]model
 				ID:   row.UnitID,
 				Name: row.Unit,
 			},
-			Description: row.Description.String,
-			PrimaryName: row.MaterialName,
-			Quantity:    quantity,
+			Category:     category(row.CategoryID, row.CategoryName),
+			Description:  row.Description.String,
+			PrimaryName:  row.MaterialName,
+			Quantity:     quantity,
+			YieldPercent: row.YieldPercent,
+			ScrapPercent: nullFloat(row.ScrapPercent),
 		})
 	}
+	if err := r.attachLineProperties(ctx, materials); err != nil {
+		return nil, err
+	}
+	// Calculator counts sheets of lines with blanks by nesting
+	if err := r.attachProductBlanks(ctx, id, materials); err != nil {
+		return nil, err
+	}
 
 	return materials, nil
 }
 
+// attachLineProperties fills attribute values of BOM lines, they are needed for theoretical mass.
+func (r *Repository) attachLineProperties(ctx context.Context, materials []models.Material) error {
+	byID := make(map[int64]*models.Material, len(materials))
+	for i := range materials {
+		byID[materials[i].ID] = &materials[i]
+	}
+	return r.attachMaterialProperties(ctx, byID)
+}
+
 func (r *Repository) GetProductFiles(ctx context.Context, id int64) ([]models.File, error) {
 	fileRows, err := r.queries.GetProductFiles(ctx, sql.NullInt64{Int64: id, Valid: true})
 	if err != nil {
@@ -665,7 +790,11 @@ func (r *Repository) InsertProductFile(ctx context.Context, fileID, productID in
 		FileID:    sql.NullInt64{Int64: fileID, Valid: true},
 		ProductID: sql.NullInt64{Int64: productID, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchProduct, productID})
+	return nil
 }
 
 func (r *Repository) DeleteProductFile(ctx context.Context, productID, fileID int64) error {
@@ -673,13 +802,19 @@ func (r *Repository) DeleteProductFile(ctx context.Context, productID, fileID in
 		ProductID: sql.NullInt64{Int64: productID, Valid: true},
 		FileID:    sql.NullInt64{Int64: fileID, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchProduct, productID})
+	return nil
 }
 
-func (r *Repository) AddProductMaterial(ctx context.Context, productID, materialID int64, quantity string) error {
+// AddProductMaterial adds BOM line, nil scrap means line uses default yield of material.
+func (r *Repository) AddProductMaterial(ctx context.Context, productID, materialID int64, quantity string, scrapPercent *float64) error {
 	req := db.AddProductMaterialParams{
-		ProductID:  sql.NullInt64{Int64: productID, Valid: true},
-		MaterialID: sql.NullInt64{Int64: materialID, Valid: true},
+		ProductID:    sql.NullInt64{Int64: productID, Valid: true},
+		MaterialID:   sql.NullInt64{Int64: materialID, Valid: true},
+		ScrapPercent: sqlFloat(scrapPercent),
 	}
 
 	// Make quantity optional - only set if provided
@@ -693,7 +828,35 @@ func (r *Repository) AddProductMaterial(ctx context.Context, productID, material
 	// If quantity is empty, both Quantity and QuantityText will be NULL/empty
 
 	err := r.queries.AddProductMaterial(ctx, req)
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
+}
+
+// SetMaterialYield changes yield of material in percent.
+func (r *Repository) SetMaterialYield(ctx context.Context, materialID int64, percent float64) error {
+	err := r.queries.SetMaterialYield(ctx, db.SetMaterialYieldParams{YieldPercent: percent, MaterialID: materialID})
+	if err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+// nullFloat converts optional number of database to model, NULL is nil.
+func nullFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+func sqlFloat(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
 }
 
 func (r *Repository) DeleteProductMaterial(ctx context.Context, productID, materialID int64) error {
@@ -701,7 +864,11 @@ func (r *Repository) DeleteProductMaterial(ctx context.Context, productID, mater
 		ProductID:  sql.NullInt64{Int64: productID, Valid: true},
 		MaterialID: sql.NullInt64{Int64: materialID, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
 }
 
 func (r *Repository) UpdateMaterialDescription(ctx context.Context, materialID int64, description string) error {
@@ -709,7 +876,11 @@ func (r *Repository) UpdateMaterialDescription(ctx context.Context, materialID i
 		MaterialID:  materialID,
 		Description: sql.NullString{String: description, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
 }
 
 func parseError(err error) error {
@@ -736,12 +907,31 @@ func parseError(err error) error {
 	return ErrInternal
 }
 
+// isForeignKeyError reports whether err is a foreign key violation.
+// On delete it means that row is still referenced, while parseError assumes insert of missing reference.
+// ON DELETE RESTRICT is implemented by SQLite as a trigger, so it fails with trigger constraint code.
+func isForeignKeyError(err error) bool {
+	var sqliteError *sqlite.Error
+	if !errors.As(err, &sqliteError) {
+		return false
+	}
+	switch sqliteError.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, sqlite3.SQLITE_CONSTRAINT_TRIGGER:
+		return true
+	}
+	return false
+}
+
 func (r *Repository) UpdateProductName(ctx context.Context, id int64, name string) error {
 	err := r.queries.UpdateProductName(ctx, db.UpdateProductNameParams{
 		ProductID: id,
 		Name:      name,
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, r.productSearchItems(ctx, id)...)
+	return nil
 }
 
 func (r *Repository) UpdateProductDescription(ctx context.Context, d int64, description string) error {
@@ -749,7 +939,11 @@ func (r *Repository) UpdateProductDescription(ctx context.Context, d int64, desc
 		ProductID:   d,
 		Description: sql.NullString{String: description, Valid: true},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchProduct, d})
+	return nil
 }
 
 func (r *Repository) GetAllUnits(ctx context.Context) ([]models.Unit, error) {
@@ -775,97 +969,141 @@ func (r *Repository) GetUnitByID(ctx context.Context, id int64) (models.Unit, er
 	}, parseError(err)
 }
 
-func (r *Repository) SearchAll(ctx context.Context, q string, limit int64) ([]models.Material, []models.Product, error) {
-	result, err := r.queries.SearchAll(ctx, db.SearchAllParams{
-		Text:  q,
-		Limit: limit,
+// snippetWords is length of search result snippet in words.
+const snippetWords = 16
+
+// Search finds materials and products by user input, see package search for query syntax.
+// itemType is "material", "product" or empty string for both. Results are ordered by relevance,
+// counts and facets of page are computed over all found items. When nothing is found,
+// words of input are replaced by similar indexed terms.
+func (r *Repository) Search(ctx context.Context, q, itemType string, filter models.SearchFilter, limit, offset int64) (models.SearchPage, error) {
+	page := models.SearchPage{Results: []models.SearchResult{}}
+	match := search.Query(q)
+	if match == "" {
+		return page, nil
+	}
+	facets := db.GetSearchFacetsParams{
+		Terms:       match,
+		UnitIds:     idList(filter.UnitIDs),
+		ProductIds:  idList(filter.ProductIDs),
+		MaterialIds: idList(filter.MaterialIDs),
+		CategoryIds: idList(filter.CategoryIDs),
+		HasFiles:    filter.HasFiles,
+	}
+	if err := r.searchFacets(ctx, facets, &page); err != nil {
+		return page, err
+	}
+	if page.MaterialCount+page.ProductCount == 0 {
+		facets.Terms = r.fuzzyQuery(ctx, q)
+		if facets.Terms == "" {
+			return page, nil
+		}
+		if err := r.searchFacets(ctx, facets, &page); err != nil {
+			return page, err
+		}
+		page.Fuzzy = true
+	}
+
+	rows, err := r.queries.Search(ctx, db.SearchParams{
+		Terms:       facets.Terms,
+		Type:        itemType,
+		UnitIds:     facets.UnitIds,
+		ProductIds:  facets.ProductIds,
+		MaterialIds: facets.MaterialIds,
+		CategoryIds: facets.CategoryIds,
+		HasFiles:    facets.HasFiles,
+		Limit:       limit,
+		Offset:      offset,
 	})
 	if err != nil {
-		return nil, nil, parseError(err)
+		return page, parseError(err)
 	}
-
-	materials := make([]models.Material, 0)
-	products := make([]models.Product, 0)
-	for _, item := range result {
-		id, err := strconv.ParseInt(item.RefID, 10, 64)
-		if err != nil {
-			return nil, nil, errors.New("can't parse id returned from search query")
-		}
-
-		name, ok := item.DisplayName.(string)
-		if !ok {
-			return nil, nil, errors.New("can't convert search result name to string")
+	for _, row := range rows {
+		matched := search.MatchedTerms(row.MatchedTerms)
+		result := models.SearchResult{
+			Type: row.Type,
+			ID:   row.RefID,
+			Name: textFragments(search.Highlight(row.DisplayName, matched)),
 		}
-
-		if item.Type == "material" {
-			materials = append(materials, models.Material{
-				ID:          id,
-				PrimaryName: name,
-			})
-		} else {
-			products = append(products, models.Product{
-				ID:   id,
-				Name: name,
-			})
+		// The first field with matched words is shown in snippet, description is shown
+		// when only name matched. Text of index also has related data like unit and files
+		// when it's maintained by the app.
+		switch {
+		case row.FileID != 0:
+			result.MatchedField = models.MatchedFile
+			result.File = row.FileName
+			// Indexed text of file starts with its name, it's already shown.
+			text := strings.TrimPrefix(row.Text, row.FileName+" ")
+			result.Snippet = textFragments(search.Snippet(text, matched, snippetWords))
+		case search.Matches(row.DisplayName, matched):
+			result.MatchedField = models.MatchedName
+			result.Snippet = textFragments(search.Snippet(row.Description, matched, snippetWords))
+		case search.Matches(row.OtherNames, matched):
+			result.MatchedField = models.MatchedOtherName
+			result.Snippet = textFragments(search.Snippet(row.OtherNames, matched, snippetWords))
+		case search.Matches(row.Description, matched):
+			result.MatchedField = models.MatchedDescription
+			result.Snippet = textFragments(search.Snippet(row.Description, matched, snippetWords))
+		default:
+			result.MatchedField = models.MatchedRelated
+			result.Snippet = textFragments(search.Snippet(row.Text, matched, snippetWords))
 		}
+		page.Results = append(page.Results, result)
 	}
-	return materials, products, nil
+	return page, nil
 }
 
-func (r *Repository) SearchMaterials(ctx context.Context, q string, limit int64) ([]models.Material, error) {
-	result, err := r.queries.SearchMaterials(ctx, db.SearchMaterialsParams{
-		Query: q,
-		Limit: limit,
-	})
-	if err != nil {
-		return nil, parseError(err)
+// idList encodes IDs to list like ",1,2,", search queries use it to filter results.
+// Empty string is returned for empty list, it disables filter.
+func idList(ids []int64) string {
+	if len(ids) == 0 {
+		return ""
 	}
-
-	materials := make([]models.Material, 0, len(result))
-	for _, item := range result {
-		id, err := strconv.ParseInt(item.RefID, 10, 64)
-		if err != nil {
-			return nil, parseError(err)
-		}
-		quantity := item.Quantity.(string)
-		materials = append(materials, models.Material{
-			ID:          id,
-			PrimaryName: item.DisplayName.String,
-			Unit: models.Unit{
-				ID:   item.UnitID,
-				Name: item.Unit,
-			},
-			Description: item.Text,
-			Quantity:    quantity,
-		})
+	var b strings.Builder
+	b.WriteString(",")
+	for _, id := range ids {
+		b.WriteString(strconv.FormatInt(id, 10))
+		b.WriteString(",")
 	}
-
-	return materials, nil
+	return b.String()
 }
 
-func (r *Repository) SearchProducts(ctx context.Context, q string, limit int64) ([]models.Product, error) {
-	result, err := r.queries.SearchProducts(ctx, db.SearchProductsParams{
-		Text:  q,
-		Limit: limit,
-	})
+// searchFacets fills counts and facets of page.
+func (r *Repository) searchFacets(ctx context.Context, arg db.GetSearchFacetsParams, page *models.SearchPage) error {
+	rows, err := r.queries.GetSearchFacets(ctx, arg)
 	if err != nil {
-		return nil, parseError(err)
+		return parseError(err)
 	}
-
-	products := make([]models.Product, 0, len(result))
-	for _, item := range result {
-		id, err := strconv.ParseInt(item.RefID, 10, 64)
-		if err != nil {
-			return nil, parseError(err)
+	page.MaterialCount, page.ProductCount, page.WithFiles = 0, 0, 0
+	page.Units, page.Products, page.Categories = []models.SearchFacet{}, []models.SearchFacet{}, []models.SearchFacet{}
+	for _, row := range rows {
+		facet := models.SearchFacet{ID: row.ID, Name: row.Name, Count: row.Count}
+		switch row.Facet {
+		case "type":
+			if row.Name == searchMaterial {
+				page.MaterialCount = row.Count
+			} else {
+				page.ProductCount = row.Count
+			}
+		case "unit":
+			page.Units = append(page.Units, facet)
+		case "product":
+			page.Products = append(page.Products, facet)
+		case "category":
+			page.Categories = append(page.Categories, facet)
+		case "files":
+			page.WithFiles = row.Count
 		}
-		products = append(products, models.Product{
-			ID:          id,
-			Name:        item.DisplayName.String,
-			Description: item.Text,
-		})
 	}
+	return nil
+}
 
-	return products, nil
+func textFragments(fragments []search.Fragment) []models.TextFragment {
+	result := make([]models.TextFragment, 0, len(fragments))
+	for _, f := range fragments {
+		result = append(result, models.TextFragment{Text: f.Text, Match: f.Match})
+	}
+	return result
 }
 
 func (r *Repository) GetMaterialProfilePicture(ctx context.Context, id int64) (models.File, error) {
@@ -891,10 +1129,15 @@ func (r *Repository) GetProductProfilePicture(ctx context.Context, id int64) (mo
 }
 
 func (r *Repository) DeleteMaterialFile(ctx context.Context, materialID, fileID int64) error {
-	return parseError(r.queries.DeleteMaterialFile(ctx, db.DeleteMaterialFileParams{
+	err := r.queries.DeleteMaterialFile(ctx, db.DeleteMaterialFileParams{
 		FileID:     sql.NullInt64{Int64: fileID, Valid: true},
 		MaterialID: sql.NullInt64{Int64: materialID, Valid: true},
-	}))
+	})
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
+	return nil
 }
 
 func (r *Repository) SetMaterialProfilePicture(ctx context.Context, materialID, fileID int64) error {
@@ -985,15 +1228,26 @@ func (r *Repository) GetAllProductImages(ctx context.Context, productID int64) (
 }
 
 func (r *Repository) UpdateMaterialProducts(ctx context.Context, materialID int64, productIDs []models.Product) error {
-	err := r.queries.DeleteAllMaterialProducts(ctx, sql.NullInt64{Int64: materialID, Valid: true})
+	// Material form doesn't edit scrap of lines, scrap of kept lines is restored after rewrite
+	scrapRows, err := r.queries.GetMaterialLineScraps(ctx, sql.NullInt64{Int64: materialID, Valid: true})
+	if err != nil {
+		return parseError(err)
+	}
+	scraps := make(map[int64]sql.NullFloat64, len(scrapRows))
+	for _, row := range scrapRows {
+		scraps[row.ProductID.Int64] = row.ScrapPercent
+	}
+
+	err = r.queries.DeleteAllMaterialProducts(ctx, sql.NullInt64{Int64: materialID, Valid: true})
 	if err != nil {
 		return parseError(err)
 	}
 
 	for _, product := range productIDs {
 		args := db.AddProductMaterialParams{
-			ProductID:  sql.NullInt64{Int64: product.ID, Valid: true},
-			MaterialID: sql.NullInt64{Int64: materialID, Valid: true},
+			ProductID:    sql.NullInt64{Int64: product.ID, Valid: true},
+			MaterialID:   sql.NullInt64{Int64: materialID, Valid: true},
+			ScrapPercent: scraps[product.ID],
 		}
 		if q, err := strconv.ParseInt(product.Quantity, 10, 64); err == nil {
 			args.Quantity = q
@@ -1005,6 +1259,7 @@ func (r *Repository) UpdateMaterialProducts(ctx context.Context, materialID int6
 			return parseError(err)
 		}
 	}
+	r.updateSearchIndex(ctx, searchItem{searchMaterial, materialID})
 	return nil
 }
 
@@ -1022,11 +1277,18 @@ func (r *Repository) UpdateProduct(ctx context.Context, id int64, name, descript
 		ProductID:   id,
 		Description: sql.NullString{String: description, Valid: description != ""},
 	})
-	return parseError(err)
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, r.productSearchItems(ctx, id)...)
+	return nil
 }
 
 // UpdateProductMaterials updates all materials for a product
 func (r *Repository) UpdateProductMaterials(ctx context.Context, productID int64, materials []models.Material) error {
+	// Materials removed from product must be reindexed too
+	removed := r.productMaterialItems(ctx, productID)
+
 	// Delete all existing material associations
 	err := r.queries.DeleteAllProductMaterials(ctx, sql.NullInt64{Int64: productID, Valid: true})
 	if err != nil {
@@ -1036,8 +1298,9 @@ func (r *Repository) UpdateProductMaterials(ctx context.Context, productID int64
 	// Add new material associations
 	for _, material := range materials {
 		args := db.AddProductMaterialParams{
-			ProductID:  sql.NullInt64{Int64: productID, Valid: true},
-			MaterialID: sql.NullInt64{Int64: material.ID, Valid: true},
+			ProductID:    sql.NullInt64{Int64: productID, Valid: true},
+			MaterialID:   sql.NullInt64{Int64: material.ID, Valid: true},
+			ScrapPercent: sqlFloat(material.ScrapPercent),
 		}
 
 		// Handle quantity - similar to how materials handle it
@@ -1054,5 +1317,13 @@ func (r *Repository) UpdateProductMaterials(ctx context.Context, productID int64
 			return parseError(err)
 		}
 	}
+	// Cut lists and blanks of kept materials survive rewrite of BOM lines, those of removed ones are dropped
+	if err := r.queries.DeleteStaleProductCuts(ctx, productID); err != nil {
+		return parseError(err)
+	}
+	if err := r.queries.DeleteStaleProductBlanks(ctx, productID); err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, append(removed, r.productMaterialItems(ctx, productID)...)...)
 	return nil
 }
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// GetAllDensities returns densities ordered by name.
+func (r *Repository) GetAllDensities(ctx context.Context) ([]models.Density, error) {
+	rows, err := r.queries.GetAllDensities(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	densities := make([]models.Density, 0, len(rows))
+	for _, row := range rows {
+		densities = append(densities, density(row))
+	}
+	return densities, nil
+}
+
+// InsertDensity creates density of steel grade or material.
+func (r *Repository) InsertDensity(ctx context.Context, d models.Density) (models.Density, error) {
+	row, err := r.queries.InsertDensity(ctx, db.InsertDensityParams{Name: d.Name, Density: d.Density})
+	if err != nil {
+		return models.Density{}, parseError(err)
+	}
+	return density(row), nil
+}
+
+// UpdateDensity changes name and value of density.
+func (r *Repository) UpdateDensity(ctx context.Context, d models.Density) error {
+	err := r.queries.UpdateDensity(ctx, db.UpdateDensityParams{Name: d.Name, Density: d.Density, DensityID: d.ID})
+	if err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+// DeleteDensity deletes density.
+func (r *Repository) DeleteDensity(ctx context.Context, id int64) error {
+	if err := r.queries.DeleteDensity(ctx, id); err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+func density(row db.Density) models.Density {
+	return models.Density{ID: row.DensityID, Name: row.Name, Density: row.Density}
+}
@@ -73,15 +73,20 @@ SELECT
     m.material_id,
     m.unit_id,
     m.description,
+    m.yield_percent,
     ut.unit,
     mn.name AS material_name,
     pm.quantity,
     pm.quantity_text,
     p.product_id,
-    p.name AS product_name
+    p.name AS product_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     materials m
     INNER JOIN unit_types ut ON m.unit_id = ut.unit_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
     INNER JOIN material_names mn ON m.material_id = mn.material_id
     LEFT JOIN product_materials pm ON m.material_id = pm.material_id
     LEFT JOIN products p ON pm.product_id = p.product_id
@@ -93,12 +98,15 @@ type GetAllMaterialsRow struct {
 	MaterialID   int64
 	UnitID       int64
 	Description  sql.NullString
+	YieldPercent float64
 	Unit         string
 	MaterialName string
 	Quantity     interface{}
 	QuantityText sql.NullString
 	ProductID    sql.NullInt64
 	ProductName  sql.NullString
+	CategoryID   sql.NullInt64
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetAllMaterials(ctx context.Context) ([]GetAllMaterialsRow, error) {
@@ -114,12 +122,15 @@ func (q *Queries) GetAllMaterials(ctx context.Context) ([]GetAllMaterialsRow, er
 			&i.MaterialID,
 			&i.UnitID,
 			&i.Description,
+			&i.YieldPercent,
 			&i.Unit,
 			&i.MaterialName,
 			&i.Quantity,
 			&i.QuantityText,
 			&i.ProductID,
 			&i.ProductName,
+			&i.CategoryID,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
@@ -139,15 +150,20 @@ SELECT
     m.material_id,
     m.unit_id,
     m.description,
+    m.yield_percent,
     ut.unit,
     mn.name AS material_name,
     pm.quantity,
     pm.quantity_text,
     p.product_id,
-    p.name AS product_name
+    p.name AS product_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     materials m
     INNER JOIN unit_types ut ON m.unit_id = ut.unit_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
     INNER JOIN material_names mn ON m.material_id = mn.material_id
     and is_primary = TRUE
     LEFT JOIN product_materials pm ON m.material_id = pm.material_id
@@ -160,12 +176,15 @@ type GetAllMaterialsWithPrimaryNamesRow struct {
 	MaterialID   int64
 	UnitID       int64
 	Description  sql.NullString
+	YieldPercent float64
 	Unit         string
 	MaterialName string
 	Quantity     interface{}
 	QuantityText sql.NullString
 	ProductID    sql.NullInt64
 	ProductName  sql.NullString
+	CategoryID   sql.NullInt64
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetAllMaterialsWithPrimaryNames(ctx context.Context) ([]GetAllMaterialsWithPrimaryNamesRow, error) {
@@ -181,12 +200,15 @@ func (q *Queries) GetAllMaterialsWithPrimaryNames(ctx context.Context) ([]GetAll
 			&i.MaterialID,
 			&i.UnitID,
 			&i.Description,
+			&i.YieldPercent,
 			&i.Unit,
 			&i.MaterialName,
 			&i.Quantity,
 			&i.QuantityText,
 			&i.ProductID,
 			&i.ProductName,
+			&i.CategoryID,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
@@ -206,22 +228,30 @@ SELECT
     materials.material_id,
     materials.unit_id,
     materials.description,
+    materials.yield_percent,
     unit_types.unit AS unit,
-    product_materials.quantity AS quantity
+    product_materials.quantity AS quantity,
+    material_categories.category_id,
+    categories.name AS category_name
 FROM
     materials
     INNER JOIN unit_types ON materials.unit_id = unit_types.unit_id
+    LEFT JOIN material_categories ON material_categories.material_id = materials.material_id
+    LEFT JOIN categories ON categories.category_id = material_categories.category_id
     LEFT JOIN product_materials ON product_materials.material_id = materials.material_id
 WHERE
     materials.material_id = ?
 `
 
 type GetMaterialByIDRow struct {
-	MaterialID  int64
-	UnitID      int64
-	Description sql.NullString
-	Unit        string
-	Quantity    interface{}
+	MaterialID   int64
+	UnitID       int64
+	Description  sql.NullString
+	YieldPercent float64
+	Unit         string
+	Quantity     interface{}
+	CategoryID   sql.NullInt64
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetMaterialByID(ctx context.Context, materialID int64) (GetMaterialByIDRow, error) {
@@ -231,15 +261,18 @@ func (q *Queries) GetMaterialByID(ctx context.Context, materialID int64) (GetMat
 		&i.MaterialID,
 		&i.UnitID,
 		&i.Description,
+		&i.YieldPercent,
 		&i.Unit,
 		&i.Quantity,
+		&i.CategoryID,
+		&i.CategoryName,
 	)
 	return i, err
 }
 
 const getMaterialByName = `-- name: GetMaterialByName :one
 select
-    m.material_id, m.unit_id, m.description,
+    m.material_id, m.unit_id, m.description, m.yield_percent,
     pm.quantity AS quantity,
     pm.quantity_text AS quantity_text,
     mn.name AS material_name,
@@ -257,6 +290,7 @@ type GetMaterialByNameRow struct {
 	MaterialID   int64
 	UnitID       int64
 	Description  sql.NullString
+	YieldPercent float64
 	Quantity     interface{}
 	QuantityText sql.NullString
 	MaterialName string
@@ -270,6 +304,7 @@ func (q *Queries) GetMaterialByName(ctx context.Context, name string) (GetMateri
 		&i.MaterialID,
 		&i.UnitID,
 		&i.Description,
+		&i.YieldPercent,
 		&i.Quantity,
 		&i.QuantityText,
 		&i.MaterialName,
@@ -278,6 +313,45 @@ func (q *Queries) GetMaterialByName(ctx context.Context, name string) (GetMateri
 	return i, err
 }
 
+const getMaterialLineScraps = `-- name: GetMaterialLineScraps :many
+SELECT
+    product_id,
+    scrap_percent
+FROM
+    product_materials
+WHERE
+    material_id = ?
+    AND scrap_percent IS NOT NULL
+`
+
+type GetMaterialLineScrapsRow struct {
+	ProductID    sql.NullInt64
+	ScrapPercent sql.NullFloat64
+}
+
+func (q *Queries) GetMaterialLineScraps(ctx context.Context, materialID sql.NullInt64) ([]GetMaterialLineScrapsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMaterialLineScraps, materialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMaterialLineScrapsRow
+	for rows.Next() {
+		var i GetMaterialLineScrapsRow
+		if err := rows.Scan(&i.ProductID, &i.ScrapPercent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMaterialNames = `-- name: GetMaterialNames :many
 select
     name_id, material_id, name, is_primary
@@ -385,7 +459,7 @@ VALUES
                 unit_types.unit = ?
         ),
         ?
-    ) RETURNING material_id, unit_id, description
+    ) RETURNING material_id, unit_id, description, yield_percent
 `
 
 type InsertMaterialParams struct {
@@ -396,7 +470,12 @@ type InsertMaterialParams struct {
 func (q *Queries) InsertMaterial(ctx context.Context, arg InsertMaterialParams) (Material, error) {
 	row := q.db.QueryRowContext(ctx, insertMaterial, arg.Unit, arg.Description)
 	var i Material
-	err := row.Scan(&i.MaterialID, &i.UnitID, &i.Description)
+	err := row.Scan(
+		&i.MaterialID,
+		&i.UnitID,
+		&i.Description,
+		&i.YieldPercent,
+	)
 	return i, err
 }
 
@@ -438,6 +517,24 @@ func (q *Queries) SetMaterialPrimaryName(ctx context.Context, name string) error
 	return err
 }
 
+const setMaterialYield = `-- name: SetMaterialYield :exec
+UPDATE materials
+SET
+    yield_percent = ?
+WHERE
+    material_id = ?
+`
+
+type SetMaterialYieldParams struct {
+	YieldPercent float64
+	MaterialID   int64
+}
+
+func (q *Queries) SetMaterialYield(ctx context.Context, arg SetMaterialYieldParams) error {
+	_, err := q.db.ExecContext(ctx, setMaterialYield, arg.YieldPercent, arg.MaterialID)
+	return err
+}
+
 const unsetMaterialPrimaryName = `-- name: UnsetMaterialPrimaryName :exec
 UPDATE material_names
 SET
@@ -457,7 +554,7 @@ UPDATE materials
 SET
     description = ?
 WHERE
-    material_id = ? RETURNING material_id, unit_id, description
+    material_id = ? RETURNING material_id, unit_id, description, yield_percent
 `
 
 type UpdateMaterialDescriptionParams struct {
@@ -468,7 +565,12 @@ type UpdateMaterialDescriptionParams struct {
 func (q *Queries) UpdateMaterialDescription(ctx context.Context, arg UpdateMaterialDescriptionParams) (Material, error) {
 	row := q.db.QueryRowContext(ctx, updateMaterialDescription, arg.Description, arg.MaterialID)
 	var i Material
-	err := row.Scan(&i.MaterialID, &i.UnitID, &i.Description)
+	err := row.Scan(
+		&i.MaterialID,
+		&i.UnitID,
+		&i.Description,
+		&i.YieldPercent,
+	)
 	return i, err
 }
 
@@ -509,7 +611,7 @@ SET
             unit_types.unit = ?
     )
 WHERE
-    material_id = ? RETURNING material_id, unit_id, description
+    material_id = ? RETURNING material_id, unit_id, description, yield_percent
 `
 
 type UpdateMaterialUnitParams struct {
@@ -520,6 +622,11 @@ type UpdateMaterialUnitParams struct {
 func (q *Queries) UpdateMaterialUnit(ctx context.Context, arg UpdateMaterialUnitParams) (Material, error) {
 	row := q.db.QueryRowContext(ctx, updateMaterialUnit, arg.Unit, arg.MaterialID)
 	var i Material
-	err := row.Scan(&i.MaterialID, &i.UnitID, &i.Description)
+	err := row.Scan(
+		&i.MaterialID,
+		&i.UnitID,
+		&i.Description,
+		&i.YieldPercent,
+	)
 	return i, err
 }
@@ -8,6 +8,32 @@ import (
 	"database/sql"
 )
 
+type Attribute struct {
+	AttributeID int64
+	Name        string
+	Kind        string
+	Unit        string
+	Options     string
+	CategoryID  sql.NullInt64
+}
+
+type Category struct {
+	CategoryID int64
+	ParentID   sql.NullInt64
+	Name       string
+}
+
+type Density struct {
+	DensityID int64
+	Name      string
+	Density   float64
+}
+
+type DigestState struct {
+	ID         int64
+	LastSentOn string
+}
+
 type File struct {
 	FileID   int64
 	Name     string
@@ -16,6 +42,11 @@ type File struct {
 	FileType sql.NullString
 }
 
+type FileText struct {
+	FileID int64
+	Text   string
+}
+
 type FilesMaterial struct {
 	MaterialID sql.NullInt64
 	FileID     sql.NullInt64
@@ -30,13 +61,44 @@ type Ft struct {
 	Type  string
 	RefID string
 	Text  string
+	Terms string
 	Score string
 }
 
+type FtsTable struct {
+	Type  string
+	RefID string
+	Text  string
+	Terms string
+}
+
+type FtsVocab struct {
+	Term string
+	Doc  int64
+	Cnt  int64
+}
+
+type Location struct {
+	LocationID int64
+	Name       string
+}
+
 type Material struct {
+	MaterialID   int64
+	UnitID       int64
+	Description  sql.NullString
+	YieldPercent float64
+}
+
+type MaterialAttribute struct {
 	MaterialID  int64
-	UnitID      int64
-	Description sql.NullString
+	AttributeID int64
+	Value       string
+}
+
+type MaterialCategory struct {
+	MaterialID int64
+	CategoryID int64
 }
 
 type MaterialName struct {
@@ -46,17 +108,122 @@ type MaterialName struct {
 	IsPrimary  bool
 }
 
+type MaterialTag struct {
+	MaterialID int64
+	Tag        string
+}
+
 type Product struct {
 	ProductID   int64
 	Name        string
 	Description sql.NullString
 }
 
+type ProductAttribute struct {
+	ProductID   int64
+	AttributeID int64
+	Value       string
+}
+
+type ProductBlank struct {
+	BlankID    int64
+	ProductID  int64
+	MaterialID int64
+	Width      float64
+	Height     float64
+	Quantity   int64
+}
+
+type ProductCut struct {
+	CutID      int64
+	ProductID  int64
+	MaterialID int64
+	Length     float64
+	Quantity   int64
+}
+
 type ProductMaterial struct {
 	ProductID    sql.NullInt64
 	MaterialID   sql.NullInt64
 	Quantity     interface{}
 	QuantityText sql.NullString
+	ScrapPercent sql.NullFloat64
+}
+
+type ProductTag struct {
+	ProductID int64
+	Tag       string
+}
+
+type ProductionOrder struct {
+	OrderID     int64
+	ProductID   sql.NullInt64
+	ProductName string
+	Quantity    int64
+	LocationID  int64
+	CreatedOn   string
+	CompletedOn sql.NullString
+	Comment     string
+}
+
+type ProductionOrderLine struct {
+	LineID       int64
+	OrderID      int64
+	MaterialID   sql.NullInt64
+	MaterialName string
+	Unit         string
+	BomQuantity  string
+	Norm         sql.NullFloat64
+	Actual       sql.NullFloat64
+}
+
+type SavedView struct {
+	ViewID int64
+	Kind   string
+	Name   string
+	Query  string
+}
+
+type Scenario struct {
+	ScenarioID  int64
+	Name        string
+	Query       string
+	BomRevision string
+	Result      string
+	SavedAt     string
+	Planned     bool
+}
+
+type SearchSetting struct {
+	ID          int64
+	AppIndexing bool
+}
+
+type SearchSource struct {
+	Type     string
+	RefID    int64
+	Text     sql.NullString
+	FullText sql.NullString
+}
+
+type StockLevel struct {
+	MaterialID      int64
+	Minimum         float64
+	ReorderPoint    float64
+	ReorderQuantity float64
+}
+
+type StockMovement struct {
+	MovementID     int64
+	MaterialID     int64
+	Unit           string
+	Kind           string
+	Quantity       float64
+	FromLocationID sql.NullInt64
+	ToLocationID   sql.NullInt64
+	MovedOn        string
+	Comment        string
+	OrderID        sql.NullInt64
 }
 
 type UnitType struct {
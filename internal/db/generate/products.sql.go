@@ -12,9 +12,9 @@ import (
 
 const addProductMaterial = `-- name: AddProductMaterial :exec
 insert into
-    product_materials (product_id, material_id, quantity, quantity_text)
+    product_materials (product_id, material_id, quantity, quantity_text, scrap_percent)
 values
-    (?, ?, ?, ?)
+    (?, ?, ?, ?, ?)
 `
 
 type AddProductMaterialParams struct {
@@ -22,6 +22,7 @@ type AddProductMaterialParams struct {
 	MaterialID   sql.NullInt64
 	Quantity     interface{}
 	QuantityText sql.NullString
+	ScrapPercent sql.NullFloat64
 }
 
 func (q *Queries) AddProductMaterial(ctx context.Context, arg AddProductMaterialParams) error {
@@ -30,6 +31,7 @@ func (q *Queries) AddProductMaterial(ctx context.Context, arg AddProductMaterial
 		arg.MaterialID,
 		arg.Quantity,
 		arg.QuantityText,
+		arg.ScrapPercent,
 	)
 	return err
 }
@@ -174,16 +176,21 @@ func (q *Queries) GetProductByName(ctx context.Context, name string) (Product, e
 
 const getProductMaterials = `-- name: GetProductMaterials :many
 SELECT
-    m.material_id, m.unit_id, m.description,
+    m.material_id, m.unit_id, m.description, m.yield_percent,
     ut.unit AS unit,
     pm.quantity AS quantity,
     pm.quantity_text AS quantity_text,
-    mn.name AS material_name
+    pm.scrap_percent AS scrap_percent,
+    mn.name AS material_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     product_materials pm
     INNER JOIN materials m ON m.material_id = pm.material_id
     INNER JOIN unit_types ut ON ut.unit_id = m.unit_id
     INNER JOIN material_names mn ON mn.material_id = m.material_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
 WHERE
     pm.product_id = ?
     and mn.is_primary = TRUE
@@ -193,10 +200,14 @@ type GetProductMaterialsRow struct {
 	MaterialID   int64
 	UnitID       int64
 	Description  sql.NullString
+	YieldPercent float64
 	Unit         string
 	Quantity     interface{}
 	QuantityText sql.NullString
+	ScrapPercent sql.NullFloat64
 	MaterialName string
+	CategoryID   sql.NullInt64
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetProductMaterials(ctx context.Context, productID sql.NullInt64) ([]GetProductMaterialsRow, error) {
@@ -212,10 +223,14 @@ func (q *Queries) GetProductMaterials(ctx context.Context, productID sql.NullInt
 			&i.MaterialID,
 			&i.UnitID,
 			&i.Description,
+			&i.YieldPercent,
 			&i.Unit,
 			&i.Quantity,
 			&i.QuantityText,
+			&i.ScrapPercent,
 			&i.MaterialName,
+			&i.CategoryID,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
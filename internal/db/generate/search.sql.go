@@ -10,62 +10,452 @@ import (
 	"database/sql"
 )
 
-const searchAll = `-- name: SearchAll :many
-SELECT
-    f.type,
-    f.ref_id,
-    COALESCE(
-        CASE
-            WHEN f.type = 'product' THEN p.name
-            WHEN f.type = 'material' THEN mn.name
-        END,
-        ''
-    ) AS display_name,
-    f.text,
-    f.score
-FROM
-    fts f
-    LEFT JOIN products p ON f.type = 'product'
-    AND f.ref_id = p.product_id
-    LEFT JOIN material_names mn ON f.type = 'material'
-    AND f.ref_id = mn.material_id
-    AND mn.is_primary = 1
+const clearSearchIndex = `-- name: ClearSearchIndex :exec
+DELETE FROM fts_table
+`
+
+func (q *Queries) ClearSearchIndex(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, clearSearchIndex)
+	return err
+}
+
+const deleteSearchItem = `-- name: DeleteSearchItem :exec
+DELETE FROM fts_table
+WHERE
+    type = ?1
+    AND ref_id = CAST(?2 AS INTEGER)
+`
+
+type DeleteSearchItemParams struct {
+	Type  string
+	RefID int64
+}
+
+func (q *Queries) DeleteSearchItem(ctx context.Context, arg DeleteSearchItemParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSearchItem, arg.Type, arg.RefID)
+	return err
+}
+
+const getAppIndexing = `-- name: GetAppIndexing :one
+SELECT
+    app_indexing
+FROM
+    search_settings
+WHERE
+    id = 1
+`
+
+func (q *Queries) GetAppIndexing(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, getAppIndexing)
+	var app_indexing bool
+	err := row.Scan(&app_indexing)
+	return app_indexing, err
+}
+
+const getFileOwners = `-- name: GetFileOwners :many
+SELECT
+    'material' AS type,
+    fm.material_id AS ref_id
+FROM
+    files_materials fm
+WHERE
+    fm.file_id = ?1
+UNION ALL
+SELECT
+    'product' AS type,
+    fp.product_id AS ref_id
+FROM
+    files_products fp
+WHERE
+    fp.file_id = ?1
+`
+
+type GetFileOwnersRow struct {
+	Type  string
+	RefID sql.NullInt64
+}
+
+func (q *Queries) GetFileOwners(ctx context.Context, fileID sql.NullInt64) ([]GetFileOwnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFileOwners, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFileOwnersRow
+	for rows.Next() {
+		var i GetFileOwnersRow
+		if err := rows.Scan(&i.Type, &i.RefID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilesWithoutText = `-- name: GetFilesWithoutText :many
+SELECT
+    f.file_id,
+    f.path
+FROM
+    files f
+    LEFT JOIN file_texts ft ON ft.file_id = f.file_id
+WHERE
+    ft.file_id IS NULL
+    AND f.file_type = 'document'
+`
+
+type GetFilesWithoutTextRow struct {
+	FileID int64
+	Path   string
+}
+
+func (q *Queries) GetFilesWithoutText(ctx context.Context) ([]GetFilesWithoutTextRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFilesWithoutText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFilesWithoutTextRow
+	for rows.Next() {
+		var i GetFilesWithoutTextRow
+		if err := rows.Scan(&i.FileID, &i.Path); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProductMaterialIDs = `-- name: GetProductMaterialIDs :many
+SELECT
+    material_id
+FROM
+    product_materials
+WHERE
+    product_id = ?
+`
+
+func (q *Queries) GetProductMaterialIDs(ctx context.Context, productID sql.NullInt64) ([]sql.NullInt64, error) {
+	rows, err := q.db.QueryContext(ctx, getProductMaterialIDs, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullInt64
+	for rows.Next() {
+		var material_id sql.NullInt64
+		if err := rows.Scan(&material_id); err != nil {
+			return nil, err
+		}
+		items = append(items, material_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSearchFacets = `-- name: GetSearchFacets :many
+WITH RECURSIVE
+    -- Selected categories with all their subcategories, ID 0 selects materials without category.
+    selected_categories (category_id) AS (
+        SELECT
+            category_id
+        FROM
+            categories
+        WHERE
+            instr (?1, ',' || category_id || ',') > 0
+        UNION
+        SELECT
+            c.category_id
+        FROM
+            categories c
+            INNER JOIN selected_categories s ON c.parent_id = s.category_id
+    ),
+    hits AS (
+        SELECT
+            CASE
+                    WHEN t.type <> 'file' THEN t.type
+                    WHEN EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            files_materials x
+                        WHERE
+                            x.file_id = t.ref_id
+                    ) THEN 'material'
+                    ELSE 'product'
+                END AS type,
+                CASE
+                    WHEN t.type <> 'file' THEN t.ref_id
+                    ELSE COALESCE(
+                        (
+                            SELECT
+                                x.material_id
+                            FROM
+                                files_materials x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        ),
+                        (
+                            SELECT
+                                x.product_id
+                            FROM
+                                files_products x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        )
+                    )
+                END AS ref_id
+        FROM
+            fts_table t
+        WHERE
+            t.terms MATCH ?2
+    ),
+    -- Item found by own text and by attached files is counted once.
+    found AS (
+        SELECT DISTINCT
+            f.type,
+            f.ref_id
+        FROM
+            hits f
+        WHERE
+            f.ref_id IS NOT NULL
+            AND (
+                CAST(?3 AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            materials fm
+                        WHERE
+                            fm.material_id = f.ref_id
+                            AND instr (?3, ',' || fm.unit_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(?4 AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            product_materials fpm
+                        WHERE
+                            fpm.material_id = f.ref_id
+                            AND instr (?4, ',' || fpm.product_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(?5 AS TEXT) = ''
+                OR (
+                    f.type = 'product'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            product_materials fpm
+                        WHERE
+                            fpm.product_id = f.ref_id
+                            AND instr (?5, ',' || fpm.material_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(?1 AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND (
+                        EXISTS (
+                            SELECT
+                                1
+                            FROM
+                                material_categories fmc
+                                INNER JOIN selected_categories sc ON sc.category_id = fmc.category_id
+                            WHERE
+                                fmc.material_id = f.ref_id
+                        )
+                        OR (
+                            instr (?1, ',0,') > 0
+                            AND NOT EXISTS (
+                                SELECT
+                                    1
+                                FROM
+                                    material_categories fmc
+                                WHERE
+                                    fmc.material_id = f.ref_id
+                            )
+                        )
+                    )
+                )
+            )
+            AND (
+                CAST(?6 AS BOOLEAN) = FALSE
+                OR EXISTS (
+                    SELECT
+                        1
+                    FROM
+                        files_materials ffm
+                    WHERE
+                        f.type = 'material'
+                        AND ffm.material_id = f.ref_id
+                    UNION ALL
+                    SELECT
+                        1
+                    FROM
+                        files_products ffp
+                    WHERE
+                        f.type = 'product'
+                        AND ffp.product_id = f.ref_id
+                )
+            )
+    )
+SELECT
+    'type' AS facet,
+    CAST(0 AS INTEGER) AS id,
+    CAST(found.type AS TEXT) AS name,
+    COUNT(*) AS count
+FROM
+    found
+GROUP BY
+    found.type
+UNION ALL
+SELECT
+    'unit' AS facet,
+    u.unit_id AS id,
+    u.unit AS name,
+    COUNT(*) AS count
+FROM
+    found
+    INNER JOIN materials m ON found.type = 'material'
+    AND m.material_id = found.ref_id
+    INNER JOIN unit_types u ON u.unit_id = m.unit_id
+GROUP BY
+    u.unit_id
+UNION ALL
+SELECT
+    'product' AS facet,
+    p.product_id AS id,
+    p.name AS name,
+    COUNT(DISTINCT found.ref_id) AS count
+FROM
+    found
+    INNER JOIN product_materials pm ON found.type = 'material'
+    AND pm.material_id = found.ref_id
+    INNER JOIN products p ON p.product_id = pm.product_id
+GROUP BY
+    p.product_id
+UNION ALL
+SELECT
+    'category' AS facet,
+    CAST(COALESCE(mc.category_id, 0) AS INTEGER) AS id,
+    CAST(COALESCE(c.name, '') AS TEXT) AS name,
+    COUNT(*) AS count
+FROM
+    found
+    LEFT JOIN material_categories mc ON mc.material_id = found.ref_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
 WHERE
-    f.text MATCH ?
+    found.type = 'material'
+GROUP BY
+    COALESCE(mc.category_id, 0)
+UNION ALL
+SELECT
+    'files' AS facet,
+    CAST(0 AS INTEGER) AS id,
+    '' AS name,
+    COUNT(*) AS count
+FROM
+    found
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            files_materials fm
+        WHERE
+            found.type = 'material'
+            AND fm.material_id = found.ref_id
+        UNION ALL
+        SELECT
+            1
+        FROM
+            files_products fp
+        WHERE
+            found.type = 'product'
+            AND fp.product_id = found.ref_id
+    )
 ORDER BY
-    f.score ASC
-LIMIT
-    ?
+    facet,
+    count DESC,
+    name
 `
 
-type SearchAllParams struct {
-	Text  string
-	Limit int64
+type GetSearchFacetsParams struct {
+	CategoryIds string
+	Terms       string
+	UnitIds     string
+	ProductIds  string
+	MaterialIds string
+	HasFiles    bool
 }
 
-type SearchAllRow struct {
-	Type        string
-	RefID       string
-	DisplayName interface{}
-	Text        string
-	Score       string
+type GetSearchFacetsRow struct {
+	Facet string
+	ID    int64
+	Name  string
+	Count int64
 }
 
-func (q *Queries) SearchAll(ctx context.Context, arg SearchAllParams) ([]SearchAllRow, error) {
-	rows, err := q.db.QueryContext(ctx, searchAll, arg.Text, arg.Limit)
+// Counts of found items by type, by unit, by product using found materials, by category
+// of found materials and count of found items with attached files. Type filter is not applied,
+// so counts of all types are known on every tab of search page.
+// Category counts are by category assigned directly, tree totals are summed by the app.
+func (q *Queries) GetSearchFacets(ctx context.Context, arg GetSearchFacetsParams) ([]GetSearchFacetsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSearchFacets,
+		arg.CategoryIds,
+		arg.Terms,
+		arg.UnitIds,
+		arg.ProductIds,
+		arg.MaterialIds,
+		arg.HasFiles,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SearchAllRow
+	var items []GetSearchFacetsRow
 	for rows.Next() {
-		var i SearchAllRow
+		var i GetSearchFacetsRow
 		if err := rows.Scan(
-			&i.Type,
-			&i.RefID,
-			&i.DisplayName,
-			&i.Text,
-			&i.Score,
+			&i.Facet,
+			&i.ID,
+			&i.Name,
+			&i.Count,
 		); err != nil {
 			return nil, err
 		}
@@ -80,63 +470,68 @@ func (q *Queries) SearchAll(ctx context.Context, arg SearchAllParams) ([]SearchA
 	return items, nil
 }
 
-const searchMaterials = `-- name: SearchMaterials :many
-SELECT
-    f.ref_id,
-    mn.name AS display_name,
-    f.text,
-    u.unit,
-    u.unit_id,
-    COALESCE(pm.quantity, pm.quantity_text) AS quantity,
-    score
-FROM fts f
-LEFT JOIN materials m
-    ON f.ref_id = m.material_id
-LEFT JOIN material_names mn
-    ON f.ref_id = mn.material_id AND mn.is_primary = 1
-LEFT JOIN product_materials pm
-    ON m.material_id = pm.material_id
-INNER JOIN unit_types u
-    ON m.unit_id = u.unit_id
-WHERE
-    f.text MATCH ?1
-    AND f.type = 'material'
-ORDER BY score ASC
-LIMIT ?2
+const getSearchIndex = `-- name: GetSearchIndex :many
+SELECT
+    type,
+    ref_id,
+    text,
+    terms
+FROM
+    fts_table
 `
 
-type SearchMaterialsParams struct {
-	Query string
-	Limit int64
+func (q *Queries) GetSearchIndex(ctx context.Context) ([]FtsTable, error) {
+	rows, err := q.db.QueryContext(ctx, getSearchIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FtsTable
+	for rows.Next() {
+		var i FtsTable
+		if err := rows.Scan(
+			&i.Type,
+			&i.RefID,
+			&i.Text,
+			&i.Terms,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-type SearchMaterialsRow struct {
-	RefID       string
-	DisplayName sql.NullString
-	Text        string
-	Unit        string
-	UnitID      int64
-	Quantity    interface{}
-	Score       string
-}
+const getSearchSource = `-- name: GetSearchSource :many
+SELECT
+    type,
+    ref_id,
+    text,
+    full_text
+FROM
+    search_source
+`
 
-func (q *Queries) SearchMaterials(ctx context.Context, arg SearchMaterialsParams) ([]SearchMaterialsRow, error) {
-	rows, err := q.db.QueryContext(ctx, searchMaterials, arg.Query, arg.Limit)
+func (q *Queries) GetSearchSource(ctx context.Context) ([]SearchSource, error) {
+	rows, err := q.db.QueryContext(ctx, getSearchSource)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SearchMaterialsRow
+	var items []SearchSource
 	for rows.Next() {
-		var i SearchMaterialsRow
+		var i SearchSource
 		if err := rows.Scan(
+			&i.Type,
 			&i.RefID,
-			&i.DisplayName,
 			&i.Text,
-			&i.Unit,
-			&i.UnitID,
-			&i.Quantity,
-			&i.Score,
+			&i.FullText,
 		); err != nil {
 			return nil, err
 		}
@@ -151,50 +546,404 @@ func (q *Queries) SearchMaterials(ctx context.Context, arg SearchMaterialsParams
 	return items, nil
 }
 
-const searchProducts = `-- name: SearchProducts :many
+const getSearchVocabulary = `-- name: GetSearchVocabulary :many
 SELECT
-    f.ref_id,
-    p.name AS display_name,
-    f.text,
-    f.score
+    term
 FROM
-    fts f
-    LEFT JOIN products p ON f.ref_id = p.product_id
+    fts_vocab
+`
+
+func (q *Queries) GetSearchVocabulary(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getSearchVocabulary)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, err
+		}
+		items = append(items, term)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const indexAll = `-- name: IndexAll :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    text,
+    search_terms (text)
+FROM
+    search_source
+`
+
+func (q *Queries) IndexAll(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, indexAll)
+	return err
+}
+
+const indexAllFull = `-- name: IndexAllFull :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    full_text,
+    search_terms (full_text)
+FROM
+    search_source
+`
+
+func (q *Queries) IndexAllFull(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, indexAllFull)
+	return err
+}
+
+const indexSearchItemFull = `-- name: IndexSearchItemFull :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    s.type,
+    s.ref_id,
+    s.full_text,
+    search_terms (s.full_text)
+FROM
+    search_source s
 WHERE
-    f.text MATCH ?
-    AND f.type = 'product'
+    s.type = ?1
+    AND s.ref_id = ?2
+`
+
+type IndexSearchItemFullParams struct {
+	Type  string
+	RefID int64
+}
+
+func (q *Queries) IndexSearchItemFull(ctx context.Context, arg IndexSearchItemFullParams) error {
+	_, err := q.db.ExecContext(ctx, indexSearchItemFull, arg.Type, arg.RefID)
+	return err
+}
+
+const saveFileText = `-- name: SaveFileText :exec
+INSERT INTO
+    file_texts (file_id, text)
+VALUES
+    (?, ?)
+ON CONFLICT (file_id) DO UPDATE
+SET
+    text = excluded.text
+`
+
+type SaveFileTextParams struct {
+	FileID int64
+	Text   string
+}
+
+func (q *Queries) SaveFileText(ctx context.Context, arg SaveFileTextParams) error {
+	_, err := q.db.ExecContext(ctx, saveFileText, arg.FileID, arg.Text)
+	return err
+}
+
+const search = `-- name: Search :many
+WITH RECURSIVE
+    -- Selected categories with all their subcategories, ID 0 selects materials without category.
+    selected_categories (category_id) AS (
+        SELECT
+            category_id
+        FROM
+            categories
+        WHERE
+            instr (?5, ',' || category_id || ',') > 0
+        UNION
+        SELECT
+            c.category_id
+        FROM
+            categories c
+            INNER JOIN selected_categories s ON c.parent_id = s.category_id
+    ),
+    hits AS (
+        SELECT
+            CASE
+                    WHEN t.type <> 'file' THEN t.type
+                    WHEN EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            files_materials x
+                        WHERE
+                            x.file_id = t.ref_id
+                    ) THEN 'material'
+                    ELSE 'product'
+                END AS type,
+                CASE
+                    WHEN t.type <> 'file' THEN t.ref_id
+                    ELSE COALESCE(
+                        (
+                            SELECT
+                                x.material_id
+                            FROM
+                                files_materials x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        ),
+                        (
+                            SELECT
+                                x.product_id
+                            FROM
+                                files_products x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        )
+                    )
+                END AS ref_id,
+                CASE
+                    WHEN t.type = 'file' THEN t.ref_id
+                    ELSE 0
+                END AS file_id,
+                t.text,
+                highlight (fts_table, 3, char(2), char(3)) AS matched_terms,
+                bm25 (fts_table) AS score
+        FROM
+            fts_table t
+        WHERE
+            t.terms MATCH ?9
+    ),
+    ranked AS (
+        SELECT
+            h.type,
+            h.ref_id,
+            h.file_id,
+            h.text,
+            h.matched_terms,
+            h.score,
+            ROW_NUMBER() OVER (
+                PARTITION BY
+                    h.type,
+                    h.ref_id
+                ORDER BY
+                    h.score,
+                    h.file_id
+            ) AS hit_rank
+        FROM
+            hits h
+    )
+SELECT
+    CAST(f.type AS TEXT) AS type,
+    CAST(f.ref_id AS INTEGER) AS ref_id,
+    CAST(COALESCE(p.name, mn.name, '') AS TEXT) AS display_name,
+    CAST(COALESCE(p.description, m.description, '') AS TEXT) AS description,
+    CAST(
+        COALESCE(
+            (
+                SELECT
+                    group_concat (o.name, ', ')
+                FROM
+                    material_names o
+                WHERE
+                    o.material_id = m.material_id
+                    AND o.is_primary = 0
+            ),
+            ''
+        ) AS TEXT
+    ) AS other_names,
+    CAST(f.file_id AS INTEGER) AS file_id,
+    CAST(COALESCE(fl.name, '') AS TEXT) AS file_name,
+    CAST(f.text AS TEXT) AS text,
+    CAST(f.matched_terms AS TEXT) AS matched_terms
+FROM
+    ranked f
+    LEFT JOIN products p ON f.type = 'product'
+    AND f.ref_id = p.product_id
+    LEFT JOIN materials m ON f.type = 'material'
+    AND f.ref_id = m.material_id
+    LEFT JOIN material_names mn ON f.type = 'material'
+    AND f.ref_id = mn.material_id
+    AND mn.is_primary = 1
+    LEFT JOIN files fl ON fl.file_id = f.file_id
+WHERE
+    -- File without owner is not shown.
+    f.ref_id IS NOT NULL
+    AND f.hit_rank = 1
+    AND (
+        CAST(?1 AS TEXT) = ''
+        OR f.type = ?1
+    )
+    AND (
+        CAST(?2 AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    materials fm
+                WHERE
+                    fm.material_id = f.ref_id
+                    AND instr (?2, ',' || fm.unit_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(?3 AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    product_materials fpm
+                WHERE
+                    fpm.material_id = f.ref_id
+                    AND instr (?3, ',' || fpm.product_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(?4 AS TEXT) = ''
+        OR (
+            f.type = 'product'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    product_materials fpm
+                WHERE
+                    fpm.product_id = f.ref_id
+                    AND instr (?4, ',' || fpm.material_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(?5 AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND (
+                EXISTS (
+                    SELECT
+                        1
+                    FROM
+                        material_categories fmc
+                        INNER JOIN selected_categories sc ON sc.category_id = fmc.category_id
+                    WHERE
+                        fmc.material_id = f.ref_id
+                )
+                OR (
+                    instr (?5, ',0,') > 0
+                    AND NOT EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            material_categories fmc
+                        WHERE
+                            fmc.material_id = f.ref_id
+                    )
+                )
+            )
+        )
+    )
+    AND (
+        CAST(?6 AS BOOLEAN) = FALSE
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                files_materials ffm
+            WHERE
+                f.type = 'material'
+                AND ffm.material_id = f.ref_id
+            UNION ALL
+            SELECT
+                1
+            FROM
+                files_products ffp
+            WHERE
+                f.type = 'product'
+                AND ffp.product_id = f.ref_id
+        )
+    )
 ORDER BY
     f.score ASC
 LIMIT
-    ?
+    ?8
+OFFSET
+    ?7
 `
 
-type SearchProductsParams struct {
-	Text  string
-	Limit int64
+type SearchParams struct {
+	Type        string
+	UnitIds     string
+	ProductIds  string
+	MaterialIds string
+	CategoryIds string
+	HasFiles    bool
+	Offset      int64
+	Limit       int64
+	Terms       string
 }
 
-type SearchProductsRow struct {
-	RefID       string
-	DisplayName sql.NullString
-	Text        string
-	Score       string
+type SearchRow struct {
+	Type         string
+	RefID        int64
+	DisplayName  string
+	Description  string
+	OtherNames   string
+	FileID       int64
+	FileName     string
+	Text         string
+	MatchedTerms string
 }
 
-func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error) {
-	rows, err := q.db.QueryContext(ctx, searchProducts, arg.Text, arg.Limit)
+// Entries of attached files are returned as hits of their owners, file_id is 0 for other hits.
+// Every item is returned once by its best hit, own text wins over file with the same score.
+// matched_terms is output of highlight() over terms column with terms matched by query,
+// see package internal/search for markers.
+// ID filters are lists like ",1,2,", empty string disables filter. Unit, product and category filters
+// leave only materials, material filter leaves only products that use the materials.
+// Filters must be the same as in GetSearchFacets.
+func (q *Queries) Search(ctx context.Context, arg SearchParams) ([]SearchRow, error) {
+	rows, err := q.db.QueryContext(ctx, search,
+		arg.Type,
+		arg.UnitIds,
+		arg.ProductIds,
+		arg.MaterialIds,
+		arg.CategoryIds,
+		arg.HasFiles,
+		arg.Offset,
+		arg.Limit,
+		arg.Terms,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SearchProductsRow
+	var items []SearchRow
 	for rows.Next() {
-		var i SearchProductsRow
+		var i SearchRow
 		if err := rows.Scan(
+			&i.Type,
 			&i.RefID,
 			&i.DisplayName,
+			&i.Description,
+			&i.OtherNames,
+			&i.FileID,
+			&i.FileName,
 			&i.Text,
-			&i.Score,
+			&i.MatchedTerms,
 		); err != nil {
 			return nil, err
 		}
@@ -208,3 +957,16 @@ func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams)
 	}
 	return items, nil
 }
+
+const setAppIndexing = `-- name: SetAppIndexing :exec
+UPDATE search_settings
+SET
+    app_indexing = ?
+WHERE
+    id = 1
+`
+
+func (q *Queries) SetAppIndexing(ctx context.Context, appIndexing bool) error {
+	_, err := q.db.ExecContext(ctx, setAppIndexing, appIndexing)
+	return err
+}
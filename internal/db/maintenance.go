@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/s-588/BOMViewer/cmd/config"
+)
+
+var (
+	ErrBackupExists  = errors.New("файл резервной копии уже существует")
+	ErrInvalidBackup = errors.New("файл не является резервной копией базы данных")
+)
+
+// backupTables are tables every backup has, goose version table is checked too.
+var backupTables = []string{"unit_types", "materials", "material_names", "products", "product_materials", "files"}
+
+// DBPath returns path of database file described by config.
+func DBPath(cfg *config.Config) string {
+	return filepath.Join(cfg.BaseDirectory, cfg.DBCfg.DBName)
+}
+
+// Migrate runs goose command on database from config without creating a repository,
+// because NewRepository always migrates database to the latest version.
+// Supported commands are "up", "down" and "status".
+func Migrate(ctx context.Context, cfg *config.Config, command string) error {
+	switch command {
+	case "up", "down", "status":
+	default:
+		return fmt.Errorf("unknown migration command %q", command)
+	}
+
+	conn, err := sql.Open("sqlite", dsn(DBPath(cfg), false))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := setupGoose(); err != nil {
+		return err
+	}
+	return goose.RunContext(ctx, command, conn, migrationsDir)
+}
+
+// Backup writes consistent copy of database to path.
+// It's safe to call while server is running, SQLite makes the copy in one read transaction.
+func (r *Repository) Backup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return ErrBackupExists
+	}
+	if _, err := r.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("can't create backup: %w", err)
+	}
+	slog.Info("database backup created", "path", path)
+	return nil
+}
+
+// Restore replaces database file from config with backup from path.
+// Backup is checked before copying, and the server must be stopped while restore is running.
+func Restore(ctx context.Context, cfg *config.Config, path string) error {
+	// SQLite creates missing file on open, so mistyped path would restore empty database.
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("can't open backup: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s", ErrInvalidBackup, path)
+	}
+	backup, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	problems, err := checkBackup(ctx, backup)
+	backup.Close()
+	if err != nil {
+		return fmt.Errorf("can't check backup: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("backup is damaged: %v", problems)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dbPath := DBPath(cfg)
+	tmpPath := dbPath + ".restore"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("can't copy backup: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Journal files of old database must not be applied to restored one.
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return err
+	}
+	slog.Info("database restored from backup", "backup", path, "database", dbPath)
+	return nil
+}
+
+// checkBackup checks that database is the app database and it isn't damaged.
+// Any file SQLite can read, even empty one, passes integrity check alone.
+func checkBackup(ctx context.Context, conn *sql.DB) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		if strings.Contains(err.Error(), "not a database") {
+			return nil, ErrInvalidBackup
+		}
+		return nil, err
+	}
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, table := range append([]string{goose.TableName()}, backupTables...) {
+		if !tables[table] {
+			return nil, fmt.Errorf("%w: нет таблицы %s", ErrInvalidBackup, table)
+		}
+	}
+	return checkIntegrity(ctx, conn)
+}
+
+// Check runs SQLite integrity and foreign key checks, compares search index with source tables
+// and returns found problems. Empty result means that database is healthy.
+func (r *Repository) Check(ctx context.Context) ([]string, error) {
+	problems, err := checkIntegrity(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	searchProblems, err := r.CheckSearchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(problems, searchProblems...), nil
+}
+
+func checkIntegrity(ctx context.Context, conn *sql.DB) ([]string, error) {
+	var problems []string
+
+	rows, err := conn.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = conn.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, parent string
+			rowID         sql.NullInt64
+			fkID          int64
+		)
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, err
+		}
+		problems = append(problems, fmt.Sprintf("row %d of %s references missing row in %s", rowID.Int64, table, parent))
+	}
+	return problems, rows.Err()
+}
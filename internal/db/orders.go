@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// ErrOrderCompleted is returned when completed order is completed again or deleted.
+var ErrOrderCompleted = errors.New("заказ уже выполнен")
+
+// GetProductionOrders returns orders without lines, latest first.
+func (r *Repository) GetProductionOrders(ctx context.Context) ([]models.ProductionOrder, error) {
+	rows, err := r.queries.GetProductionOrders(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	orders := make([]models.ProductionOrder, 0, len(rows))
+	for _, row := range rows {
+		orders = append(orders, models.ProductionOrder{
+			ID:          row.OrderID,
+			ProductID:   row.ProductID.Int64,
+			ProductName: row.ProductName,
+			Quantity:    row.Quantity,
+			Location:    models.Location{ID: row.LocationID, Name: row.LocationName},
+			CreatedOn:   row.CreatedOn,
+			CompletedOn: row.CompletedOn.String,
+			Comment:     row.Comment,
+			Overused:    int(row.Overused),
+		})
+	}
+	return orders, nil
+}
+
+// GetProductionOrder returns order with its lines.
+func (r *Repository) GetProductionOrder(ctx context.Context, id int64) (models.ProductionOrder, error) {
+	row, err := r.queries.GetProductionOrder(ctx, id)
+	if err != nil {
+		return models.ProductionOrder{}, parseError(err)
+	}
+	lines, err := r.queries.GetProductionOrderLines(ctx, id)
+	if err != nil {
+		return models.ProductionOrder{}, parseError(err)
+	}
+	order := models.ProductionOrder{
+		ID:          row.OrderID,
+		ProductID:   row.ProductID.Int64,
+		ProductName: row.ProductName,
+		Quantity:    row.Quantity,
+		Location:    models.Location{ID: row.LocationID, Name: row.LocationName},
+		CreatedOn:   row.CreatedOn,
+		CompletedOn: row.CompletedOn.String,
+		Comment:     row.Comment,
+		Lines:       make([]models.ProductionOrderLine, 0, len(lines)),
+	}
+	for _, line := range lines {
+		order.Lines = append(order.Lines, models.ProductionOrderLine{
+			ID:           line.LineID,
+			MaterialID:   line.MaterialID.Int64,
+			MaterialName: line.MaterialName,
+			Unit:         line.Unit,
+			BOMQuantity:  line.BomQuantity,
+			Norm:         nullFloat(line.Norm),
+			Actual:       nullFloat(line.Actual),
+		})
+	}
+	return order, nil
+}
+
+// GetOpenOrderDemand returns norms of open orders summed by material and unit.
+func (r *Repository) GetOpenOrderDemand(ctx context.Context) ([]models.MaterialDemand, error) {
+	rows, err := r.queries.GetOpenOrderDemand(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	demand := make([]models.MaterialDemand, 0, len(rows))
+	for _, row := range rows {
+		demand = append(demand, models.MaterialDemand{
+			MaterialID: row.MaterialID.Int64,
+			Unit:       row.Unit,
+			Quantity:   row.Quantity,
+		})
+	}
+	return demand, nil
+}
+
+// CreateProductionOrder creates open order with its lines and returns ID of order.
+func (r *Repository) CreateProductionOrder(ctx context.Context, order models.ProductionOrder) (int64, error) {
+	var id int64
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		id, err = q.InsertProductionOrder(ctx, db.InsertProductionOrderParams{
+			ProductID:   sql.NullInt64{Int64: order.ProductID, Valid: order.ProductID != 0},
+			ProductName: order.ProductName,
+			Quantity:    order.Quantity,
+			LocationID:  order.Location.ID,
+			CreatedOn:   order.CreatedOn,
+			Comment:     order.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		for _, line := range order.Lines {
+			err := q.InsertProductionOrderLine(ctx, db.InsertProductionOrderLineParams{
+				OrderID:      id,
+				MaterialID:   sql.NullInt64{Int64: line.MaterialID, Valid: line.MaterialID != 0},
+				MaterialName: line.MaterialName,
+				Unit:         line.Unit,
+				BomQuantity:  line.BOMQuantity,
+				Norm:         sqlFloat(line.Norm),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, parseError(err)
+	}
+	return id, nil
+}
+
+// CompleteProductionOrder writes actual consumption of order lines and issues it from location of order
+// in one transaction. Lines without actual consumption aren't issued. ErrInsufficientStock is returned
+// when location has not enough material, ErrOrderCompleted when order is already completed
+// and ErrNotFound when order doesn't exist.
+func (r *Repository) CompleteProductionOrder(ctx context.Context, order models.ProductionOrder, date string) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		n, err := q.CompleteProductionOrder(ctx, db.CompleteProductionOrderParams{
+			CompletedOn: sql.NullString{String: date, Valid: true},
+			OrderID:     order.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return orderNotOpen(ctx, q, order.ID)
+		}
+		var issues []models.StockMovement
+		for _, line := range order.Lines {
+			err := q.SetOrderLineActual(ctx, db.SetOrderLineActualParams{
+				Actual:  sqlFloat(line.Actual),
+				LineID:  line.ID,
+				OrderID: order.ID,
+			})
+			if err != nil {
+				return err
+			}
+			if line.Actual == nil || *line.Actual <= 0 || line.MaterialID == 0 {
+				continue
+			}
+			issues = append(issues, models.StockMovement{
+				MaterialID:   line.MaterialID,
+				MaterialName: line.MaterialName,
+				Unit:         line.Unit,
+				Kind:         models.MovementIssue,
+				Quantity:     *line.Actual,
+				From:         order.Location,
+				Date:         date,
+				Comment:      fmt.Sprintf("Производственный заказ №%d", order.ID),
+				OrderID:      order.ID,
+			})
+		}
+		return addStockMovements(ctx, q, issues)
+	})
+	if errors.Is(err, ErrInsufficientStock) || errors.Is(err, ErrOrderCompleted) || errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return parseError(err)
+}
+
+// DeleteProductionOrder deletes open order, completed order has movements and ErrOrderCompleted is returned.
+// ErrNotFound is returned when order doesn't exist.
+func (r *Repository) DeleteProductionOrder(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteProductionOrder(ctx, id)
+	if err != nil {
+		return parseError(err)
+	}
+	if n == 0 {
+		return orderNotOpen(ctx, r.queries, id)
+	}
+	return nil
+}
+
+// orderNotOpen tells why order wasn't changed by update of open order:
+// ErrNotFound when order doesn't exist and ErrOrderCompleted otherwise.
+func orderNotOpen(ctx context.Context, q *db.Queries, id int64) error {
+	if _, err := q.GetProductionOrder(ctx, id); err != nil {
+		return parseError(err)
+	}
+	return ErrOrderCompleted
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// GetScenarios returns saved scenarios ordered by name.
+func (r *Repository) GetScenarios(ctx context.Context) ([]models.Scenario, error) {
+	rows, err := r.queries.GetScenarios(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	scenarios := make([]models.Scenario, 0, len(rows))
+	for _, row := range rows {
+		scenario, err := scenario(row)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// GetScenario returns scenario by ID.
+func (r *Repository) GetScenario(ctx context.Context, id int64) (models.Scenario, error) {
+	row, err := r.queries.GetScenario(ctx, id)
+	if err != nil {
+		return models.Scenario{}, parseError(err)
+	}
+	return scenario(row)
+}
+
+// SaveScenario stores scenario, scenario with the same name is replaced.
+func (r *Repository) SaveScenario(ctx context.Context, s models.Scenario) (models.Scenario, error) {
+	result, err := json.Marshal(s.Result)
+	if err != nil {
+		return models.Scenario{}, err
+	}
+	row, err := r.queries.SaveScenario(ctx, db.SaveScenarioParams{
+		Name:        s.Name,
+		Query:       s.Query,
+		BomRevision: s.Revision,
+		Result:      string(result),
+	})
+	if err != nil {
+		return models.Scenario{}, parseError(err)
+	}
+	return scenario(row)
+}
+
+// SetScenarioPlanned marks whether scenario is planned demand. ErrNotFound is returned when scenario doesn't exist.
+func (r *Repository) SetScenarioPlanned(ctx context.Context, id int64, planned bool) error {
+	n, err := r.queries.SetScenarioPlanned(ctx, db.SetScenarioPlannedParams{Planned: planned, ScenarioID: id})
+	if err != nil {
+		return parseError(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteScenario deletes scenario by ID.
+func (r *Repository) DeleteScenario(ctx context.Context, id int64) error {
+	if err := r.queries.DeleteScenario(ctx, id); err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+func scenario(row db.Scenario) (models.Scenario, error) {
+	s := models.Scenario{
+		ID:       row.ScenarioID,
+		Name:     row.Name,
+		Query:    row.Query,
+		Revision: row.BomRevision,
+		SavedAt:  row.SavedAt,
+		Planned:  row.Planned,
+	}
+	if err := json.Unmarshal([]byte(row.Result), &s.Result); err != nil {
+		return models.Scenario{}, err
+	}
+	return s, nil
+}
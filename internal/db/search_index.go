@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+	"github.com/s-588/BOMViewer/internal/search"
+
+	"modernc.org/sqlite"
+)
+
+// search_terms SQL function is used by triggers and queries to fill terms column of fts_table.
+// It's registered for every connection of the process, including migrations.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("search_terms", 1,
+		func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch text := args[0].(type) {
+			case string:
+				return search.IndexText(text), nil
+			case []byte:
+				return search.IndexText(string(text)), nil
+			}
+			return "", nil
+		})
+}
+
+// Values of type column of fts_table.
+const (
+	searchMaterial = "material"
+	searchProduct  = "product"
+	// Text of attached document, it's shown as hit of the material or product.
+	searchFile = "file"
+)
+
+// searchItem identifies one entry of search index.
+type searchItem struct {
+	kind string
+	id   int64
+}
+
+// setupSearchIndexing switches index maintenance between triggers and the app according to config.
+// Triggers check the flag in search_settings table, so they are just disabled in app mode.
+// Index is rebuilt when the mode is changed, because texts of two modes are different.
+func (r *Repository) setupSearchIndexing(ctx context.Context) error {
+	current, err := r.queries.GetAppIndexing(ctx)
+	if err != nil {
+		return parseError(err)
+	}
+	if current == r.appIndexing {
+		return nil
+	}
+	slog.Info("search indexing mode changed, rebuilding index", "app_indexing", r.appIndexing)
+	return r.ReindexSearch(ctx)
+}
+
+// ReindexSearch rebuilds full text search index from source tables.
+func (r *Repository) ReindexSearch(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return parseError(err)
+	}
+	defer tx.Rollback()
+	q := r.queries.WithTx(tx)
+
+	if err := q.SetAppIndexing(ctx, r.appIndexing); err != nil {
+		return parseError(err)
+	}
+	if err := q.ClearSearchIndex(ctx); err != nil {
+		return parseError(err)
+	}
+	if r.appIndexing {
+		err = q.IndexAllFull(ctx)
+	} else {
+		err = q.IndexAll(ctx)
+	}
+	if err != nil {
+		return parseError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return parseError(err)
+	}
+	slog.Info("search index rebuilt")
+	return nil
+}
+
+// CheckSearchIndex compares search index with texts built from source tables
+// and returns missing, outdated, duplicated and orphaned entries.
+func (r *Repository) CheckSearchIndex(ctx context.Context) ([]string, error) {
+	sourceRows, err := r.queries.GetSearchSource(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	indexRows, err := r.queries.GetSearchIndex(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	indexed := make(map[string][]db.FtsTable, len(indexRows))
+	for _, row := range indexRows {
+		key := row.Type + " " + row.RefID
+		indexed[key] = append(indexed[key], row)
+	}
+
+	var problems []string
+	for _, row := range sourceRows {
+		key := fmt.Sprintf("%s %d", row.Type, row.RefID)
+		want := row.Text.String
+		if r.appIndexing {
+			want = row.FullText.String
+		}
+		entries := indexed[key]
+		delete(indexed, key)
+		switch {
+		case len(entries) == 0:
+			problems = append(problems, fmt.Sprintf("search index has no entry for %s", key))
+		case len(entries) > 1:
+			problems = append(problems, fmt.Sprintf("search index has %d entries for %s", len(entries), key))
+		case entries[0].Text != want:
+			problems = append(problems, fmt.Sprintf("search index entry for %s is outdated", key))
+		case entries[0].Terms != search.IndexText(want):
+			// Terms depend on normalization rules of the app, so they are outdated after its update.
+			problems = append(problems, fmt.Sprintf("search terms for %s are outdated", key))
+		}
+	}
+	for key := range indexed {
+		problems = append(problems, fmt.Sprintf("search index has entry for deleted %s", key))
+	}
+	return problems, nil
+}
+
+// updateSearchIndex rebuilds entries of items when index is maintained by the app.
+// Entries of deleted items are removed. Write that caused update is already done,
+// so error is only logged and index can be fixed by ReindexSearch.
+func (r *Repository) updateSearchIndex(ctx context.Context, items ...searchItem) {
+	if !r.appIndexing || len(items) == 0 {
+		return
+	}
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		for _, item := range items {
+			err := q.DeleteSearchItem(ctx, db.DeleteSearchItemParams{Type: item.kind, RefID: item.id})
+			if err != nil {
+				return err
+			}
+			err = q.IndexSearchItemFull(ctx, db.IndexSearchItemFullParams{Type: item.kind, RefID: item.id})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("can't update search index, run reindex-search command to fix it", "error", err)
+	}
+}
+
+func (r *Repository) withTx(ctx context.Context, f func(q *db.Queries) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := f(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// productSearchItems returns product and its materials, because material text includes product names.
+func (r *Repository) productSearchItems(ctx context.Context, productID int64) []searchItem {
+	if !r.appIndexing {
+		return nil
+	}
+	items := []searchItem{{searchProduct, productID}}
+	return append(items, r.productMaterialItems(ctx, productID)...)
+}
+
+// productMaterialItems returns materials used in product.
+// It must be called before product or its materials are deleted.
+func (r *Repository) productMaterialItems(ctx context.Context, productID int64) []searchItem {
+	if !r.appIndexing {
+		return nil
+	}
+	ids, err := r.queries.GetProductMaterialIDs(ctx, sql.NullInt64{Int64: productID, Valid: true})
+	if err != nil {
+		slog.Error("can't get materials of product for search index", "product", productID, "error", err)
+		return nil
+	}
+	items := make([]searchItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, searchItem{searchMaterial, id.Int64})
+	}
+	return items
+}
+
+// fileSearchItems returns file with materials and products it's attached to.
+// It must be called before file is deleted.
+func (r *Repository) fileSearchItems(ctx context.Context, fileID int64) []searchItem {
+	if !r.appIndexing {
+		return nil
+	}
+	owners, err := r.queries.GetFileOwners(ctx, sql.NullInt64{Int64: fileID, Valid: true})
+	if err != nil {
+		slog.Error("can't get owners of file for search index", "file", fileID, "error", err)
+		return nil
+	}
+	items := make([]searchItem, 0, len(owners)+1)
+	items = append(items, searchItem{searchFile, fileID})
+	for _, owner := range owners {
+		items = append(items, searchItem{owner.Type, owner.RefID.Int64})
+	}
+	return items
+}
+
+// SaveFileText stores text extracted from attached document and indexes it.
+// Empty text is saved for documents without text, so extraction is not repeated.
+func (r *Repository) SaveFileText(ctx context.Context, fileID int64, text string) error {
+	err := r.queries.SaveFileText(ctx, db.SaveFileTextParams{FileID: fileID, Text: text})
+	if err != nil {
+		return parseError(err)
+	}
+	r.updateSearchIndex(ctx, searchItem{searchFile, fileID})
+	return nil
+}
+
+// GetFilesWithoutText returns documents which text is not extracted yet.
+func (r *Repository) GetFilesWithoutText(ctx context.Context) ([]models.File, error) {
+	rows, err := r.queries.GetFilesWithoutText(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	files := make([]models.File, 0, len(rows))
+	for _, row := range rows {
+		files = append(files, models.File{ID: row.FileID, Path: row.Path})
+	}
+	return files, nil
+}
+
+// fuzzyQuery returns query with indexed terms similar to words of input,
+// or empty string when there are no such terms.
+func (r *Repository) fuzzyQuery(ctx context.Context, input string) string {
+	vocabulary, err := r.queries.GetSearchVocabulary(ctx)
+	if err != nil {
+		slog.Error("can't get search vocabulary", "error", err)
+		return ""
+	}
+	query := search.FuzzyQuery(input, vocabulary)
+	if query != "" {
+		slog.Debug("using fuzzy search", "input", input, "query", query)
+	}
+	return query
+}
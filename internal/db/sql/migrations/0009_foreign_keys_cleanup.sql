@@ -0,0 +1,74 @@
+-- +goose Up
+-- Foreign keys were not enabled before, so ON DELETE CASCADE didn't work and
+-- rows of deleted materials, products and files stayed in link tables.
+-- They must be removed, otherwise foreign key check reports them as broken references.
+-- +goose StatementBegin
+DELETE FROM material_names
+WHERE
+    material_id NOT IN (
+        SELECT
+            material_id
+        FROM
+            materials
+    );
+
+DELETE FROM product_materials
+WHERE
+    product_id NOT IN (
+        SELECT
+            product_id
+        FROM
+            products
+    )
+    OR material_id NOT IN (
+        SELECT
+            material_id
+        FROM
+            materials
+    );
+
+DELETE FROM files_materials
+WHERE
+    material_id NOT IN (
+        SELECT
+            material_id
+        FROM
+            materials
+    )
+    OR file_id NOT IN (
+        SELECT
+            file_id
+        FROM
+            files
+    );
+
+DELETE FROM files_products
+WHERE
+    product_id NOT IN (
+        SELECT
+            product_id
+        FROM
+            products
+    )
+    OR file_id NOT IN (
+        SELECT
+            file_id
+        FROM
+            files
+    );
+
+-- Triggers on material_names add search entries for deleted materials back.
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id NOT IN (
+        SELECT
+            material_id
+        FROM
+            materials
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- Deleted rows can't be restored and they are not needed.
+SELECT 1;
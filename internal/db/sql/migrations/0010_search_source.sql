@@ -0,0 +1,353 @@
+-- +goose Up
+-- Indexed text is described once by search_source view and used by triggers,
+-- index rebuild and consistency check, so they can't drift from each other.
+-- text is indexed by triggers, full_text is indexed by the app when app indexing is enabled,
+-- it also includes unit, products where material is used and attached file names.
+-- +goose StatementBegin
+CREATE VIEW search_source AS
+SELECT
+    'material' AS type,
+    m.material_id AS ref_id,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') AS text,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                unit
+            FROM
+                unit_types
+            WHERE
+                unit_id = m.unit_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (p.name, ' ')
+            FROM
+                product_materials pm
+                INNER JOIN products p ON p.product_id = pm.product_id
+            WHERE
+                pm.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_materials fm
+                INNER JOIN files f ON f.file_id = fm.file_id
+            WHERE
+                fm.material_id = m.material_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    materials m
+UNION ALL
+SELECT
+    'product' AS type,
+    p.product_id AS ref_id,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') AS text,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_products fp
+                INNER JOIN files f ON f.file_id = fp.file_id
+            WHERE
+                fp.product_id = p.product_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    products p;
+-- +goose StatementEnd
+
+-- Triggers are disabled when index is maintained by the app.
+-- The app updates this flag on start from config.
+-- +goose StatementBegin
+CREATE TABLE
+    search_settings (
+        id INTEGER PRIMARY KEY CHECK (id = 1),
+        app_indexing BOOLEAN NOT NULL DEFAULT FALSE
+    );
+
+INSERT INTO
+    search_settings (id, app_indexing)
+VALUES
+    (1, FALSE);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS material_ai;
+DROP TRIGGER IF EXISTS material_au;
+DROP TRIGGER IF EXISTS material_ad;
+DROP TRIGGER IF EXISTS material_name_ai;
+DROP TRIGGER IF EXISTS material_name_ad;
+DROP TRIGGER IF EXISTS material_name_au;
+DROP TRIGGER IF EXISTS product_ai;
+DROP TRIGGER IF EXISTS product_au;
+DROP TRIGGER IF EXISTS product_ad;
+-- +goose StatementEnd
+
+-- Every trigger deletes entry before insert, so insert of material before its names
+-- doesn't leave duplicates. Insert from view does nothing when material is already deleted,
+-- e.g. when names are deleted by ON DELETE CASCADE.
+-- +goose StatementBegin
+CREATE TRIGGER material_ai AFTER INSERT ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_au AFTER UPDATE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ad AFTER DELETE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ai AFTER INSERT ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ad AFTER DELETE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_au AFTER UPDATE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ai AFTER INSERT ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = NEW.product_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_au AFTER UPDATE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ad AFTER DELETE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS material_ai;
+DROP TRIGGER IF EXISTS material_au;
+DROP TRIGGER IF EXISTS material_ad;
+DROP TRIGGER IF EXISTS material_name_ai;
+DROP TRIGGER IF EXISTS material_name_ad;
+DROP TRIGGER IF EXISTS material_name_au;
+DROP TRIGGER IF EXISTS product_ai;
+DROP TRIGGER IF EXISTS product_au;
+DROP TRIGGER IF EXISTS product_ad;
+DROP TABLE IF EXISTS search_settings;
+-- +goose StatementEnd
+
+-- Triggers from migrations 0004 and 0008.
+-- +goose StatementBegin
+CREATE TRIGGER material_ai AFTER INSERT ON materials BEGIN
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'material',
+        NEW.material_id,
+        COALESCE((SELECT group_concat(name, ' ') FROM material_names WHERE material_id = NEW.material_id), '') || ' ' || COALESCE(NEW.description, '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_au AFTER UPDATE ON materials BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id = OLD.material_id;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'material',
+        NEW.material_id,
+        COALESCE((SELECT group_concat(name, ' ') FROM material_names WHERE material_id = NEW.material_id), '') || ' ' || COALESCE(NEW.description, '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ad AFTER DELETE ON materials BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ai AFTER INSERT ON material_names BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id = NEW.material_id;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'material',
+        NEW.material_id,
+        COALESCE((SELECT group_concat(name, ' ') FROM material_names WHERE material_id = NEW.material_id), '') || ' ' || COALESCE((SELECT description FROM materials WHERE material_id = NEW.material_id), '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ad AFTER DELETE ON material_names BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id = OLD.material_id;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'material',
+        OLD.material_id,
+        COALESCE((SELECT group_concat(name, ' ') FROM material_names WHERE material_id = OLD.material_id), '') || ' ' || COALESCE((SELECT description FROM materials WHERE material_id = OLD.material_id), '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_au AFTER UPDATE ON material_names BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'material'
+    AND ref_id = NEW.material_id;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'material',
+        NEW.material_id,
+        COALESCE((SELECT group_concat(name, ' ') FROM material_names WHERE material_id = NEW.material_id), '') || ' ' || COALESCE((SELECT description FROM materials WHERE material_id = NEW.material_id), '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ai AFTER INSERT ON products BEGIN
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'product',
+        NEW.product_id,
+        COALESCE(NEW.name, '') || ' ' || COALESCE(NEW.description, '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_au AFTER UPDATE ON products BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'product'
+    AND ref_id = OLD.product_id;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+VALUES
+    (
+        'product',
+        NEW.product_id,
+        COALESCE(NEW.name, '') || ' ' || COALESCE(NEW.description, '')
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ad AFTER DELETE ON products BEGIN
+DELETE FROM fts_table
+WHERE
+    type = 'product'
+    AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+DROP VIEW IF EXISTS search_source;
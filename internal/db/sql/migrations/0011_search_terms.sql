@@ -0,0 +1,261 @@
+-- +goose Up
+-- Search table gets terms column with normalized text, see package internal/search.
+-- It's filled by search_terms function registered by the app, text column keeps original text
+-- for display and is not indexed anymore. Dot is a token character, so "1.5" is one term.
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS material_ai;
+DROP TRIGGER IF EXISTS material_au;
+DROP TRIGGER IF EXISTS material_ad;
+DROP TRIGGER IF EXISTS material_name_ai;
+DROP TRIGGER IF EXISTS material_name_ad;
+DROP TRIGGER IF EXISTS material_name_au;
+DROP TRIGGER IF EXISTS product_ai;
+DROP TRIGGER IF EXISTS product_au;
+DROP TRIGGER IF EXISTS product_ad;
+DROP VIEW IF EXISTS fts;
+DROP TABLE IF EXISTS fts_table;
+
+CREATE VIRTUAL TABLE fts_table USING fts5 (
+    type UNINDEXED,
+    ref_id UNINDEXED,
+    text UNINDEXED,
+    terms,
+    tokenize = "unicode61 tokenchars '.'"
+);
+
+CREATE VIEW fts AS
+SELECT
+    type,
+    ref_id,
+    text,
+    terms,
+    bm25(fts_table) AS score
+FROM
+    fts_table;
+
+-- List of all indexed terms, used to find similar words when query has typos.
+CREATE VIRTUAL TABLE fts_vocab USING fts5vocab (fts_table, 'row');
+
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END,
+    search_terms(CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END)
+FROM
+    search_source;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ai AFTER INSERT ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_au AFTER UPDATE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ad AFTER DELETE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ai AFTER INSERT ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ad AFTER DELETE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_au AFTER UPDATE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ai AFTER INSERT ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = NEW.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_au AFTER UPDATE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ad AFTER DELETE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS material_ai;
+DROP TRIGGER IF EXISTS material_au;
+DROP TRIGGER IF EXISTS material_ad;
+DROP TRIGGER IF EXISTS material_name_ai;
+DROP TRIGGER IF EXISTS material_name_ad;
+DROP TRIGGER IF EXISTS material_name_au;
+DROP TRIGGER IF EXISTS product_ai;
+DROP TRIGGER IF EXISTS product_au;
+DROP TRIGGER IF EXISTS product_ad;
+DROP TABLE IF EXISTS fts_vocab;
+DROP VIEW IF EXISTS fts;
+DROP TABLE IF EXISTS fts_table;
+
+CREATE VIRTUAL TABLE fts_table USING fts5 (
+    type UNINDEXED,
+    ref_id UNINDEXED,
+    text,
+    tokenize = 'unicode61'
+);
+
+CREATE VIEW fts AS
+SELECT
+    type,
+    ref_id,
+    text,
+    bm25(fts_table) AS score
+FROM
+    fts_table;
+
+INSERT INTO
+    fts_table (type, ref_id, text)
+SELECT
+    type,
+    ref_id,
+    CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END
+FROM
+    search_source;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ai AFTER INSERT ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_au AFTER UPDATE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_ad AFTER DELETE ON materials
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ai AFTER INSERT ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_ad AFTER DELETE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_name_au AFTER UPDATE ON material_names
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ai AFTER INSERT ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = NEW.product_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_au AFTER UPDATE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+INSERT INTO fts_table (type, ref_id, text)
+SELECT type, ref_id, text FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_ad AFTER DELETE ON products
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
@@ -0,0 +1,221 @@
+-- +goose Up
+-- Text extracted from attached documents by the app, see package internal/extract.
+-- Row is saved even for document without text, so extraction is not repeated.
+-- Text is indexed with type 'file' and ref_id of file, owner of file is found by search queries.
+-- +goose StatementBegin
+CREATE TABLE
+    file_texts (
+        file_id INTEGER PRIMARY KEY REFERENCES files (file_id) ON DELETE CASCADE,
+        text TEXT NOT NULL
+    );
+
+DROP VIEW search_source;
+
+CREATE VIEW search_source AS
+SELECT
+    'material' AS type,
+    m.material_id AS ref_id,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') AS text,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                unit
+            FROM
+                unit_types
+            WHERE
+                unit_id = m.unit_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (p.name, ' ')
+            FROM
+                product_materials pm
+                INNER JOIN products p ON p.product_id = pm.product_id
+            WHERE
+                pm.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_materials fm
+                INNER JOIN files f ON f.file_id = fm.file_id
+            WHERE
+                fm.material_id = m.material_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    materials m
+UNION ALL
+SELECT
+    'product' AS type,
+    p.product_id AS ref_id,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') AS text,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_products fp
+                INNER JOIN files f ON f.file_id = fp.file_id
+            WHERE
+                fp.product_id = p.product_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    products p
+UNION ALL
+SELECT
+    'file' AS type,
+    ft.file_id AS ref_id,
+    f.name || ' ' || ft.text AS text,
+    f.name || ' ' || ft.text AS full_text
+FROM
+    file_texts ft
+    INNER JOIN files f ON f.file_id = ft.file_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER file_text_ai AFTER INSERT ON file_texts
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'file' AND ref_id = NEW.file_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'file' AND ref_id = NEW.file_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER file_text_au AFTER UPDATE ON file_texts
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'file' AND ref_id = OLD.file_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'file' AND ref_id = NEW.file_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER file_text_ad AFTER DELETE ON file_texts
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'file' AND ref_id = OLD.file_id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS file_text_ai;
+DROP TRIGGER IF EXISTS file_text_au;
+DROP TRIGGER IF EXISTS file_text_ad;
+DELETE FROM fts_table WHERE type = 'file';
+DROP VIEW search_source;
+
+CREATE VIEW search_source AS
+SELECT
+    'material' AS type,
+    m.material_id AS ref_id,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') AS text,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                unit
+            FROM
+                unit_types
+            WHERE
+                unit_id = m.unit_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (p.name, ' ')
+            FROM
+                product_materials pm
+                INNER JOIN products p ON p.product_id = pm.product_id
+            WHERE
+                pm.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_materials fm
+                INNER JOIN files f ON f.file_id = fm.file_id
+            WHERE
+                fm.material_id = m.material_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    materials m
+UNION ALL
+SELECT
+    'product' AS type,
+    p.product_id AS ref_id,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') AS text,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_products fp
+                INNER JOIN files f ON f.file_id = fp.file_id
+            WHERE
+                fp.product_id = p.product_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    products p;
+
+DROP TABLE file_texts;
+-- +goose StatementEnd
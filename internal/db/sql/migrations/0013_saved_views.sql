@@ -0,0 +1,18 @@
+-- +goose Up
+-- Named states of materials and products tables and saved search queries.
+-- query keeps URL query parameters of the page, so a view is opened like a shared link.
+-- +goose StatementBegin
+CREATE TABLE
+    saved_views (
+        view_id INTEGER PRIMARY KEY,
+        kind TEXT NOT NULL CHECK (kind IN ('materials', 'products', 'search')),
+        name TEXT NOT NULL,
+        query TEXT NOT NULL,
+        UNIQUE (kind, name)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE saved_views;
+-- +goose StatementEnd
@@ -0,0 +1,86 @@
+-- +goose Up
+-- Tree of material categories. Category with children can't be deleted,
+-- materials of deleted category become uncategorized.
+-- +goose StatementBegin
+CREATE TABLE
+    categories (
+        category_id INTEGER PRIMARY KEY,
+        parent_id INTEGER REFERENCES categories (category_id),
+        name TEXT NOT NULL
+    );
+
+-- NULL parents are distinct in UNIQUE constraint, so names of top level categories are checked by index.
+CREATE UNIQUE INDEX categories_parent_name ON categories (COALESCE(parent_id, 0), name);
+
+-- Each material has at most one category.
+CREATE TABLE
+    material_categories (
+        material_id INTEGER PRIMARY KEY REFERENCES materials (material_id) ON DELETE CASCADE,
+        category_id INTEGER NOT NULL REFERENCES categories (category_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX material_categories_category ON material_categories (category_id);
+
+INSERT INTO
+    categories (category_id, parent_id, name)
+VALUES
+    (1, NULL, 'Металлопрокат'),
+    (2, 1, 'Лист'),
+    (3, 1, 'Труба'),
+    (4, 1, 'Круг'),
+    (5, NULL, 'Крепёж'),
+    (6, 5, 'Болты'),
+    (7, 5, 'Гайки'),
+    (8, 5, 'Шайбы'),
+    (9, NULL, 'Упаковка'),
+    (10, NULL, 'ЛКМ');
+
+-- Existing materials are classified by the first word of primary name. Rolled metal is
+-- measured by weight, so plastic pipes and sheets counted in pieces are left uncategorized.
+INSERT INTO
+    material_categories (material_id, category_id)
+SELECT
+    material_id,
+    category_id
+FROM
+    (
+        SELECT
+            n.material_id,
+            CASE
+                WHEN u.unit LIKE '%кг%'
+                AND n.name LIKE 'Лист%' THEN 2
+                WHEN u.unit LIKE '%кг%'
+                AND n.name LIKE 'Труба%' THEN 3
+                WHEN u.unit LIKE '%кг%'
+                AND n.name LIKE 'Круг%' THEN 4
+                WHEN n.name LIKE 'Болт%'
+                OR n.name LIKE 'Шпилька%' THEN 6
+                WHEN n.name LIKE 'Гайка%' THEN 7
+                WHEN n.name LIKE 'Шайба%' THEN 8
+                WHEN n.name LIKE 'Стретч%'
+                OR n.name LIKE 'Мешок%'
+                OR n.name LIKE 'Поддон%'
+                OR n.name LIKE 'Шпагат%'
+                OR n.name LIKE 'Этикетка%' THEN 9
+                WHEN n.name LIKE 'Грунт%'
+                OR n.name LIKE 'Эмаль%'
+                OR n.name LIKE 'Краска%'
+                OR n.name LIKE 'Разбавитель%' THEN 10
+            END AS category_id
+        FROM
+            material_names n
+            JOIN materials m ON m.material_id = n.material_id
+            JOIN unit_types u ON u.unit_id = m.unit_id
+        WHERE
+            n.is_primary = TRUE
+    )
+WHERE
+    category_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE material_categories;
+
+DROP TABLE categories;
+-- +goose StatementEnd
@@ -0,0 +1,535 @@
+-- +goose Up
+-- Typed attributes of materials and products. Attribute with category applies only to materials
+-- of the category and its subcategories, attribute without category applies to everything.
+-- Values are stored as text: numbers without unit, booleans as 'true' or 'false',
+-- enum values as one of options, which are stored one per line.
+-- +goose StatementBegin
+CREATE TABLE
+    attributes (
+        attribute_id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        kind TEXT NOT NULL CHECK (kind IN ('text', 'number', 'enum', 'bool')),
+        unit TEXT NOT NULL DEFAULT '',
+        options TEXT NOT NULL DEFAULT '',
+        category_id INTEGER REFERENCES categories (category_id) ON DELETE CASCADE
+    );
+
+CREATE UNIQUE INDEX attributes_category_name ON attributes (COALESCE(category_id, 0), name);
+
+CREATE TABLE
+    material_attributes (
+        material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+        attribute_id INTEGER NOT NULL REFERENCES attributes (attribute_id) ON DELETE CASCADE,
+        value TEXT NOT NULL,
+        PRIMARY KEY (material_id, attribute_id)
+    );
+
+CREATE INDEX material_attributes_attribute ON material_attributes (attribute_id);
+
+CREATE TABLE
+    product_attributes (
+        product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+        attribute_id INTEGER NOT NULL REFERENCES attributes (attribute_id) ON DELETE CASCADE,
+        value TEXT NOT NULL,
+        PRIMARY KEY (product_id, attribute_id)
+    );
+
+CREATE INDEX product_attributes_attribute ON product_attributes (attribute_id);
+
+-- Free tags, tag is stored as typed by user.
+CREATE TABLE
+    material_tags (
+        material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+        tag TEXT NOT NULL,
+        PRIMARY KEY (material_id, tag)
+    );
+
+CREATE TABLE
+    product_tags (
+        product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+        tag TEXT NOT NULL,
+        PRIMARY KEY (product_id, tag)
+    );
+
+INSERT INTO
+    attributes (name, kind, unit, options, category_id)
+VALUES
+    ('Стандарт', 'text', '', '', NULL),
+    ('Артикул поставщика', 'text', '', '', NULL);
+
+-- Category attributes are added only for seeded categories that still exist.
+INSERT INTO
+    attributes (name, kind, unit, options, category_id)
+SELECT
+    a.column1,
+    a.column2,
+    a.column3,
+    a.column4,
+    c.category_id
+FROM
+    (
+        VALUES
+            ('Марка стали', 'text', '', '', 'Металлопрокат'),
+            ('Толщина', 'number', 'мм', '', 'Лист'),
+            ('Диаметр', 'number', 'мм', '', 'Труба'),
+            ('Толщина стенки', 'number', 'мм', '', 'Труба'),
+            ('Диаметр', 'number', 'мм', '', 'Круг'),
+            ('Покрытие', 'enum', '', 'без покрытия' || char(10) || 'оцинкованное' || char(10) || 'нержавеющая сталь', 'Крепёж'),
+            ('Цвет RAL', 'text', '', '', 'ЛКМ')
+    ) a
+    INNER JOIN categories c ON c.name = a.column5;
+
+DROP VIEW search_source;
+
+-- Tags and attribute values are part of indexed text. Number is indexed with unit,
+-- true boolean is indexed by attribute name, so "оцинкованное" or "3 мм" finds materials.
+CREATE VIEW search_source AS
+SELECT
+    'material' AS type,
+    m.material_id AS ref_id,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (tag, ' ')
+            FROM
+                material_tags
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (
+                    CASE a.kind
+                        WHEN 'number' THEN ma.value || ' ' || a.unit
+                        WHEN 'bool' THEN CASE ma.value
+                            WHEN 'true' THEN a.name
+                            ELSE ''
+                        END
+                        ELSE ma.value
+                    END,
+                    ' '
+                )
+            FROM
+                material_attributes ma
+                INNER JOIN attributes a ON a.attribute_id = ma.attribute_id
+            WHERE
+                ma.material_id = m.material_id
+        ),
+        ''
+    ) AS text,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (tag, ' ')
+            FROM
+                material_tags
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (
+                    CASE a.kind
+                        WHEN 'number' THEN ma.value || ' ' || a.unit
+                        WHEN 'bool' THEN CASE ma.value
+                            WHEN 'true' THEN a.name
+                            ELSE ''
+                        END
+                        ELSE ma.value
+                    END,
+                    ' '
+                )
+            FROM
+                material_attributes ma
+                INNER JOIN attributes a ON a.attribute_id = ma.attribute_id
+            WHERE
+                ma.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                unit
+            FROM
+                unit_types
+            WHERE
+                unit_id = m.unit_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (p.name, ' ')
+            FROM
+                product_materials pm
+                INNER JOIN products p ON p.product_id = pm.product_id
+            WHERE
+                pm.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_materials fm
+                INNER JOIN files f ON f.file_id = fm.file_id
+            WHERE
+                fm.material_id = m.material_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    materials m
+UNION ALL
+SELECT
+    'product' AS type,
+    p.product_id AS ref_id,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (tag, ' ')
+            FROM
+                product_tags
+            WHERE
+                product_id = p.product_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (
+                    CASE a.kind
+                        WHEN 'number' THEN pa.value || ' ' || a.unit
+                        WHEN 'bool' THEN CASE pa.value
+                            WHEN 'true' THEN a.name
+                            ELSE ''
+                        END
+                        ELSE pa.value
+                    END,
+                    ' '
+                )
+            FROM
+                product_attributes pa
+                INNER JOIN attributes a ON a.attribute_id = pa.attribute_id
+            WHERE
+                pa.product_id = p.product_id
+        ),
+        ''
+    ) AS text,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (tag, ' ')
+            FROM
+                product_tags
+            WHERE
+                product_id = p.product_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (
+                    CASE a.kind
+                        WHEN 'number' THEN pa.value || ' ' || a.unit
+                        WHEN 'bool' THEN CASE pa.value
+                            WHEN 'true' THEN a.name
+                            ELSE ''
+                        END
+                        ELSE pa.value
+                    END,
+                    ' '
+                )
+            FROM
+                product_attributes pa
+                INNER JOIN attributes a ON a.attribute_id = pa.attribute_id
+            WHERE
+                pa.product_id = p.product_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_products fp
+                INNER JOIN files f ON f.file_id = fp.file_id
+            WHERE
+                fp.product_id = p.product_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    products p
+UNION ALL
+SELECT
+    'file' AS type,
+    ft.file_id AS ref_id,
+    f.name || ' ' || ft.text AS text,
+    f.name || ' ' || ft.text AS full_text
+FROM
+    file_texts ft
+    INNER JOIN files f ON f.file_id = ft.file_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_tag_ai AFTER INSERT ON material_tags
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_tag_ad AFTER DELETE ON material_tags
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_attribute_ai AFTER INSERT ON material_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = NEW.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = NEW.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_attribute_au AFTER UPDATE ON material_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id IN (OLD.material_id, NEW.material_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER material_attribute_ad AFTER DELETE ON material_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id = OLD.material_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id = OLD.material_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_tag_ai AFTER INSERT ON product_tags
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = NEW.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_tag_ad AFTER DELETE ON product_tags
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_attribute_ai AFTER INSERT ON product_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = NEW.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_attribute_au AFTER UPDATE ON product_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id IN (OLD.product_id, NEW.product_id);
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id IN (OLD.product_id, NEW.product_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER product_attribute_ad AFTER DELETE ON product_attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'product' AND ref_id = OLD.product_id;
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+-- Index is rebuilt from new search_source, so it matches the view the same way as after 0011.
+-- +goose StatementBegin
+DELETE FROM fts_table;
+
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END,
+    search_terms(CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END)
+FROM
+    search_source;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS material_tag_ai;
+DROP TRIGGER IF EXISTS material_tag_ad;
+DROP TRIGGER IF EXISTS material_attribute_ai;
+DROP TRIGGER IF EXISTS material_attribute_au;
+DROP TRIGGER IF EXISTS material_attribute_ad;
+DROP TRIGGER IF EXISTS product_tag_ai;
+DROP TRIGGER IF EXISTS product_tag_ad;
+DROP TRIGGER IF EXISTS product_attribute_ai;
+DROP TRIGGER IF EXISTS product_attribute_au;
+DROP TRIGGER IF EXISTS product_attribute_ad;
+DROP VIEW search_source;
+
+CREATE VIEW search_source AS
+SELECT
+    'material' AS type,
+    m.material_id AS ref_id,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') AS text,
+    COALESCE(
+        (
+            SELECT
+                group_concat (name, ' ')
+            FROM
+                material_names
+            WHERE
+                material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(m.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                unit
+            FROM
+                unit_types
+            WHERE
+                unit_id = m.unit_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (p.name, ' ')
+            FROM
+                product_materials pm
+                INNER JOIN products p ON p.product_id = pm.product_id
+            WHERE
+                pm.material_id = m.material_id
+        ),
+        ''
+    ) || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_materials fm
+                INNER JOIN files f ON f.file_id = fm.file_id
+            WHERE
+                fm.material_id = m.material_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    materials m
+UNION ALL
+SELECT
+    'product' AS type,
+    p.product_id AS ref_id,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') AS text,
+    COALESCE(p.name, '') || ' ' || COALESCE(p.description, '') || ' ' || COALESCE(
+        (
+            SELECT
+                group_concat (f.name, ' ')
+            FROM
+                files_products fp
+                INNER JOIN files f ON f.file_id = fp.file_id
+            WHERE
+                fp.product_id = p.product_id
+        ),
+        ''
+    ) AS full_text
+FROM
+    products p
+UNION ALL
+SELECT
+    'file' AS type,
+    ft.file_id AS ref_id,
+    f.name || ' ' || ft.text AS text,
+    f.name || ' ' || ft.text AS full_text
+FROM
+    file_texts ft
+    INNER JOIN files f ON f.file_id = ft.file_id;
+
+DROP TABLE product_tags;
+DROP TABLE material_tags;
+DROP TABLE product_attributes;
+DROP TABLE material_attributes;
+DROP TABLE attributes;
+
+DELETE FROM fts_table;
+
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END,
+    search_terms(CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END)
+FROM
+    search_source;
+-- +goose StatementEnd
@@ -0,0 +1,146 @@
+-- +goose Up
+-- Categories and size attributes of steel profiles, names match profile parser in package helpers.
+-- +goose StatementBegin
+INSERT INTO
+    categories (parent_id, name)
+SELECT
+    c.category_id,
+    p.column1
+FROM
+    (
+        VALUES
+            ('Рулон'),
+            ('Уголок'),
+            ('Полоса'),
+            ('Квадрат')
+    ) p
+    INNER JOIN categories c ON c.name = 'Металлопрокат'
+    AND c.parent_id IS NULL
+WHERE
+    NOT EXISTS (
+        SELECT
+            1
+        FROM
+            categories e
+        WHERE
+            e.parent_id = c.category_id
+            AND e.name = p.column1
+    );
+
+INSERT INTO
+    attributes (name, kind, unit, category_id)
+SELECT
+    a.column1,
+    'number',
+    'мм',
+    c.category_id
+FROM
+    (
+        VALUES
+            ('Ширина', 'Лист'),
+            ('Длина', 'Лист'),
+            ('Высота', 'Труба'),
+            ('Ширина', 'Труба'),
+            ('Толщина', 'Рулон'),
+            ('Ширина', 'Рулон'),
+            ('Полка', 'Уголок'),
+            ('Вторая полка', 'Уголок'),
+            ('Толщина', 'Уголок'),
+            ('Ширина', 'Полоса'),
+            ('Толщина', 'Полоса'),
+            ('Сторона', 'Квадрат')
+    ) a
+    INNER JOIN categories c ON c.name = a.column2
+WHERE
+    NOT EXISTS (
+        SELECT
+            1
+        FROM
+            attributes e
+        WHERE
+            e.category_id = c.category_id
+            AND e.name = a.column1
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- Migrations run without foreign keys, so values and links are deleted explicitly.
+-- +goose StatementBegin
+CREATE TEMP TABLE profile_categories AS
+SELECT
+    category_id
+FROM
+    categories
+WHERE
+    name IN ('Рулон', 'Уголок', 'Полоса', 'Квадрат')
+    AND parent_id IN (
+        SELECT
+            category_id
+        FROM
+            categories
+        WHERE
+            name = 'Металлопрокат'
+            AND parent_id IS NULL
+    );
+
+CREATE TEMP TABLE profile_attributes AS
+SELECT
+    a.attribute_id
+FROM
+    attributes a
+    INNER JOIN categories c ON c.category_id = a.category_id
+WHERE
+    (c.name, a.name) IN (
+        VALUES
+            ('Лист', 'Ширина'),
+            ('Лист', 'Длина'),
+            ('Труба', 'Высота'),
+            ('Труба', 'Ширина')
+    )
+    OR a.category_id IN (
+        SELECT
+            category_id
+        FROM
+            profile_categories
+    );
+
+DELETE FROM material_attributes
+WHERE
+    attribute_id IN (
+        SELECT
+            attribute_id
+        FROM
+            profile_attributes
+    );
+
+DELETE FROM attributes
+WHERE
+    attribute_id IN (
+        SELECT
+            attribute_id
+        FROM
+            profile_attributes
+    );
+
+DELETE FROM material_categories
+WHERE
+    category_id IN (
+        SELECT
+            category_id
+        FROM
+            profile_categories
+    );
+
+DELETE FROM categories
+WHERE
+    category_id IN (
+        SELECT
+            category_id
+        FROM
+            profile_categories
+    );
+
+DROP TABLE profile_attributes;
+
+DROP TABLE profile_categories;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Densities of steel grades and materials in kg/m³ for theoretical mass of profiles.
+-- Name is matched against steel grade or material name, 'Сталь' is used for profiles without known grade.
+-- +goose StatementBegin
+CREATE TABLE
+    densities (
+        density_id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL UNIQUE,
+        density REAL NOT NULL CHECK (density > 0)
+    );
+
+INSERT INTO
+    densities (name, density)
+VALUES
+    ('Сталь', 7850),
+    ('08Х18Н10', 7900),
+    ('12Х18Н10Т', 7900),
+    ('AISI 304', 7900),
+    ('ПА-6', 1130),
+    ('Алюминий', 2700),
+    ('Латунь', 8500),
+    ('Медь', 8940);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE densities;
+-- +goose StatementEnd
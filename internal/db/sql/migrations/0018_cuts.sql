@@ -0,0 +1,20 @@
+-- +goose Up
+-- Cut lists of BOM lines: pieces of length in mm cut from stock bars, quantity is per one product.
+-- Cuts reference product and material rather than BOM line, because BOM lines are rewritten on every product save.
+-- +goose StatementBegin
+CREATE TABLE
+    product_cuts (
+        cut_id INTEGER PRIMARY KEY,
+        product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+        material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+        length REAL NOT NULL CHECK (length > 0),
+        quantity INTEGER NOT NULL CHECK (quantity > 0)
+    );
+
+CREATE INDEX idx_product_cuts_product ON product_cuts (product_id, material_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE product_cuts;
+-- +goose StatementEnd
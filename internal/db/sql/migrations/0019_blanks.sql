@@ -0,0 +1,21 @@
+-- +goose Up
+-- Rectangular blanks of BOM lines cut from sheets, sizes are in mm and quantity is per one product.
+-- Like cuts, blanks reference product and material because BOM lines are rewritten on every product save.
+-- +goose StatementBegin
+CREATE TABLE
+    product_blanks (
+        blank_id INTEGER PRIMARY KEY,
+        product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+        material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+        width REAL NOT NULL CHECK (width > 0),
+        height REAL NOT NULL CHECK (height > 0),
+        quantity INTEGER NOT NULL CHECK (quantity > 0)
+    );
+
+CREATE INDEX idx_product_blanks_product ON product_blanks (product_id, material_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE product_blanks;
+-- +goose StatementEnd
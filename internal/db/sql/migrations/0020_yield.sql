@@ -0,0 +1,25 @@
+-- +goose Up
+-- Yield of material is share of gross quantity that goes into products, gross = net ÷ yield.
+-- Scrap of BOM line overrides yield of its material, NULL means the material yield is used.
+-- +goose StatementBegin
+ALTER TABLE materials
+ADD COLUMN yield_percent REAL NOT NULL DEFAULT 100 CHECK (
+    yield_percent > 0
+    AND yield_percent <= 100
+);
+
+ALTER TABLE product_materials
+ADD COLUMN scrap_percent REAL CHECK (
+    scrap_percent >= 0
+    AND scrap_percent < 100
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE product_materials
+DROP COLUMN scrap_percent;
+
+ALTER TABLE materials
+DROP COLUMN yield_percent;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Saved inputs of production plan calculator. query keeps the plan form as URL query like saved views do.
+-- bom_revision is fingerprint of BOM lines of planned products and result is JSON of requirements
+-- computed against that revision, so a scenario can be compared with a re-run after BOM changes.
+-- +goose StatementBegin
+CREATE TABLE
+    scenarios (
+        scenario_id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL UNIQUE,
+        query TEXT NOT NULL,
+        bom_revision TEXT NOT NULL,
+        result TEXT NOT NULL,
+        saved_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE scenarios;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Stock ledger. Balance of material at location is sum of movements into it minus movements out of it:
+-- receipt comes into location, issue goes out of it, transfer moves between two locations and
+-- adjustment after stock-taking either adds or removes difference. Quantity is always positive and
+-- is kept in unit material had when movement was made, so balances are per material and unit.
+-- +goose StatementBegin
+CREATE TABLE
+    locations (
+        location_id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL UNIQUE
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO
+    locations (name)
+VALUES
+    ('Основной склад');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE
+    stock_movements (
+        movement_id INTEGER PRIMARY KEY,
+        material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE RESTRICT,
+        unit TEXT NOT NULL,
+        kind TEXT NOT NULL CHECK (kind IN ('receipt', 'issue', 'transfer', 'adjustment')),
+        quantity REAL NOT NULL CHECK (quantity > 0),
+        from_location_id INTEGER REFERENCES locations (location_id) ON DELETE RESTRICT,
+        to_location_id INTEGER REFERENCES locations (location_id) ON DELETE RESTRICT,
+        moved_on TEXT NOT NULL,
+        comment TEXT NOT NULL DEFAULT '',
+        CHECK (
+            (kind = 'receipt' AND from_location_id IS NULL AND to_location_id IS NOT NULL)
+            OR (kind = 'issue' AND from_location_id IS NOT NULL AND to_location_id IS NULL)
+            OR (kind = 'transfer' AND from_location_id IS NOT NULL AND to_location_id IS NOT NULL AND from_location_id <> to_location_id)
+            OR (kind = 'adjustment' AND (from_location_id IS NULL) <> (to_location_id IS NULL))
+        )
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX stock_movements_material ON stock_movements (material_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE stock_movements;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE locations;
+-- +goose StatementEnd
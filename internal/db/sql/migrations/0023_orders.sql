@@ -0,0 +1,57 @@
+-- +goose Up
+-- Production orders. Lines keep BOM of product exploded for quantity of order when order was created,
+-- so later BOM changes don't change norms. Names are copied for the same reason and references become
+-- NULL when product or material is deleted. norm is gross quantity for the whole order, it's NULL for
+-- lines with text quantity. Order is open until completed_on is set, completion writes actual
+-- consumption and posts issue movements with order_id. Only open orders without movements can be
+-- deleted, so order_id of movements has no foreign key and can be dropped on downgrade.
+-- +goose StatementBegin
+CREATE TABLE
+    production_orders (
+        order_id INTEGER PRIMARY KEY,
+        product_id INTEGER REFERENCES products (product_id) ON DELETE SET NULL,
+        product_name TEXT NOT NULL,
+        quantity INTEGER NOT NULL CHECK (quantity > 0),
+        location_id INTEGER NOT NULL REFERENCES locations (location_id) ON DELETE RESTRICT,
+        created_on TEXT NOT NULL,
+        completed_on TEXT,
+        comment TEXT NOT NULL DEFAULT ''
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE
+    production_order_lines (
+        line_id INTEGER PRIMARY KEY,
+        order_id INTEGER NOT NULL REFERENCES production_orders (order_id) ON DELETE CASCADE,
+        material_id INTEGER REFERENCES materials (material_id) ON DELETE SET NULL,
+        material_name TEXT NOT NULL,
+        unit TEXT NOT NULL,
+        bom_quantity TEXT NOT NULL,
+        norm REAL CHECK (norm > 0),
+        actual REAL CHECK (actual >= 0)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX production_order_lines_order ON production_order_lines (order_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+ADD COLUMN order_id INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+DROP COLUMN order_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE production_order_lines;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE production_orders;
+-- +goose StatementEnd
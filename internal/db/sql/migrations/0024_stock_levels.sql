@@ -0,0 +1,17 @@
+-- +goose Up
+-- Minimum stock, reorder point and reorder quantity of material in its current unit. Material without
+-- levels has no row, levels are deleted with material.
+-- +goose StatementBegin
+CREATE TABLE
+    stock_levels (
+        material_id INTEGER PRIMARY KEY REFERENCES materials (material_id) ON DELETE CASCADE,
+        minimum REAL NOT NULL DEFAULT 0 CHECK (minimum >= 0),
+        reorder_point REAL NOT NULL DEFAULT 0 CHECK (reorder_point >= 0),
+        reorder_quantity REAL NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE stock_levels;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Name, kind and unit of attribute are part of search text of materials and products with its values,
+-- so changed attribute refreshes index entries of its owners like changed value does.
+-- +goose StatementBegin
+CREATE TRIGGER attribute_au AFTER UPDATE ON attributes
+WHEN (SELECT app_indexing FROM search_settings) = 0
+BEGIN
+DELETE FROM fts_table WHERE type = 'material' AND ref_id IN (
+    SELECT material_id FROM material_attributes WHERE attribute_id = NEW.attribute_id
+);
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'material' AND ref_id IN (
+    SELECT material_id FROM material_attributes WHERE attribute_id = NEW.attribute_id
+);
+DELETE FROM fts_table WHERE type = 'product' AND ref_id IN (
+    SELECT product_id FROM product_attributes WHERE attribute_id = NEW.attribute_id
+);
+INSERT INTO fts_table (type, ref_id, text, terms)
+SELECT type, ref_id, text, search_terms(text) FROM search_source WHERE type = 'product' AND ref_id IN (
+    SELECT product_id FROM product_attributes WHERE attribute_id = NEW.attribute_id
+);
+END;
+-- +goose StatementEnd
+
+-- Entries left outdated by earlier changes of attributes are rebuilt.
+-- +goose StatementBegin
+DELETE FROM fts_table;
+
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END,
+    search_terms(CASE WHEN (SELECT app_indexing FROM search_settings) THEN full_text ELSE text END)
+FROM
+    search_source;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS attribute_au;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Date of last scheduled reorder digest, so digest isn't sent again when app is restarted the same day.
+-- Empty date means digest wasn't sent yet.
+-- +goose StatementBegin
+CREATE TABLE
+    digest_state (
+        id INTEGER PRIMARY KEY CHECK (id = 1),
+        last_sent_on TEXT NOT NULL DEFAULT ''
+    );
+
+INSERT INTO
+    digest_state (id)
+VALUES
+    (1);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE digest_state;
+-- +goose StatementEnd
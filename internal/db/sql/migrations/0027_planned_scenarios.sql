@@ -0,0 +1,13 @@
+-- +goose Up
+-- Planned scenario is demand of production plan, only planned scenarios make demand of reorder widget and digest.
+-- Copies and what-if scenarios aren't planned, so they don't add to demand.
+-- +goose StatementBegin
+ALTER TABLE scenarios
+ADD COLUMN planned BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scenarios
+DROP COLUMN planned;
+-- +goose StatementEnd
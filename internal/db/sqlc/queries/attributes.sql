@@ -0,0 +1,195 @@
+-- name: GetAllAttributes :many
+SELECT
+    *
+FROM
+    attributes
+ORDER BY
+    COALESCE(category_id, 0),
+    name;
+
+-- name: InsertAttribute :one
+INSERT INTO
+    attributes (name, kind, unit, options, category_id)
+VALUES
+    (?, ?, ?, ?, ?) RETURNING *;
+
+-- name: UpdateAttribute :exec
+UPDATE attributes
+SET
+    name = ?,
+    kind = ?,
+    unit = ?,
+    options = ?,
+    category_id = ?
+WHERE
+    attribute_id = ?;
+
+-- name: DeleteAttribute :exec
+DELETE FROM attributes
+WHERE
+    attribute_id = ?;
+
+-- name: ImportAttribute :exec
+INSERT INTO
+    attributes (attribute_id, name, kind, unit, options, category_id)
+VALUES
+    (?, ?, ?, ?, ?, ?);
+
+-- name: ClearAttributes :exec
+DELETE FROM attributes;
+
+-- name: GetMaterialAttributes :many
+SELECT
+    ma.material_id,
+    ma.value,
+    a.attribute_id,
+    a.name,
+    a.kind,
+    a.unit
+FROM
+    material_attributes ma
+    INNER JOIN attributes a ON a.attribute_id = ma.attribute_id
+WHERE
+    ma.material_id = ?
+ORDER BY
+    COALESCE(a.category_id, 0),
+    a.name;
+
+-- name: GetAllMaterialAttributes :many
+SELECT
+    ma.material_id,
+    ma.value,
+    a.attribute_id,
+    a.name,
+    a.kind,
+    a.unit
+FROM
+    material_attributes ma
+    INNER JOIN attributes a ON a.attribute_id = ma.attribute_id
+ORDER BY
+    ma.material_id,
+    COALESCE(a.category_id, 0),
+    a.name;
+
+-- name: SetMaterialAttribute :exec
+INSERT INTO
+    material_attributes (material_id, attribute_id, value)
+VALUES
+    (?, ?, ?)
+ON CONFLICT (material_id, attribute_id) DO UPDATE
+SET
+    value = excluded.value;
+
+-- name: DeleteMaterialAttributes :exec
+DELETE FROM material_attributes
+WHERE
+    material_id = ?;
+
+-- name: GetMaterialTags :many
+SELECT
+    tag
+FROM
+    material_tags
+WHERE
+    material_id = ?
+ORDER BY
+    tag;
+
+-- name: GetAllMaterialTags :many
+SELECT
+    material_id,
+    tag
+FROM
+    material_tags
+ORDER BY
+    material_id,
+    tag;
+
+-- name: InsertMaterialTag :exec
+INSERT INTO
+    material_tags (material_id, tag)
+VALUES
+    (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMaterialTags :exec
+DELETE FROM material_tags
+WHERE
+    material_id = ?;
+
+-- name: GetProductAttributes :many
+SELECT
+    pa.product_id,
+    pa.value,
+    a.attribute_id,
+    a.name,
+    a.kind,
+    a.unit
+FROM
+    product_attributes pa
+    INNER JOIN attributes a ON a.attribute_id = pa.attribute_id
+WHERE
+    pa.product_id = ?
+ORDER BY
+    a.name;
+
+-- name: GetAllProductAttributes :many
+SELECT
+    pa.product_id,
+    pa.value,
+    a.attribute_id,
+    a.name,
+    a.kind,
+    a.unit
+FROM
+    product_attributes pa
+    INNER JOIN attributes a ON a.attribute_id = pa.attribute_id
+ORDER BY
+    pa.product_id,
+    a.name;
+
+-- name: SetProductAttribute :exec
+INSERT INTO
+    product_attributes (product_id, attribute_id, value)
+VALUES
+    (?, ?, ?)
+ON CONFLICT (product_id, attribute_id) DO UPDATE
+SET
+    value = excluded.value;
+
+-- name: DeleteProductAttributes :exec
+DELETE FROM product_attributes
+WHERE
+    product_id = ?;
+
+-- name: GetProductTags :many
+SELECT
+    tag
+FROM
+    product_tags
+WHERE
+    product_id = ?
+ORDER BY
+    tag;
+
+-- name: GetAllProductTags :many
+SELECT
+    product_id,
+    tag
+FROM
+    product_tags
+ORDER BY
+    product_id,
+    tag;
+
+-- name: InsertProductTag :exec
+INSERT INTO
+    product_tags (product_id, tag)
+VALUES
+    (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteProductTags :exec
+DELETE FROM product_tags
+WHERE
+    product_id = ?;
@@ -0,0 +1,54 @@
+-- name: GetProductBlanks :many
+SELECT
+    material_id,
+    width,
+    height,
+    quantity
+FROM
+    product_blanks
+WHERE
+    product_id = ?
+ORDER BY
+    material_id,
+    width * height DESC,
+    blank_id;
+
+-- name: GetAllProductBlanks :many
+SELECT
+    product_id,
+    material_id,
+    width,
+    height,
+    quantity
+FROM
+    product_blanks
+ORDER BY
+    product_id,
+    material_id,
+    width * height DESC,
+    blank_id;
+
+-- name: InsertProductBlank :exec
+INSERT INTO
+    product_blanks (product_id, material_id, width, height, quantity)
+VALUES
+    (?, ?, ?, ?, ?);
+
+-- name: DeleteProductMaterialBlanks :exec
+DELETE FROM product_blanks
+WHERE
+    product_id = ?
+    AND material_id = ?;
+
+-- name: DeleteStaleProductBlanks :exec
+DELETE FROM product_blanks
+WHERE
+    product_blanks.product_id = sqlc.arg (product_id)
+    AND product_blanks.material_id NOT IN (
+        SELECT
+            pm.material_id
+        FROM
+            product_materials pm
+        WHERE
+            pm.product_id = sqlc.arg (product_id)
+    );
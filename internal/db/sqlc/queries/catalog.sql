@@ -0,0 +1,155 @@
+-- name: ExportMaterials :many
+SELECT
+    m.material_id,
+    m.unit_id,
+    m.description,
+    m.yield_percent,
+    mc.category_id
+FROM
+    materials m
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+ORDER BY
+    m.material_id;
+
+-- name: ExportMaterialNames :many
+SELECT
+    material_id,
+    name,
+    is_primary
+FROM
+    material_names
+ORDER BY
+    material_id,
+    name_id;
+
+-- name: ExportProducts :many
+SELECT
+    product_id,
+    name,
+    description
+FROM
+    products
+ORDER BY
+    product_id;
+
+-- name: ExportProductMaterials :many
+SELECT
+    product_id,
+    material_id,
+    quantity,
+    quantity_text,
+    scrap_percent
+FROM
+    product_materials
+ORDER BY
+    product_id,
+    material_id;
+
+-- name: ExportFiles :many
+SELECT
+    *
+FROM
+    files
+ORDER BY
+    file_id;
+
+-- name: ExportMaterialFiles :many
+SELECT
+    material_id,
+    file_id
+FROM
+    files_materials
+ORDER BY
+    file_id,
+    material_id;
+
+-- name: ExportProductFiles :many
+SELECT
+    product_id,
+    file_id
+FROM
+    files_products
+ORDER BY
+    file_id,
+    product_id;
+
+-- name: CountCatalogItems :one
+SELECT
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            materials
+    ) + (
+        SELECT
+            COUNT(*)
+        FROM
+            products
+    ) + (
+        SELECT
+            COUNT(*)
+        FROM
+            files
+    ) AS total;
+
+-- name: CountStockLedger :one
+SELECT
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            stock_movements
+    ) + (
+        SELECT
+            COUNT(*)
+        FROM
+            production_orders
+    ) AS total;
+
+-- name: ClearProductMaterials :exec
+DELETE FROM product_materials;
+
+-- name: ClearMaterialFiles :exec
+DELETE FROM files_materials;
+
+-- name: ClearProductFiles :exec
+DELETE FROM files_products;
+
+-- name: ClearFiles :exec
+DELETE FROM files;
+
+-- name: ClearMaterialNames :exec
+DELETE FROM material_names;
+
+-- name: ClearMaterials :exec
+DELETE FROM materials;
+
+-- name: ClearProducts :exec
+DELETE FROM products;
+
+-- name: ClearUnits :exec
+DELETE FROM unit_types;
+
+-- name: ImportUnit :exec
+INSERT INTO
+    unit_types (unit_id, unit)
+VALUES
+    (?, ?);
+
+-- name: ImportMaterial :exec
+INSERT INTO
+    materials (material_id, unit_id, description, yield_percent)
+VALUES
+    (?, ?, ?, ?);
+
+-- name: ImportProduct :exec
+INSERT INTO
+    products (product_id, name, description)
+VALUES
+    (?, ?, ?);
+
+-- name: ImportFile :exec
+INSERT INTO
+    files (file_id, name, path, mime_type, file_type)
+VALUES
+    (?, ?, ?, ?, ?);
@@ -0,0 +1,70 @@
+-- name: GetAllCategories :many
+SELECT
+    c.category_id,
+    c.parent_id,
+    c.name,
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            material_categories mc
+        WHERE
+            mc.category_id = c.category_id
+    ) AS material_count
+FROM
+    categories c
+ORDER BY
+    c.name;
+
+-- name: InsertCategory :one
+INSERT INTO
+    categories (parent_id, name)
+VALUES
+    (?, ?) RETURNING *;
+
+-- name: UpdateCategory :exec
+UPDATE categories
+SET
+    parent_id = ?,
+    name = ?
+WHERE
+    category_id = ?;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories
+WHERE
+    category_id = ?;
+
+-- name: SetMaterialCategory :exec
+INSERT INTO
+    material_categories (material_id, category_id)
+VALUES
+    (?, ?)
+ON CONFLICT (material_id) DO UPDATE
+SET
+    category_id = excluded.category_id;
+
+-- name: UnsetMaterialCategory :exec
+DELETE FROM material_categories
+WHERE
+    material_id = ?;
+
+-- name: ExportCategories :many
+SELECT
+    *
+FROM
+    categories
+ORDER BY
+    category_id;
+
+-- name: ImportCategory :exec
+INSERT INTO
+    categories (category_id, parent_id, name)
+VALUES
+    (?, ?, ?);
+
+-- name: ClearMaterialCategories :exec
+DELETE FROM material_categories;
+
+-- name: ClearCategories :exec
+DELETE FROM categories;
@@ -0,0 +1,52 @@
+-- name: GetProductCuts :many
+SELECT
+    material_id,
+    length,
+    quantity
+FROM
+    product_cuts
+WHERE
+    product_id = ?
+ORDER BY
+    material_id,
+    length DESC,
+    cut_id;
+
+-- name: GetAllProductCuts :many
+SELECT
+    product_id,
+    material_id,
+    length,
+    quantity
+FROM
+    product_cuts
+ORDER BY
+    product_id,
+    material_id,
+    length DESC,
+    cut_id;
+
+-- name: InsertProductCut :exec
+INSERT INTO
+    product_cuts (product_id, material_id, length, quantity)
+VALUES
+    (?, ?, ?, ?);
+
+-- name: DeleteProductMaterialCuts :exec
+DELETE FROM product_cuts
+WHERE
+    product_id = ?
+    AND material_id = ?;
+
+-- name: DeleteStaleProductCuts :exec
+DELETE FROM product_cuts
+WHERE
+    product_cuts.product_id = sqlc.arg (product_id)
+    AND product_cuts.material_id NOT IN (
+        SELECT
+            pm.material_id
+        FROM
+            product_materials pm
+        WHERE
+            pm.product_id = sqlc.arg (product_id)
+    );
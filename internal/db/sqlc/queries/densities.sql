@@ -0,0 +1,35 @@
+-- name: GetAllDensities :many
+SELECT
+    *
+FROM
+    densities
+ORDER BY
+    name;
+
+-- name: InsertDensity :one
+INSERT INTO
+    densities (name, density)
+VALUES
+    (?, ?) RETURNING *;
+
+-- name: UpdateDensity :exec
+UPDATE densities
+SET
+    name = ?,
+    density = ?
+WHERE
+    density_id = ?;
+
+-- name: DeleteDensity :exec
+DELETE FROM densities
+WHERE
+    density_id = ?;
+
+-- name: ImportDensity :exec
+INSERT INTO
+    densities (density_id, name, density)
+VALUES
+    (?, ?, ?);
+
+-- name: ClearDensities :exec
+DELETE FROM densities;
@@ -3,15 +3,20 @@ SELECT
     m.material_id,
     m.unit_id,
     m.description,
+    m.yield_percent,
     ut.unit,
     mn.name AS material_name,
     pm.quantity,
     pm.quantity_text,
     p.product_id,
-    p.name AS product_name
+    p.name AS product_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     materials m
     INNER JOIN unit_types ut ON m.unit_id = ut.unit_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
     INNER JOIN material_names mn ON m.material_id = mn.material_id
     LEFT JOIN product_materials pm ON m.material_id = pm.material_id
     LEFT JOIN products p ON pm.product_id = p.product_id
@@ -23,15 +28,20 @@ SELECT
     m.material_id,
     m.unit_id,
     m.description,
+    m.yield_percent,
     ut.unit,
     mn.name AS material_name,
     pm.quantity,
     pm.quantity_text,
     p.product_id,
-    p.name AS product_name
+    p.name AS product_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     materials m
     INNER JOIN unit_types ut ON m.unit_id = ut.unit_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
     INNER JOIN material_names mn ON m.material_id = mn.material_id
     and is_primary = TRUE
     LEFT JOIN product_materials pm ON m.material_id = pm.material_id
@@ -76,11 +86,16 @@ SELECT
     materials.material_id,
     materials.unit_id,
     materials.description,
+    materials.yield_percent,
     unit_types.unit AS unit,
-    product_materials.quantity AS quantity
+    product_materials.quantity AS quantity,
+    material_categories.category_id,
+    categories.name AS category_name
 FROM
     materials
     INNER JOIN unit_types ON materials.unit_id = unit_types.unit_id
+    LEFT JOIN material_categories ON material_categories.material_id = materials.material_id
+    LEFT JOIN categories ON categories.category_id = material_categories.category_id
     LEFT JOIN product_materials ON product_materials.material_id = materials.material_id
 WHERE
     materials.material_id = ?;
@@ -176,4 +191,21 @@ where
 -- name: DeleteAllMaterialProducts :exec
 DELETE FROM product_materials
 WHERE
-    material_id = ?;
\ No newline at end of file
+    material_id = ?;
+
+-- name: SetMaterialYield :exec
+UPDATE materials
+SET
+    yield_percent = ?
+WHERE
+    material_id = ?;
+
+-- name: GetMaterialLineScraps :many
+SELECT
+    product_id,
+    scrap_percent
+FROM
+    product_materials
+WHERE
+    material_id = ?
+    AND scrap_percent IS NOT NULL;
@@ -0,0 +1,121 @@
+-- name: InsertProductionOrder :one
+INSERT INTO
+    production_orders (
+        product_id,
+        product_name,
+        quantity,
+        location_id,
+        created_on,
+        comment
+    )
+VALUES
+    (?, ?, ?, ?, ?, ?) RETURNING order_id;
+
+-- name: InsertProductionOrderLine :exec
+INSERT INTO
+    production_order_lines (
+        order_id,
+        material_id,
+        material_name,
+        unit,
+        bom_quantity,
+        norm
+    )
+VALUES
+    (?, ?, ?, ?, ?, ?);
+
+-- name: GetProductionOrders :many
+-- Latest orders first, overused is number of lines whose actual consumption exceeds norm.
+SELECT
+    o.order_id,
+    o.product_id,
+    o.product_name,
+    o.quantity,
+    o.location_id,
+    l.name AS location_name,
+    o.created_on,
+    o.completed_on,
+    o.comment,
+    CAST(
+        (
+            SELECT
+                COUNT(*)
+            FROM
+                production_order_lines pl
+            WHERE
+                pl.order_id = o.order_id
+                AND pl.actual > pl.norm
+        ) AS INTEGER
+    ) AS overused
+FROM
+    production_orders o
+    INNER JOIN locations l ON l.location_id = o.location_id
+ORDER BY
+    o.order_id DESC;
+
+-- name: GetProductionOrder :one
+SELECT
+    o.order_id,
+    o.product_id,
+    o.product_name,
+    o.quantity,
+    o.location_id,
+    l.name AS location_name,
+    o.created_on,
+    o.completed_on,
+    o.comment
+FROM
+    production_orders o
+    INNER JOIN locations l ON l.location_id = o.location_id
+WHERE
+    o.order_id = ?;
+
+-- name: GetProductionOrderLines :many
+SELECT
+    *
+FROM
+    production_order_lines
+WHERE
+    order_id = ?
+ORDER BY
+    line_id;
+
+-- name: SetOrderLineActual :exec
+UPDATE production_order_lines
+SET
+    actual = ?
+WHERE
+    line_id = ?
+    AND order_id = ?;
+
+-- name: CompleteProductionOrder :execrows
+UPDATE production_orders
+SET
+    completed_on = ?
+WHERE
+    order_id = ?
+    AND completed_on IS NULL;
+
+-- name: DeleteProductionOrder :execrows
+-- Completed order has movements and can't be deleted.
+DELETE FROM production_orders
+WHERE
+    order_id = ?
+    AND completed_on IS NULL;
+
+-- name: GetOpenOrderDemand :many
+-- Norms of open orders not issued yet, summed by material and unit.
+SELECT
+    pl.material_id,
+    pl.unit,
+    CAST(SUM(pl.norm) AS REAL) AS quantity
+FROM
+    production_order_lines pl
+    INNER JOIN production_orders o ON o.order_id = pl.order_id
+WHERE
+    o.completed_on IS NULL
+    AND pl.material_id IS NOT NULL
+    AND pl.norm IS NOT NULL
+GROUP BY
+    pl.material_id,
+    pl.unit;
@@ -39,12 +39,17 @@ SELECT
     ut.unit AS unit,
     pm.quantity AS quantity,
     pm.quantity_text AS quantity_text,
-    mn.name AS material_name
+    pm.scrap_percent AS scrap_percent,
+    mn.name AS material_name,
+    mc.category_id,
+    c.name AS category_name
 FROM
     product_materials pm
     INNER JOIN materials m ON m.material_id = pm.material_id
     INNER JOIN unit_types ut ON ut.unit_id = m.unit_id
     INNER JOIN material_names mn ON mn.material_id = m.material_id
+    LEFT JOIN material_categories mc ON mc.material_id = m.material_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
 WHERE
     pm.product_id = ?
     and mn.is_primary = TRUE;
@@ -56,9 +61,9 @@ WHERE
 
 -- name: AddProductMaterial :exec
 insert into
-    product_materials (product_id, material_id, quantity, quantity_text)
+    product_materials (product_id, material_id, quantity, quantity_text, scrap_percent)
 values
-    (?, ?, ?, ?);
+    (?, ?, ?, ?, ?);
 
 -- name: DeleteProductMaterial :exec
 delete from product_materials
@@ -0,0 +1,42 @@
+-- name: GetScenarios :many
+SELECT
+    *
+FROM
+    scenarios
+ORDER BY
+    name;
+
+-- name: GetScenario :one
+SELECT
+    *
+FROM
+    scenarios
+WHERE
+    scenario_id = ?;
+
+-- name: SaveScenario :one
+-- Scenario with the same name is replaced, it stays planned when it was.
+INSERT INTO
+    scenarios (name, query, bom_revision, result)
+VALUES
+    (?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE
+SET
+    query = excluded.query,
+    bom_revision = excluded.bom_revision,
+    result = excluded.result,
+    saved_at = CURRENT_TIMESTAMP
+RETURNING
+    *;
+
+-- name: SetScenarioPlanned :execrows
+UPDATE scenarios
+SET
+    planned = ?
+WHERE
+    scenario_id = ?;
+
+-- name: DeleteScenario :exec
+DELETE FROM scenarios
+WHERE
+    scenario_id = ?;
@@ -1,68 +1,611 @@
--- name: SearchAll :many
-SELECT
-    f.type,
-    f.ref_id,
-    COALESCE(
-        CASE
-            WHEN f.type = 'product' THEN p.name
-            WHEN f.type = 'material' THEN mn.name
-        END,
-        ''
-    ) AS display_name,
-    f.text,
-    f.score
-FROM
-    fts f
+-- name: Search :many
+-- Entries of attached files are returned as hits of their owners, file_id is 0 for other hits.
+-- Every item is returned once by its best hit, own text wins over file with the same score.
+-- matched_terms is output of highlight() over terms column with terms matched by query,
+-- see package internal/search for markers.
+-- ID filters are lists like ",1,2,", empty string disables filter. Unit, product and category filters
+-- leave only materials, material filter leaves only products that use the materials.
+-- Filters must be the same as in GetSearchFacets.
+WITH RECURSIVE
+    -- Selected categories with all their subcategories, ID 0 selects materials without category.
+    selected_categories (category_id) AS (
+        SELECT
+            category_id
+        FROM
+            categories
+        WHERE
+            instr (sqlc.arg(category_ids), ',' || category_id || ',') > 0
+        UNION
+        SELECT
+            c.category_id
+        FROM
+            categories c
+            INNER JOIN selected_categories s ON c.parent_id = s.category_id
+    ),
+    hits AS (
+        SELECT
+            CASE
+                    WHEN t.type <> 'file' THEN t.type
+                    WHEN EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            files_materials x
+                        WHERE
+                            x.file_id = t.ref_id
+                    ) THEN 'material'
+                    ELSE 'product'
+                END AS type,
+                CASE
+                    WHEN t.type <> 'file' THEN t.ref_id
+                    ELSE COALESCE(
+                        (
+                            SELECT
+                                x.material_id
+                            FROM
+                                files_materials x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        ),
+                        (
+                            SELECT
+                                x.product_id
+                            FROM
+                                files_products x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        )
+                    )
+                END AS ref_id,
+                CASE
+                    WHEN t.type = 'file' THEN t.ref_id
+                    ELSE 0
+                END AS file_id,
+                t.text,
+                highlight (fts_table, 3, char(2), char(3)) AS matched_terms,
+                bm25 (fts_table) AS score
+        FROM
+            fts_table t
+        WHERE
+            t.terms MATCH sqlc.arg(terms)
+    ),
+    ranked AS (
+        SELECT
+            h.type,
+            h.ref_id,
+            h.file_id,
+            h.text,
+            h.matched_terms,
+            h.score,
+            ROW_NUMBER() OVER (
+                PARTITION BY
+                    h.type,
+                    h.ref_id
+                ORDER BY
+                    h.score,
+                    h.file_id
+            ) AS hit_rank
+        FROM
+            hits h
+    )
+SELECT
+    CAST(f.type AS TEXT) AS type,
+    CAST(f.ref_id AS INTEGER) AS ref_id,
+    CAST(COALESCE(p.name, mn.name, '') AS TEXT) AS display_name,
+    CAST(COALESCE(p.description, m.description, '') AS TEXT) AS description,
+    CAST(
+        COALESCE(
+            (
+                SELECT
+                    group_concat (o.name, ', ')
+                FROM
+                    material_names o
+                WHERE
+                    o.material_id = m.material_id
+                    AND o.is_primary = 0
+            ),
+            ''
+        ) AS TEXT
+    ) AS other_names,
+    CAST(f.file_id AS INTEGER) AS file_id,
+    CAST(COALESCE(fl.name, '') AS TEXT) AS file_name,
+    CAST(f.text AS TEXT) AS text,
+    CAST(f.matched_terms AS TEXT) AS matched_terms
+FROM
+    ranked f
     LEFT JOIN products p ON f.type = 'product'
     AND f.ref_id = p.product_id
+    LEFT JOIN materials m ON f.type = 'material'
+    AND f.ref_id = m.material_id
     LEFT JOIN material_names mn ON f.type = 'material'
     AND f.ref_id = mn.material_id
     AND mn.is_primary = 1
+    LEFT JOIN files fl ON fl.file_id = f.file_id
 WHERE
-    f.text MATCH ?
+    -- File without owner is not shown.
+    f.ref_id IS NOT NULL
+    AND f.hit_rank = 1
+    AND (
+        CAST(sqlc.arg(type) AS TEXT) = ''
+        OR f.type = sqlc.arg(type)
+    )
+    AND (
+        CAST(sqlc.arg(unit_ids) AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    materials fm
+                WHERE
+                    fm.material_id = f.ref_id
+                    AND instr (sqlc.arg(unit_ids), ',' || fm.unit_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(sqlc.arg(product_ids) AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    product_materials fpm
+                WHERE
+                    fpm.material_id = f.ref_id
+                    AND instr (sqlc.arg(product_ids), ',' || fpm.product_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(sqlc.arg(material_ids) AS TEXT) = ''
+        OR (
+            f.type = 'product'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    product_materials fpm
+                WHERE
+                    fpm.product_id = f.ref_id
+                    AND instr (sqlc.arg(material_ids), ',' || fpm.material_id || ',') > 0
+            )
+        )
+    )
+    AND (
+        CAST(sqlc.arg(category_ids) AS TEXT) = ''
+        OR (
+            f.type = 'material'
+            AND (
+                EXISTS (
+                    SELECT
+                        1
+                    FROM
+                        material_categories fmc
+                        INNER JOIN selected_categories sc ON sc.category_id = fmc.category_id
+                    WHERE
+                        fmc.material_id = f.ref_id
+                )
+                OR (
+                    instr (sqlc.arg(category_ids), ',0,') > 0
+                    AND NOT EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            material_categories fmc
+                        WHERE
+                            fmc.material_id = f.ref_id
+                    )
+                )
+            )
+        )
+    )
+    AND (
+        CAST(sqlc.arg(has_files) AS BOOLEAN) = FALSE
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                files_materials ffm
+            WHERE
+                f.type = 'material'
+                AND ffm.material_id = f.ref_id
+            UNION ALL
+            SELECT
+                1
+            FROM
+                files_products ffp
+            WHERE
+                f.type = 'product'
+                AND ffp.product_id = f.ref_id
+        )
+    )
 ORDER BY
     f.score ASC
 LIMIT
-    ?;
+    sqlc.arg(limit)
+OFFSET
+    sqlc.arg(offset);
 
--- name: SearchMaterials :many
-SELECT
-    f.ref_id,
-    mn.name AS display_name,
-    f.text,
-    u.unit,
-    u.unit_id,
-    COALESCE(pm.quantity, pm.quantity_text) AS quantity,
-    score
-FROM fts f
-LEFT JOIN materials m
-    ON f.ref_id = m.material_id
-LEFT JOIN material_names mn
-    ON f.ref_id = mn.material_id AND mn.is_primary = 1
-LEFT JOIN product_materials pm
-    ON m.material_id = pm.material_id
-INNER JOIN unit_types u
-    ON m.unit_id = u.unit_id
+-- name: GetSearchFacets :many
+-- Counts of found items by type, by unit, by product using found materials, by category
+-- of found materials and count of found items with attached files. Type filter is not applied,
+-- so counts of all types are known on every tab of search page.
+-- Category counts are by category assigned directly, tree totals are summed by the app.
+WITH RECURSIVE
+    -- Selected categories with all their subcategories, ID 0 selects materials without category.
+    selected_categories (category_id) AS (
+        SELECT
+            category_id
+        FROM
+            categories
+        WHERE
+            instr (sqlc.arg(category_ids), ',' || category_id || ',') > 0
+        UNION
+        SELECT
+            c.category_id
+        FROM
+            categories c
+            INNER JOIN selected_categories s ON c.parent_id = s.category_id
+    ),
+    hits AS (
+        SELECT
+            CASE
+                    WHEN t.type <> 'file' THEN t.type
+                    WHEN EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            files_materials x
+                        WHERE
+                            x.file_id = t.ref_id
+                    ) THEN 'material'
+                    ELSE 'product'
+                END AS type,
+                CASE
+                    WHEN t.type <> 'file' THEN t.ref_id
+                    ELSE COALESCE(
+                        (
+                            SELECT
+                                x.material_id
+                            FROM
+                                files_materials x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        ),
+                        (
+                            SELECT
+                                x.product_id
+                            FROM
+                                files_products x
+                            WHERE
+                                x.file_id = t.ref_id
+                            LIMIT
+                                1
+                        )
+                    )
+                END AS ref_id
+        FROM
+            fts_table t
+        WHERE
+            t.terms MATCH sqlc.arg(terms)
+    ),
+    -- Item found by own text and by attached files is counted once.
+    found AS (
+        SELECT DISTINCT
+            f.type,
+            f.ref_id
+        FROM
+            hits f
+        WHERE
+            f.ref_id IS NOT NULL
+            AND (
+                CAST(sqlc.arg(unit_ids) AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            materials fm
+                        WHERE
+                            fm.material_id = f.ref_id
+                            AND instr (sqlc.arg(unit_ids), ',' || fm.unit_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(sqlc.arg(product_ids) AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            product_materials fpm
+                        WHERE
+                            fpm.material_id = f.ref_id
+                            AND instr (sqlc.arg(product_ids), ',' || fpm.product_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(sqlc.arg(material_ids) AS TEXT) = ''
+                OR (
+                    f.type = 'product'
+                    AND EXISTS (
+                        SELECT
+                            1
+                        FROM
+                            product_materials fpm
+                        WHERE
+                            fpm.product_id = f.ref_id
+                            AND instr (sqlc.arg(material_ids), ',' || fpm.material_id || ',') > 0
+                    )
+                )
+            )
+            AND (
+                CAST(sqlc.arg(category_ids) AS TEXT) = ''
+                OR (
+                    f.type = 'material'
+                    AND (
+                        EXISTS (
+                            SELECT
+                                1
+                            FROM
+                                material_categories fmc
+                                INNER JOIN selected_categories sc ON sc.category_id = fmc.category_id
+                            WHERE
+                                fmc.material_id = f.ref_id
+                        )
+                        OR (
+                            instr (sqlc.arg(category_ids), ',0,') > 0
+                            AND NOT EXISTS (
+                                SELECT
+                                    1
+                                FROM
+                                    material_categories fmc
+                                WHERE
+                                    fmc.material_id = f.ref_id
+                            )
+                        )
+                    )
+                )
+            )
+            AND (
+                CAST(sqlc.arg(has_files) AS BOOLEAN) = FALSE
+                OR EXISTS (
+                    SELECT
+                        1
+                    FROM
+                        files_materials ffm
+                    WHERE
+                        f.type = 'material'
+                        AND ffm.material_id = f.ref_id
+                    UNION ALL
+                    SELECT
+                        1
+                    FROM
+                        files_products ffp
+                    WHERE
+                        f.type = 'product'
+                        AND ffp.product_id = f.ref_id
+                )
+            )
+    )
+SELECT
+    'type' AS facet,
+    CAST(0 AS INTEGER) AS id,
+    CAST(found.type AS TEXT) AS name,
+    COUNT(*) AS count
+FROM
+    found
+GROUP BY
+    found.type
+UNION ALL
+SELECT
+    'unit' AS facet,
+    u.unit_id AS id,
+    u.unit AS name,
+    COUNT(*) AS count
+FROM
+    found
+    INNER JOIN materials m ON found.type = 'material'
+    AND m.material_id = found.ref_id
+    INNER JOIN unit_types u ON u.unit_id = m.unit_id
+GROUP BY
+    u.unit_id
+UNION ALL
+SELECT
+    'product' AS facet,
+    p.product_id AS id,
+    p.name AS name,
+    COUNT(DISTINCT found.ref_id) AS count
+FROM
+    found
+    INNER JOIN product_materials pm ON found.type = 'material'
+    AND pm.material_id = found.ref_id
+    INNER JOIN products p ON p.product_id = pm.product_id
+GROUP BY
+    p.product_id
+UNION ALL
+SELECT
+    'category' AS facet,
+    CAST(COALESCE(mc.category_id, 0) AS INTEGER) AS id,
+    CAST(COALESCE(c.name, '') AS TEXT) AS name,
+    COUNT(*) AS count
+FROM
+    found
+    LEFT JOIN material_categories mc ON mc.material_id = found.ref_id
+    LEFT JOIN categories c ON c.category_id = mc.category_id
 WHERE
-    f.text MATCH sqlc.arg(query)
-    AND f.type = 'material'
-ORDER BY score ASC
-LIMIT sqlc.arg(limit);
+    found.type = 'material'
+GROUP BY
+    COALESCE(mc.category_id, 0)
+UNION ALL
+SELECT
+    'files' AS facet,
+    CAST(0 AS INTEGER) AS id,
+    '' AS name,
+    COUNT(*) AS count
+FROM
+    found
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            files_materials fm
+        WHERE
+            found.type = 'material'
+            AND fm.material_id = found.ref_id
+        UNION ALL
+        SELECT
+            1
+        FROM
+            files_products fp
+        WHERE
+            found.type = 'product'
+            AND fp.product_id = found.ref_id
+    )
+ORDER BY
+    facet,
+    count DESC,
+    name;
 
+-- name: ClearSearchIndex :exec
+DELETE FROM fts_table;
 
--- name: SearchProducts :many
+-- name: IndexAll :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
 SELECT
-    f.ref_id,
-    p.name AS display_name,
-    f.text,
-    f.score
+    type,
+    ref_id,
+    text,
+    search_terms (text)
 FROM
-    fts f
-    LEFT JOIN products p ON f.ref_id = p.product_id
+    search_source;
+
+-- name: IndexAllFull :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    type,
+    ref_id,
+    full_text,
+    search_terms (full_text)
+FROM
+    search_source;
+
+-- name: DeleteSearchItem :exec
+DELETE FROM fts_table
 WHERE
-    f.text MATCH ?
-    AND f.type = 'product'
-ORDER BY
-    f.score ASC
-LIMIT
-    ?;
\ No newline at end of file
+    type = sqlc.arg(type)
+    AND ref_id = CAST(sqlc.arg(ref_id) AS INTEGER);
+
+-- name: IndexSearchItemFull :exec
+INSERT INTO
+    fts_table (type, ref_id, text, terms)
+SELECT
+    s.type,
+    s.ref_id,
+    s.full_text,
+    search_terms (s.full_text)
+FROM
+    search_source s
+WHERE
+    s.type = sqlc.arg(type)
+    AND s.ref_id = sqlc.arg(ref_id);
+
+-- name: GetSearchIndex :many
+SELECT
+    type,
+    ref_id,
+    text,
+    terms
+FROM
+    fts_table;
+
+-- name: GetSearchVocabulary :many
+SELECT
+    term
+FROM
+    fts_vocab;
+
+-- name: GetSearchSource :many
+SELECT
+    type,
+    ref_id,
+    text,
+    full_text
+FROM
+    search_source;
+
+-- name: GetAppIndexing :one
+SELECT
+    app_indexing
+FROM
+    search_settings
+WHERE
+    id = 1;
+
+-- name: SetAppIndexing :exec
+UPDATE search_settings
+SET
+    app_indexing = ?
+WHERE
+    id = 1;
+
+-- name: GetProductMaterialIDs :many
+SELECT
+    material_id
+FROM
+    product_materials
+WHERE
+    product_id = ?;
+
+-- name: SaveFileText :exec
+INSERT INTO
+    file_texts (file_id, text)
+VALUES
+    (?, ?)
+ON CONFLICT (file_id) DO UPDATE
+SET
+    text = excluded.text;
+
+-- name: GetFilesWithoutText :many
+SELECT
+    f.file_id,
+    f.path
+FROM
+    files f
+    LEFT JOIN file_texts ft ON ft.file_id = f.file_id
+WHERE
+    ft.file_id IS NULL
+    AND f.file_type = 'document';
+
+-- name: GetFileOwners :many
+SELECT
+    'material' AS type,
+    fm.material_id AS ref_id
+FROM
+    files_materials fm
+WHERE
+    fm.file_id = sqlc.arg(file_id)
+UNION ALL
+SELECT
+    'product' AS type,
+    fp.product_id AS ref_id
+FROM
+    files_products fp
+WHERE
+    fp.file_id = sqlc.arg(file_id);
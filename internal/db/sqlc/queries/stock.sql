@@ -0,0 +1,169 @@
+-- name: GetLocations :many
+SELECT
+    *
+FROM
+    locations
+ORDER BY
+    name;
+
+-- name: InsertLocation :one
+INSERT INTO
+    locations (name)
+VALUES
+    (?) RETURNING *;
+
+-- name: UpdateLocation :exec
+UPDATE locations
+SET
+    name = ?
+WHERE
+    location_id = ?;
+
+-- name: DeleteLocation :exec
+DELETE FROM locations
+WHERE
+    location_id = ?;
+
+-- name: InsertStockMovement :exec
+INSERT INTO
+    stock_movements (
+        material_id,
+        unit,
+        kind,
+        quantity,
+        from_location_id,
+        to_location_id,
+        moved_on,
+        comment,
+        order_id
+    )
+VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetStockBalances :many
+-- Transfer is joined to both its locations, it goes out of one and into the other.
+SELECT
+    sm.material_id,
+    mn.name AS material_name,
+    sm.unit,
+    l.location_id,
+    l.name AS location_name,
+    CAST(
+        SUM(
+            CASE
+                WHEN sm.to_location_id = l.location_id THEN sm.quantity
+                ELSE - sm.quantity
+            END
+        ) AS REAL
+    ) AS balance
+FROM
+    stock_movements sm
+    INNER JOIN locations l ON l.location_id IN (sm.from_location_id, sm.to_location_id)
+    INNER JOIN material_names mn ON mn.material_id = sm.material_id
+    AND mn.is_primary = 1
+GROUP BY
+    sm.material_id,
+    sm.unit,
+    l.location_id
+ORDER BY
+    mn.name,
+    sm.unit,
+    l.name;
+
+-- name: GetLocationBalance :one
+SELECT
+    CAST(
+        COALESCE(
+            SUM(
+                CASE
+                    WHEN to_location_id = sqlc.arg(location_id) THEN quantity
+                    ELSE - quantity
+                END
+            ),
+            0
+        ) AS REAL
+    ) AS balance
+FROM
+    stock_movements
+WHERE
+    material_id = sqlc.arg(material_id)
+    AND unit = sqlc.arg(unit)
+    AND (
+        from_location_id = sqlc.arg(location_id)
+        OR to_location_id = sqlc.arg(location_id)
+    );
+
+-- name: GetStockMovements :many
+-- Latest movements, of all materials when material_id is NULL.
+SELECT
+    sm.movement_id,
+    sm.material_id,
+    mn.name AS material_name,
+    sm.unit,
+    sm.kind,
+    sm.quantity,
+    sm.from_location_id,
+    fl.name AS from_location_name,
+    sm.to_location_id,
+    tl.name AS to_location_name,
+    sm.moved_on,
+    sm.comment,
+    sm.order_id
+FROM
+    stock_movements sm
+    INNER JOIN material_names mn ON mn.material_id = sm.material_id
+    AND mn.is_primary = 1
+    LEFT JOIN locations fl ON fl.location_id = sm.from_location_id
+    LEFT JOIN locations tl ON tl.location_id = sm.to_location_id
+WHERE
+    sqlc.narg(material_id) IS NULL
+    OR sm.material_id = sqlc.narg(material_id)
+ORDER BY
+    sm.moved_on DESC,
+    sm.movement_id DESC
+LIMIT
+    sqlc.arg(max_rows);
+
+-- name: GetStockLevels :many
+SELECT
+    *
+FROM
+    stock_levels
+ORDER BY
+    material_id;
+
+-- name: SetStockLevel :exec
+INSERT INTO
+    stock_levels (
+        material_id,
+        minimum,
+        reorder_point,
+        reorder_quantity
+    )
+VALUES
+    (?, ?, ?, ?)
+ON CONFLICT (material_id) DO UPDATE
+SET
+    minimum = excluded.minimum,
+    reorder_point = excluded.reorder_point,
+    reorder_quantity = excluded.reorder_quantity;
+
+-- name: DeleteStockLevel :exec
+DELETE FROM stock_levels
+WHERE
+    material_id = ?;
+
+-- name: GetDigestSentOn :one
+SELECT
+    last_sent_on
+FROM
+    digest_state
+WHERE
+    id = 1;
+
+-- name: SetDigestSentOn :exec
+UPDATE digest_state
+SET
+    last_sent_on = ?
+WHERE
+    id = 1;
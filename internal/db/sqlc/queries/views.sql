@@ -0,0 +1,28 @@
+-- name: GetSavedViews :many
+SELECT
+    *
+FROM
+    saved_views
+WHERE
+    kind = ?
+ORDER BY
+    name;
+
+-- name: SaveView :one
+-- View with the same name is replaced.
+INSERT INTO
+    saved_views (kind, name, query)
+VALUES
+    (?, ?, ?)
+ON CONFLICT (kind, name) DO UPDATE
+SET
+    query = excluded.query
+RETURNING
+    *;
+
+-- name: DeleteSavedView :one
+DELETE FROM saved_views
+WHERE
+    view_id = ?
+RETURNING
+    kind;
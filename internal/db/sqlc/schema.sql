@@ -3,7 +3,11 @@ CREATE TABLE
   materials (
     material_id INTEGER PRIMARY KEY AUTOINCREMENT,
     unit_id INT NOT NULL REFERENCES unit_types (unit_id) ON DELETE SET NULL,
-    description TEXT
+    description TEXT,
+    yield_percent REAL NOT NULL DEFAULT 100 CHECK (
+      yield_percent > 0
+      AND yield_percent <= 100
+    )
   );
 
 CREATE TABLE
@@ -33,6 +37,10 @@ CREATE TABLE
     material_id INT REFERENCES materials (material_id) ON DELETE RESTRICT,
     quantity NUMERIC(12, 3) CHECK (quantity > 0),
     quantity_text VARCHAR(50),
+    scrap_percent REAL CHECK (
+      scrap_percent >= 0
+      AND scrap_percent < 100
+    ),
     PRIMARY KEY (product_id, material_id)
   );
 
@@ -69,7 +77,214 @@ CREATE TABLE
 CREATE VIRTUAL TABLE fts USING fts5 (
   type UNINDEXED,
   ref_id UNINDEXED,
-  text,
+  text UNINDEXED,
+  terms,
   score,
   tokenize = 'unicode61'
-);
\ No newline at end of file
+);
+
+-- Real full text search table, fts above is a view over it with bm25 score.
+CREATE VIRTUAL TABLE fts_table USING fts5 (
+  type UNINDEXED,
+  ref_id UNINDEXED,
+  text UNINDEXED,
+  terms,
+  tokenize = 'unicode61'
+);
+
+-- fts5vocab table over fts_table, sqlc doesn't support fts5vocab module.
+CREATE TABLE
+  fts_vocab (
+    term TEXT NOT NULL,
+    doc INTEGER NOT NULL,
+    cnt INTEGER NOT NULL
+  );
+
+CREATE TABLE
+  search_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    app_indexing BOOLEAN NOT NULL DEFAULT FALSE
+  );
+
+CREATE TABLE
+  file_texts (
+    file_id INTEGER PRIMARY KEY REFERENCES files (file_id) ON DELETE CASCADE,
+    text TEXT NOT NULL
+  );
+
+-- Simplified definition of view from migrations 0010 and 0012, sqlc only needs its columns.
+CREATE VIEW search_source AS
+SELECT
+  'material' AS type,
+  material_id AS ref_id,
+  description AS text,
+  description AS full_text
+FROM
+  materials;
+
+CREATE TABLE
+  saved_views (
+    view_id INTEGER PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('materials', 'products', 'search')),
+    name TEXT NOT NULL,
+    query TEXT NOT NULL,
+    UNIQUE (kind, name)
+  );
+
+CREATE TABLE
+  categories (
+    category_id INTEGER PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories (category_id),
+    name TEXT NOT NULL
+  );
+
+CREATE TABLE
+  material_categories (
+    material_id INTEGER PRIMARY KEY REFERENCES materials (material_id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories (category_id) ON DELETE CASCADE
+  );
+
+CREATE TABLE
+  attributes (
+    attribute_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('text', 'number', 'enum', 'bool')),
+    unit TEXT NOT NULL DEFAULT '',
+    options TEXT NOT NULL DEFAULT '',
+    category_id INTEGER REFERENCES categories (category_id) ON DELETE CASCADE
+  );
+
+CREATE TABLE
+  material_attributes (
+    material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attributes (attribute_id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (material_id, attribute_id)
+  );
+
+CREATE TABLE
+  product_attributes (
+    product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attributes (attribute_id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (product_id, attribute_id)
+  );
+
+CREATE TABLE
+  material_tags (
+    material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (material_id, tag)
+  );
+
+CREATE TABLE
+  product_tags (
+    product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (product_id, tag)
+  );
+
+CREATE TABLE
+  densities (
+    density_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    density REAL NOT NULL CHECK (density > 0)
+  );
+
+CREATE TABLE
+  product_cuts (
+    cut_id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+    length REAL NOT NULL CHECK (length > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+  );
+
+CREATE TABLE
+  product_blanks (
+    blank_id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE CASCADE,
+    width REAL NOT NULL CHECK (width > 0),
+    height REAL NOT NULL CHECK (height > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+  );
+
+CREATE TABLE
+  scenarios (
+    scenario_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    query TEXT NOT NULL,
+    bom_revision TEXT NOT NULL,
+    result TEXT NOT NULL,
+    saved_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    planned BOOLEAN NOT NULL DEFAULT FALSE
+  );
+
+CREATE TABLE
+  locations (
+    location_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+  );
+
+CREATE TABLE
+  stock_movements (
+    movement_id INTEGER PRIMARY KEY,
+    material_id INTEGER NOT NULL REFERENCES materials (material_id) ON DELETE RESTRICT,
+    unit TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('receipt', 'issue', 'transfer', 'adjustment')),
+    quantity REAL NOT NULL CHECK (quantity > 0),
+    from_location_id INTEGER REFERENCES locations (location_id) ON DELETE RESTRICT,
+    to_location_id INTEGER REFERENCES locations (location_id) ON DELETE RESTRICT,
+    moved_on TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    order_id INTEGER,
+    CHECK (
+      (kind = 'receipt' AND from_location_id IS NULL AND to_location_id IS NOT NULL)
+      OR (kind = 'issue' AND from_location_id IS NOT NULL AND to_location_id IS NULL)
+      OR (kind = 'transfer' AND from_location_id IS NOT NULL AND to_location_id IS NOT NULL AND from_location_id <> to_location_id)
+      OR (kind = 'adjustment' AND (from_location_id IS NULL) <> (to_location_id IS NULL))
+    )
+  );
+
+CREATE INDEX stock_movements_material ON stock_movements (material_id);
+
+CREATE TABLE
+  production_orders (
+    order_id INTEGER PRIMARY KEY,
+    product_id INTEGER REFERENCES products (product_id) ON DELETE SET NULL,
+    product_name TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    location_id INTEGER NOT NULL REFERENCES locations (location_id) ON DELETE RESTRICT,
+    created_on TEXT NOT NULL,
+    completed_on TEXT,
+    comment TEXT NOT NULL DEFAULT ''
+  );
+
+CREATE TABLE
+  production_order_lines (
+    line_id INTEGER PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES production_orders (order_id) ON DELETE CASCADE,
+    material_id INTEGER REFERENCES materials (material_id) ON DELETE SET NULL,
+    material_name TEXT NOT NULL,
+    unit TEXT NOT NULL,
+    bom_quantity TEXT NOT NULL,
+    norm REAL CHECK (norm > 0),
+    actual REAL CHECK (actual >= 0)
+  );
+
+CREATE INDEX production_order_lines_order ON production_order_lines (order_id);
+
+CREATE TABLE
+  stock_levels (
+    material_id INTEGER PRIMARY KEY REFERENCES materials (material_id) ON DELETE CASCADE,
+    minimum REAL NOT NULL DEFAULT 0 CHECK (minimum >= 0),
+    reorder_point REAL NOT NULL DEFAULT 0 CHECK (reorder_point >= 0),
+    reorder_quantity REAL NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0)
+  );
+
+CREATE TABLE
+  digest_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_sent_on TEXT NOT NULL DEFAULT ''
+  );
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+var ErrInsufficientStock = errors.New("недостаточно остатка на складе")
+
+// stockTolerance absorbs rounding of decimal quantities when balance is compared with quantity to take.
+const stockTolerance = 1e-9
+
+// GetLocations returns locations ordered by name.
+func (r *Repository) GetLocations(ctx context.Context) ([]models.Location, error) {
+	rows, err := r.queries.GetLocations(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	locations := make([]models.Location, 0, len(rows))
+	for _, row := range rows {
+		locations = append(locations, models.Location{ID: row.LocationID, Name: row.Name})
+	}
+	return locations, nil
+}
+
+// InsertLocation creates location.
+func (r *Repository) InsertLocation(ctx context.Context, name string) (models.Location, error) {
+	row, err := r.queries.InsertLocation(ctx, name)
+	if err != nil {
+		return models.Location{}, parseError(err)
+	}
+	return models.Location{ID: row.LocationID, Name: row.Name}, nil
+}
+
+// UpdateLocation renames location.
+func (r *Repository) UpdateLocation(ctx context.Context, location models.Location) error {
+	err := r.queries.UpdateLocation(ctx, db.UpdateLocationParams{Name: location.Name, LocationID: location.ID})
+	if err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+// DeleteLocation deletes location. Location with movements or orders can't be deleted and ErrInUse is returned.
+func (r *Repository) DeleteLocation(ctx context.Context, id int64) error {
+	err := r.queries.DeleteLocation(ctx, id)
+	if isForeignKeyError(err) {
+		return ErrInUse
+	}
+	if err != nil {
+		return parseError(err)
+	}
+	return nil
+}
+
+// AddStockMovements records movements in one transaction. Movement out of location must not take more
+// than its balance, otherwise nothing is recorded and ErrInsufficientStock is returned.
+func (r *Repository) AddStockMovements(ctx context.Context, movements []models.StockMovement) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		return addStockMovements(ctx, q, movements)
+	})
+	if errors.Is(err, ErrInsufficientStock) {
+		return err
+	}
+	return parseError(err)
+}
+
+// addStockMovements records movements checking balances of locations they go out of.
+func addStockMovements(ctx context.Context, q *db.Queries, movements []models.StockMovement) error {
+	for _, m := range movements {
+		if m.From.ID != 0 {
+			balance, err := q.GetLocationBalance(ctx, db.GetLocationBalanceParams{
+				LocationID: locationID(m.From),
+				MaterialID: m.MaterialID,
+				Unit:       m.Unit,
+			})
+			if err != nil {
+				return err
+			}
+			if m.Quantity > balance+stockTolerance {
+				return fmt.Errorf("%w: %s, %s — остаток %s %s", ErrInsufficientStock,
+					m.MaterialName, m.From.Name, strconv.FormatFloat(balance, 'f', -1, 64), m.Unit)
+			}
+		}
+		err := q.InsertStockMovement(ctx, db.InsertStockMovementParams{
+			MaterialID:     m.MaterialID,
+			Unit:           m.Unit,
+			Kind:           m.Kind,
+			Quantity:       m.Quantity,
+			FromLocationID: locationID(m.From),
+			ToLocationID:   locationID(m.To),
+			MovedOn:        m.Date,
+			Comment:        m.Comment,
+			OrderID:        sql.NullInt64{Int64: m.OrderID, Valid: m.OrderID != 0},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStockBalances returns non-zero balances of materials by unit and location, ordered by name of material.
+func (r *Repository) GetStockBalances(ctx context.Context) ([]models.StockBalance, error) {
+	rows, err := r.queries.GetStockBalances(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	balances := make([]models.StockBalance, 0, len(rows))
+	for _, row := range rows {
+		if row.Balance > -stockTolerance && row.Balance < stockTolerance {
+			continue
+		}
+		balances = append(balances, models.StockBalance{
+			MaterialID:   row.MaterialID,
+			MaterialName: row.MaterialName,
+			Unit:         row.Unit,
+			Location:     models.Location{ID: row.LocationID, Name: row.LocationName},
+			Quantity:     row.Balance,
+		})
+	}
+	return balances, nil
+}
+
+// GetStockMovements returns at most limit latest movements, of all materials when materialID is 0.
+func (r *Repository) GetStockMovements(ctx context.Context, materialID, limit int64) ([]models.StockMovement, error) {
+	var material any
+	if materialID != 0 {
+		material = materialID
+	}
+	rows, err := r.queries.GetStockMovements(ctx, db.GetStockMovementsParams{MaterialID: material, MaxRows: limit})
+	if err != nil {
+		return nil, parseError(err)
+	}
+	movements := make([]models.StockMovement, 0, len(rows))
+	for _, row := range rows {
+		movements = append(movements, models.StockMovement{
+			ID:           row.MovementID,
+			MaterialID:   row.MaterialID,
+			MaterialName: row.MaterialName,
+			Unit:         row.Unit,
+			Kind:         row.Kind,
+			Quantity:     row.Quantity,
+			From:         models.Location{ID: row.FromLocationID.Int64, Name: row.FromLocationName.String},
+			To:           models.Location{ID: row.ToLocationID.Int64, Name: row.ToLocationName.String},
+			Date:         row.MovedOn,
+			Comment:      row.Comment,
+			OrderID:      row.OrderID.Int64,
+		})
+	}
+	return movements, nil
+}
+
+// GetStockLevels returns levels of materials that have them.
+func (r *Repository) GetStockLevels(ctx context.Context) ([]models.StockLevel, error) {
+	rows, err := r.queries.GetStockLevels(ctx)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	levels := make([]models.StockLevel, 0, len(rows))
+	for _, row := range rows {
+		levels = append(levels, models.StockLevel{
+			MaterialID:      row.MaterialID,
+			Minimum:         row.Minimum,
+			ReorderPoint:    row.ReorderPoint,
+			ReorderQuantity: row.ReorderQuantity,
+		})
+	}
+	return levels, nil
+}
+
+// SaveStockLevels stores levels of materials in one transaction, zero level deletes levels of material.
+func (r *Repository) SaveStockLevels(ctx context.Context, levels []models.StockLevel) error {
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		for _, level := range levels {
+			if level == (models.StockLevel{MaterialID: level.MaterialID}) {
+				if err := q.DeleteStockLevel(ctx, level.MaterialID); err != nil {
+					return err
+				}
+				continue
+			}
+			err := q.SetStockLevel(ctx, db.SetStockLevelParams{
+				MaterialID:      level.MaterialID,
+				Minimum:         level.Minimum,
+				ReorderPoint:    level.ReorderPoint,
+				ReorderQuantity: level.ReorderQuantity,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return parseError(err)
+}
+
+// GetDigestSentOn returns date of last scheduled reorder digest, empty when digest wasn't sent yet.
+func (r *Repository) GetDigestSentOn(ctx context.Context) (string, error) {
+	date, err := r.queries.GetDigestSentOn(ctx)
+	if err != nil {
+		return "", parseError(err)
+	}
+	return date, nil
+}
+
+// SetDigestSentOn stores date of last scheduled reorder digest.
+func (r *Repository) SetDigestSentOn(ctx context.Context, date string) error {
+	return parseError(r.queries.SetDigestSentOn(ctx, date))
+}
+
+// locationID returns NULL for missing location.
+func locationID(location models.Location) sql.NullInt64 {
+	return sql.NullInt64{Int64: location.ID, Valid: location.ID != 0}
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+
+	db "github.com/s-588/BOMViewer/internal/db/generate"
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// GetSavedViews returns views of kind ordered by name.
+func (r *Repository) GetSavedViews(ctx context.Context, kind string) ([]models.SavedView, error) {
+	rows, err := r.queries.GetSavedViews(ctx, kind)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	views := make([]models.SavedView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, savedView(row))
+	}
+	return views, nil
+}
+
+// SaveView stores view, view of the same kind and name is replaced.
+func (r *Repository) SaveView(ctx context.Context, view models.SavedView) (models.SavedView, error) {
+	row, err := r.queries.SaveView(ctx, db.SaveViewParams{
+		Kind:  view.Kind,
+		Name:  view.Name,
+		Query: view.Query,
+	})
+	if err != nil {
+		return models.SavedView{}, parseError(err)
+	}
+	return savedView(row), nil
+}
+
+// DeleteSavedView deletes view and returns its kind.
+func (r *Repository) DeleteSavedView(ctx context.Context, id int64) (string, error) {
+	kind, err := r.queries.DeleteSavedView(ctx, id)
+	if err != nil {
+		return "", parseError(err)
+	}
+	return kind, nil
+}
+
+func savedView(row db.SavedView) models.SavedView {
+	return models.SavedView{
+		ID:    row.ViewID,
+		Kind:  row.Kind,
+		Name:  row.Name,
+		Query: row.Query,
+	}
+}
@@ -0,0 +1,92 @@
+// Package extract gets plain text from attached documents, so their content can be searched.
+//
+// Only pure Go readers are used: DOCX, XLSX and PPTX are read as zipped XML, text of PDF
+// is taken from content streams of its pages, and plain text may be in UTF-8, UTF-16
+// or Windows-1251 encoding. Format is detected by content, not by file name.
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// ErrUnsupported is returned for images and other documents without extractable text.
+var ErrUnsupported = errors.New("unsupported document format")
+
+// MaxTextSize limits extracted text in bytes, the rest of big documents is not indexed.
+const MaxTextSize = 1 << 20
+
+// maxFileSize limits size of document that is read into memory.
+const maxFileSize = 100 << 20
+
+// Text returns text of document at path with whitespace collapsed to single spaces.
+func Text(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxFileSize {
+		return "", fmt.Errorf("document is too large: %d bytes", info.Size())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		text, err = pdfText(data)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		text, err = officeText(data)
+	case strings.HasPrefix(http.DetectContentType(data), "text/plain"):
+		text, err = plainText(data)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+	// Glyphs without known characters may be decoded to control characters.
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+	return truncate(strings.Join(strings.Fields(text), " "), MaxTextSize), nil
+}
+
+// plainText decodes text file. Text that is not valid UTF-8 and has no UTF-16 byte order mark
+// is considered to be in Windows-1251, because it's the most common legacy encoding of Russian texts.
+func plainText(data []byte) (string, error) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) || bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
+		decoded, err := xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM).NewDecoder().Bytes(data)
+		return string(decoded), err
+	}
+	if utf8.Valid(data) {
+		return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), nil
+	}
+	decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+	return string(decoded), err
+}
+
+// truncate cuts s to at most size bytes without breaking UTF-8 sequence.
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size]
+}
@@ -0,0 +1,110 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Parts of Office Open XML packages with text. In all of them text is kept in elements
+// named "t": w:t in documents, t in spreadsheets and a:t in presentations.
+var officeParts = []string{
+	"word/document.xml",
+	// Title blocks of drawings are often placed in headers and footers.
+	"word/header*.xml",
+	"word/footer*.xml",
+	"xl/sharedStrings.xml",
+	// Inline strings are stored in sheets.
+	"xl/worksheets/sheet*.xml",
+	"ppt/slides/slide*.xml",
+}
+
+// officeBreaks are paragraphs, cells, tabs and line breaks. Space is added around them,
+// so words of neighbour elements are not glued together.
+var officeBreaks = map[string]bool{
+	"p": true, "tab": true, "br": true, "cr": true, "tc": true, "si": true, "c": true, "row": true,
+}
+
+// officeText returns text of DOCX, XLSX or PPTX document. Other zip archives are not supported.
+func officeText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	names := make([]string, 0, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+		names = append(names, file.Name)
+	}
+	// Keep order of sheets and slides, "slide10" goes after "slide9".
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	found := false
+	for _, pattern := range officeParts {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); !ok {
+				continue
+			}
+			found = true
+			if err := xmlText(files[name], &b); err != nil {
+				return "", err
+			}
+			if b.Len() > MaxTextSize {
+				return b.String(), nil
+			}
+		}
+	}
+	if !found {
+		return "", ErrUnsupported
+	}
+	return b.String(), nil
+}
+
+// xmlText writes text of all "t" elements of file to b.
+func xmlText(file *zip.File, b *strings.Builder) error {
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(r, maxFileSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+			if officeBreaks[t.Name.Local] {
+				b.WriteByte(' ')
+			}
+		case xml.EndElement:
+			inText = false
+			if officeBreaks[t.Name.Local] {
+				b.WriteByte(' ')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,734 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// PDF is read without cross-reference table: all objects are found by "N G obj" headers,
+// so damaged and incrementally updated files are read too. Text is taken from text showing
+// operators of page content streams and form XObjects. Codes of fonts are converted to text
+// by ToUnicode CMaps, fonts without CMap are considered to be in Windows-1251.
+
+type (
+	pdfName    string
+	pdfRef     int
+	pdfString  []byte
+	pdfKeyword string
+	pdfArray   []any
+	pdfDict    map[pdfName]any
+)
+
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+// maxPDFDepth limits nesting of pages, forms and references, so broken file can't loop forever.
+const maxPDFDepth = 32
+
+var objHeaderRe = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+
+type pdfFile struct {
+	data    []byte
+	objects map[pdfRef]*pdfObject
+	cmaps   map[pdfRef]*cmap
+	out     strings.Builder
+}
+
+func pdfText(data []byte) (string, error) {
+	f := &pdfFile{
+		data:    data,
+		objects: make(map[pdfRef]*pdfObject),
+		cmaps:   make(map[pdfRef]*cmap),
+	}
+	f.readObjects()
+
+	pages := f.pages()
+	for _, page := range pages {
+		f.content(page, f.inherited(page, "Resources"), 0)
+		f.out.WriteByte('\n')
+		if f.out.Len() > MaxTextSize {
+			break
+		}
+	}
+	return f.out.String(), nil
+}
+
+// readObjects parses all objects of file and objects packed in object streams.
+func (f *pdfFile) readObjects() {
+	type header struct {
+		ref   pdfRef
+		start int
+	}
+	var headers []header
+	for _, m := range objHeaderRe.FindAllSubmatchIndex(f.data, -1) {
+		num, err := strconv.Atoi(string(f.data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		headers = append(headers, header{pdfRef(num), m[1]})
+	}
+
+	// Stream length may be a reference to object defined later, so streams are read
+	// when all objects are known.
+	streamStarts := make(map[pdfRef]int)
+	skipUntil := 0
+	for _, h := range headers {
+		// Binary stream data may look like object header.
+		if h.start < skipUntil {
+			continue
+		}
+		l := &pdfLexer{data: f.data, pos: h.start}
+		value := l.object(0)
+		obj := &pdfObject{value: value}
+		if keyword, ok := l.next().(pdfKeyword); ok && keyword == "stream" {
+			start := l.pos
+			if start < len(f.data) && f.data[start] == '\r' {
+				start++
+			}
+			if start < len(f.data) && f.data[start] == '\n' {
+				start++
+			}
+			streamStarts[h.ref] = start
+			if end := bytes.Index(f.data[start:], []byte("endstream")); end >= 0 {
+				skipUntil = start + end
+			}
+		}
+		// Later definitions replace earlier ones, as in incremental updates.
+		f.objects[h.ref] = obj
+	}
+	for ref, start := range streamStarts {
+		obj := f.objects[ref]
+		dict, _ := obj.value.(pdfDict)
+		end := -1
+		if length, ok := f.resolve(dict["Length"], 0).(float64); ok {
+			end = start + int(length)
+		}
+		if end < start || end > len(f.data) || !bytes.Contains(f.data[end:min(end+20, len(f.data))], []byte("endstream")) {
+			end = bytes.Index(f.data[start:], []byte("endstream"))
+			if end < 0 {
+				continue
+			}
+			end += start
+		}
+		obj.stream = decodeStream(dict, f.data[start:end])
+	}
+
+	for _, obj := range f.objects {
+		if dict, ok := obj.value.(pdfDict); ok && dict["Type"] == pdfName("ObjStm") && obj.stream != nil {
+			f.readObjectStream(dict, obj.stream)
+		}
+	}
+}
+
+// readObjectStream parses objects packed into stream of PDF 1.5 and newer.
+func (f *pdfFile) readObjectStream(dict pdfDict, data []byte) {
+	n, _ := dict["N"].(float64)
+	first, _ := dict["First"].(float64)
+	if int(first) > len(data) {
+		return
+	}
+	l := &pdfLexer{data: data[:int(first)]}
+	for range int(n) {
+		num, ok1 := l.next().(float64)
+		offset, ok2 := l.next().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		start := int(first) + int(offset)
+		if start >= len(data) {
+			continue
+		}
+		if _, exists := f.objects[pdfRef(num)]; exists {
+			continue
+		}
+		objLexer := &pdfLexer{data: data, pos: start}
+		f.objects[pdfRef(num)] = &pdfObject{value: objLexer.object(0)}
+	}
+}
+
+// decodeStream returns decoded stream data or nil when its filter is not supported.
+func decodeStream(dict pdfDict, data []byte) []byte {
+	var filters []any
+	switch filter := dict["Filter"].(type) {
+	case nil:
+		return data
+	case pdfName:
+		filters = []any{filter}
+	case pdfArray:
+		filters = filter
+	}
+	for _, filter := range filters {
+		if filter != pdfName("FlateDecode") {
+			return nil
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		// Checksum errors are common in real files, data read before the error is kept.
+		decoded, _ := io.ReadAll(io.LimitReader(r, maxFileSize))
+		if len(decoded) == 0 {
+			return nil
+		}
+		data = decoded
+	}
+	return data
+}
+
+func (f *pdfFile) resolve(v any, depth int) any {
+	ref, ok := v.(pdfRef)
+	if !ok {
+		return v
+	}
+	obj := f.objects[ref]
+	if obj == nil || depth > maxPDFDepth {
+		return nil
+	}
+	return f.resolve(obj.value, depth+1)
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	dict, _ := f.resolve(v, 0).(pdfDict)
+	return dict
+}
+
+// pages returns pages in order of page tree, or all pages by object number when tree is broken.
+func (f *pdfFile) pages() []pdfDict {
+	var pages []pdfDict
+	var walk func(node pdfDict, depth int)
+	walk = func(node pdfDict, depth int) {
+		if depth > maxPDFDepth {
+			return
+		}
+		switch node["Type"] {
+		case pdfName("Page"):
+			pages = append(pages, node)
+		case pdfName("Pages"):
+			kids, _ := f.resolve(node["Kids"], 0).(pdfArray)
+			for _, kid := range kids {
+				if dict := f.dict(kid); dict != nil {
+					walk(dict, depth+1)
+				}
+			}
+		}
+	}
+	refs := make([]pdfRef, 0, len(f.objects))
+	for ref := range f.objects {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+	for _, ref := range refs {
+		if dict, ok := f.objects[ref].value.(pdfDict); ok && dict["Type"] == pdfName("Pages") && dict["Parent"] == nil {
+			walk(dict, 0)
+		}
+	}
+	if len(pages) != 0 {
+		return pages
+	}
+	for _, ref := range refs {
+		if dict, ok := f.objects[ref].value.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, dict)
+		}
+	}
+	return pages
+}
+
+// inherited returns attribute of page that may be defined in its parent nodes.
+func (f *pdfFile) inherited(page pdfDict, key pdfName) pdfDict {
+	for node, depth := page, 0; node != nil && depth < maxPDFDepth; node, depth = f.dict(node["Parent"]), depth+1 {
+		if value := f.dict(node[key]); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// content writes text of page.
+func (f *pdfFile) content(node pdfDict, resources pdfDict, depth int) {
+	if depth > maxPDFDepth {
+		return
+	}
+	var data []byte
+	switch contents := f.resolve(node["Contents"], 0).(type) {
+	case pdfArray:
+		for _, part := range contents {
+			if ref, ok := part.(pdfRef); ok && f.objects[ref] != nil {
+				data = append(data, f.objects[ref].stream...)
+				data = append(data, '\n')
+			}
+		}
+	default:
+		if ref, ok := node["Contents"].(pdfRef); ok && f.objects[ref] != nil {
+			data = f.objects[ref].stream
+		}
+	}
+	f.showText(data, resources, depth)
+}
+
+// showText runs text operators of content stream.
+func (f *pdfFile) showText(data []byte, resources pdfDict, depth int) {
+	fonts := f.dict(resources["Font"])
+	xobjects := f.dict(resources["XObject"])
+	var font *cmap
+	var operands []any
+
+	l := &pdfLexer{data: data}
+	for {
+		token := l.object(0)
+		if token == nil && l.pos >= len(data) {
+			return
+		}
+		op, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = f.fontCMap(fonts[name])
+				}
+			}
+		case "Tj", "'":
+			if len(operands) >= 1 {
+				f.write(font, operands[len(operands)-1])
+			}
+		case `"`:
+			if len(operands) >= 3 {
+				f.write(font, operands[2])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				items, _ := operands[0].(pdfArray)
+				for _, item := range items {
+					// Big negative offset between strings is a gap between words.
+					if offset, ok := item.(float64); ok && offset < -150 {
+						f.out.WriteByte(' ')
+					}
+					f.write(font, item)
+				}
+			}
+		case "T*", "Tm", "BT", "ET":
+			f.out.WriteByte(' ')
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[1] != 0.0 {
+				f.out.WriteByte(' ')
+			}
+		case "Do":
+			if len(operands) >= 1 {
+				name, _ := operands[0].(pdfName)
+				ref, ok := xobjects[name].(pdfRef)
+				if obj := f.objects[ref]; ok && obj != nil {
+					if form, ok := obj.value.(pdfDict); ok && form["Subtype"] == pdfName("Form") {
+						formResources := f.dict(form["Resources"])
+						if formResources == nil {
+							formResources = resources
+						}
+						f.out.WriteByte(' ')
+						f.showText(obj.stream, formResources, depth+1)
+						f.out.WriteByte(' ')
+					}
+				}
+			}
+		case "ID":
+			l.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+func (f *pdfFile) write(font *cmap, v any) {
+	s, ok := v.(pdfString)
+	if !ok {
+		return
+	}
+	if font == nil {
+		decoded, _ := charmap.Windows1251.NewDecoder().Bytes(s)
+		f.out.Write(decoded)
+		return
+	}
+	f.out.WriteString(font.decode(s))
+}
+
+// fontCMap returns ToUnicode CMap of font or nil when font has no CMap.
+func (f *pdfFile) fontCMap(v any) *cmap {
+	font := f.dict(v)
+	ref, ok := font["ToUnicode"].(pdfRef)
+	if !ok || f.objects[ref] == nil {
+		return nil
+	}
+	if c, ok := f.cmaps[ref]; ok {
+		return c
+	}
+	c := parseCMap(f.objects[ref].stream)
+	f.cmaps[ref] = c
+	return c
+}
+
+// maxCMapRange limits size of one range of CMap.
+const maxCMapRange = 1 << 16
+
+// cmap maps character codes of font to text.
+type cmap struct {
+	lengths []int // byte lengths of codes, the shortest first
+	chars   map[int]string
+}
+
+func parseCMap(data []byte) *cmap {
+	c := &cmap{chars: make(map[int]string)}
+	lengths := make(map[int]bool)
+	l := &pdfLexer{data: data}
+	var operands []any
+	for l.pos < len(data) {
+		token := l.object(0)
+		op, ok := token.(pdfKeyword)
+		if !ok {
+			if token != nil {
+				operands = append(operands, token)
+			}
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for _, v := range operands {
+				if s, ok := v.(pdfString); ok && len(s) > 0 {
+					lengths[len(s)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					lengths[len(src)] = true
+					c.chars[codeOf(src)] = utf16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || codeOf(hi)-codeOf(lo) > maxCMapRange {
+					continue
+				}
+				lengths[len(lo)] = true
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					// Destination of the next code is the same text with the last character incremented.
+					text := []rune(utf16BE(dst))
+					for code := codeOf(lo); code <= codeOf(hi) && len(text) > 0; code++ {
+						c.chars[code] = string(text)
+						text[len(text)-1]++
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok {
+							c.chars[codeOf(lo)+j] = utf16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	for length := range lengths {
+		c.lengths = append(c.lengths, length)
+	}
+	sort.Ints(c.lengths)
+	if len(c.lengths) == 0 {
+		c.lengths = []int{1}
+	}
+	return c
+}
+
+// decode converts codes of string to text, unknown codes are skipped.
+func (c *cmap) decode(s []byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		n := c.lengths[0]
+		for _, length := range c.lengths {
+			if i+length > len(s) {
+				break
+			}
+			if text, ok := c.chars[codeOf(s[i:i+length])]; ok {
+				b.WriteString(text)
+				n = length
+				break
+			}
+		}
+		i += n
+	}
+	return b.String()
+}
+
+func codeOf(s []byte) int {
+	code := 0
+	for _, b := range s {
+		code = code<<8 | int(b)
+	}
+	return code
+}
+
+func bytesToUTF16(s []byte) []uint16 {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16BE(s []byte) string {
+	return string(utf16.Decode(bytesToUTF16(s)))
+}
+
+// pdfLexer reads PDF objects and content stream operators.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// object reads one object, references "N G R" are returned as pdfRef.
+// Nil is returned at the end of data.
+func (l *pdfLexer) object(depth int) any {
+	token := l.next()
+	switch t := token.(type) {
+	case float64:
+		// Look ahead for reference, lexer is restored if it's just a number.
+		save := l.pos
+		if _, ok := l.next().(float64); ok {
+			if keyword, ok := l.next().(pdfKeyword); ok && keyword == "R" {
+				return pdfRef(t)
+			}
+		}
+		l.pos = save
+		return t
+	case pdfKeyword:
+		if depth > maxPDFDepth {
+			return nil
+		}
+		switch t {
+		case "[":
+			var array pdfArray
+			for l.pos < len(l.data) {
+				save := l.pos
+				if keyword, ok := l.next().(pdfKeyword); ok && keyword == "]" {
+					break
+				}
+				l.pos = save
+				array = append(array, l.object(depth+1))
+			}
+			return array
+		case "<<":
+			dict := make(pdfDict)
+			for l.pos < len(l.data) {
+				key := l.next()
+				if keyword, ok := key.(pdfKeyword); ok && keyword == ">>" {
+					break
+				}
+				if name, ok := key.(pdfName); ok {
+					dict[name] = l.object(depth + 1)
+				}
+			}
+			return dict
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+	}
+	return token
+}
+
+// next reads one token: number, name, string, keyword or delimiter of array or dictionary.
+func (l *pdfLexer) next() any {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		break
+	}
+	if l.pos >= len(l.data) {
+		return nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return pdfKeyword("<<")
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return pdfKeyword(">>")
+	case c == '<':
+		return l.hexString()
+	case c == '[' || c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfKeyword(string(c))
+	case c == '/':
+		l.pos++
+		return pdfName(l.regular())
+	}
+
+	word := l.regular()
+	if word == "" {
+		l.pos++
+		return pdfKeyword(string(c))
+	}
+	if number, err := strconv.ParseFloat(word, 64); err == nil {
+		return number
+	}
+	return pdfKeyword(word)
+}
+
+// regular reads run of regular characters, names may have #XX escapes.
+func (l *pdfLexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if !strings.Contains(word, "#") {
+		return word
+	}
+	var b strings.Builder
+	for i := 0; i < len(word); i++ {
+		if word[i] == '#' && i+2 < len(word) {
+			if v, err := strconv.ParseUint(word[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(word[i])
+	}
+	return b.String()
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // (
+	var s []byte
+	nesting := 0
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			if nesting == 0 {
+				return s
+			}
+			nesting--
+		case '\\':
+			if l.pos >= len(l.data) {
+				return s
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for range 2 {
+						if l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7' {
+							v = v*8 + int(l.data[l.pos]-'0')
+							l.pos++
+						}
+					}
+					c = byte(v)
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return s
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	s := make(pdfString, len(digits)/2)
+	for i := range s {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		s[i] = byte(v)
+	}
+	return s
+}
+
+// skipInlineImage skips binary data of inline image up to "EI" operator.
+func (l *pdfLexer) skipInlineImage() {
+	for i := l.pos + 1; i+1 < len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && isPDFSpace(l.data[i-1]) &&
+			(i+2 == len(l.data) || isPDFSpace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// AttributeFilter narrows materials by value of attribute. Values match text attributes
+// by substring and other kinds exactly, Min and Max apply to number attributes.
+type AttributeFilter struct {
+	AttributeID int64
+	Values      []string
+	Min         *float64
+	Max         *float64
+}
+
+// ApplicableAttributes returns global attributes and attributes of category and its parents,
+// category 0 gets only global attributes, as products do.
+func ApplicableAttributes(attributes []models.Attribute, categories []models.Category, categoryID int64) []models.Attribute {
+	parents := make(map[int64]int64, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	path := map[int64]bool{0: true}
+	for id := categoryID; id != 0 && !path[id]; id = parents[id] {
+		path[id] = true
+	}
+	applicable := make([]models.Attribute, 0, len(attributes))
+	for _, a := range attributes {
+		if path[a.CategoryID] {
+			applicable = append(applicable, a)
+		}
+	}
+	return applicable
+}
+
+// ParseAttributeValue validates value entered for attribute and returns it in stored form.
+// Empty value means that attribute isn't set.
+func ParseAttributeValue(a models.Attribute, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	switch a.Kind {
+	case models.AttributeNumber:
+		number, err := parseQuantity(value)
+		if err != nil {
+			return "", fmt.Errorf("значение «%s» должно быть числом", a.Name)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case models.AttributeEnum:
+		if !slices.Contains(a.Options, value) {
+			return "", fmt.Errorf("значение «%s» должно быть одним из: %s", a.Name, strings.Join(a.Options, ", "))
+		}
+	case models.AttributeBool:
+		if value != "true" && value != "false" {
+			return "", fmt.Errorf("значение «%s» должно быть «да» или «нет»", a.Name)
+		}
+	}
+	if len(value) > 250 {
+		return "", fmt.Errorf("значение «%s» длиннее 250 символов", a.Name)
+	}
+	return value, nil
+}
+
+// ParseAttributeOptions splits options of enum attribute, one option per line.
+func ParseAttributeOptions(text string) []string {
+	var options []string
+	for _, option := range strings.Split(text, "\n") {
+		option = strings.TrimSpace(option)
+		if option != "" && !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// ValidateAttribute checks attribute definition entered by user.
+func ValidateAttribute(a models.Attribute) error {
+	if a.Name == "" || len(a.Name) > 100 {
+		return errors.New("название должно быть от 1 до 100 символов")
+	}
+	switch a.Kind {
+	case models.AttributeText, models.AttributeNumber, models.AttributeBool:
+	case models.AttributeEnum:
+		if len(a.Options) == 0 {
+			return errors.New("у списка должен быть хотя бы один вариант")
+		}
+	default:
+		return errors.New("неизвестный тип атрибута")
+	}
+	return nil
+}
+
+// ParseTags splits comma separated tags, repeated and empty tags are dropped.
+func ParseTags(text string) []string {
+	var tags []string
+	for _, tag := range strings.Split(text, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// AllTags returns sorted tags used by materials.
+func AllTags(materials []models.Material) []string {
+	var tags []string
+	for _, material := range materials {
+		for _, tag := range material.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// FormatAttributeValue returns value for display, number is followed by unit.
+func FormatAttributeValue(value models.AttributeValue) string {
+	switch value.Kind {
+	case models.AttributeNumber:
+		if value.Unit != "" {
+			return value.Value + " " + value.Unit
+		}
+	case models.AttributeBool:
+		if value.Value == "true" {
+			return "да"
+		}
+		return "нет"
+	}
+	return value.Value
+}
+
+// AttributeValueOf returns stored value of attribute, or empty string when it isn't set.
+func AttributeValueOf(values []models.AttributeValue, attributeID int64) string {
+	for _, value := range values {
+		if value.AttributeID == attributeID {
+			return value.Value
+		}
+	}
+	return ""
+}
+
+// hasAnyTag reports whether material has any of tags, tags are compared ignoring case.
+func hasAnyTag(material models.Material, tags []string) bool {
+	for _, tag := range material.Tags {
+		for _, wanted := range tags {
+			if strings.EqualFold(tag, wanted) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// passesAttributeFilter checks value of material attribute, material without value doesn't pass.
+func passesAttributeFilter(material models.Material, filter AttributeFilter) bool {
+	i := slices.IndexFunc(material.Attributes, func(v models.AttributeValue) bool {
+		return v.AttributeID == filter.AttributeID
+	})
+	if i < 0 {
+		return false
+	}
+	value := material.Attributes[i]
+	if len(filter.Values) > 0 {
+		matched := slices.ContainsFunc(filter.Values, func(wanted string) bool {
+			if value.Kind == models.AttributeText {
+				return strings.Contains(strings.ToLower(value.Value), strings.ToLower(wanted))
+			}
+			return strings.EqualFold(value.Value, wanted)
+		})
+		if !matched {
+			return false
+		}
+	}
+	if filter.Min != nil || filter.Max != nil {
+		number, err := parseQuantity(value.Value)
+		if err != nil {
+			return false
+		}
+		if filter.Min != nil && number < *filter.Min {
+			return false
+		}
+		if filter.Max != nil && number > *filter.Max {
+			return false
+		}
+	}
+	return true
+}
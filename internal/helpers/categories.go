@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"slices"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// CategoryRollup is requirement of all materials of category and its subcategories measured in Unit.
+// Materials with different units can't be summed, so category has a rollup for each unit.
+type CategoryRollup struct {
+	CategoryItem
+	Unit      string
+	Required  float64
+	Shortage  float64
+	Materials int
+}
+
+// Uncategorized is name of group of materials without category.
+const Uncategorized = "Без категории"
+
+// CategoryItem is category in depth-first order of tree, Depth of top level category is 0.
+type CategoryItem struct {
+	models.Category
+	Depth int
+	// Total is number of materials in category and all its subcategories.
+	Total int64
+}
+
+// CategoryTree orders categories depth-first, children follow their parent in the order of input.
+func CategoryTree(categories []models.Category) []CategoryItem {
+	children := categoryChildren(categories)
+	tree := make([]CategoryItem, 0, len(categories))
+	var walk func(parentID int64, depth int) int64
+	walk = func(parentID int64, depth int) int64 {
+		var total int64
+		for _, category := range children[parentID] {
+			i := len(tree)
+			tree = append(tree, CategoryItem{Category: category, Depth: depth})
+			tree[i].Total = category.MaterialCount + walk(category.ID, depth+1)
+			total += tree[i].Total
+		}
+		return total
+	}
+	walk(0, 0)
+	return tree
+}
+
+// CategorySubtree returns ids with IDs of all their subcategories.
+// ID 0 stands for materials without category and is kept as is.
+func CategorySubtree(categories []models.Category, ids []int64) []int64 {
+	children := categoryChildren(categories)
+	subtree := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool)
+	var walk func(id int64)
+	walk = func(id int64) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		subtree = append(subtree, id)
+		if id == 0 {
+			return
+		}
+		for _, child := range children[id] {
+			walk(child.ID)
+		}
+	}
+	for _, id := range ids {
+		walk(id)
+	}
+	return subtree
+}
+
+// CategoryFacets orders categories that have found materials as tree, counts are number of found materials
+// directly in category by its ID. Total of item is number of found materials in category and subcategories.
+func CategoryFacets(categories []models.Category, counts []models.SearchFacet) []CategoryItem {
+	byID := make(map[int64]int64, len(counts))
+	for _, count := range counts {
+		byID[count.ID] = count.Count
+	}
+	found := make([]models.Category, len(categories))
+	for i, category := range categories {
+		category.MaterialCount = byID[category.ID]
+		found[i] = category
+	}
+	var items []CategoryItem
+	for _, item := range CategoryTree(found) {
+		if item.Total > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// CategoryRollups sums calculable results by every level of category tree.
+// Rollups follow order of tree, materials without category are summed last.
+func CategoryRollups(categories []models.Category, results []models.CalculationResult) []CategoryRollup {
+	parents := make(map[int64]int64, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	type key struct {
+		categoryID int64
+		unit       string
+	}
+	sums := make(map[key]*CategoryRollup)
+	var units []string
+	for _, result := range results {
+		if !result.IsCalculable {
+			continue
+		}
+		if !slices.Contains(units, result.Unit) {
+			units = append(units, result.Unit)
+		}
+		// Uncategorized materials have ID 0 and are summed under it.
+		// Steps are limited in case of a broken tree.
+		id := result.CategoryID
+		for steps := 0; steps <= len(categories); steps++ {
+			k := key{id, result.Unit}
+			if sums[k] == nil {
+				sums[k] = &CategoryRollup{Unit: result.Unit}
+			}
+			sums[k].Required += result.Total
+			sums[k].Shortage += result.Shortage
+			sums[k].Materials++
+			if id = parents[id]; id == 0 {
+				break
+			}
+		}
+	}
+
+	var rollups []CategoryRollup
+	tree := append(CategoryTree(categories), CategoryItem{Category: models.Category{Name: Uncategorized}})
+	for _, item := range tree {
+		for _, unit := range units {
+			if sum := sums[key{item.ID, unit}]; sum != nil {
+				sum.CategoryItem = item
+				rollups = append(rollups, *sum)
+			}
+		}
+	}
+	return rollups
+}
+
+func categoryChildren(categories []models.Category) map[int64][]models.Category {
+	children := make(map[int64][]models.Category)
+	for _, category := range categories {
+		children[category.ParentID] = append(children[category.ParentID], category)
+	}
+	return children
+}
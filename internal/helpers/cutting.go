@@ -0,0 +1,396 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// DefaultStockLength is length in mm of commercial bars and pipes.
+const DefaultStockLength = 6000
+
+// MaxCuttingPieces limits number of pieces of one plan, pieces are cut lists multiplied by batch.
+const MaxCuttingPieces = 10000
+
+// linearProfiles are profile types that are bought in bars and cut to length.
+var linearProfiles = []string{"Труба", "Круг", "Квадрат", "Полоса", "Уголок"}
+
+// IsLinearMaterial reports whether BOM line is cut from bars: it already has cut list, is measured in length,
+// is in category of long profile or its name is long profile with recognized sizes. Type in name alone
+// isn't enough, "Круг отрезной 125х1,2х22" is abrasive disc, not round bar.
+func IsLinearMaterial(material models.Material) bool {
+	if len(material.Cuts) > 0 {
+		return true
+	}
+	switch unitKey(material.Unit.Name) {
+	case "м", "м.п", "мп", "пог.м", "см", "мм":
+		return true
+	}
+	if slices.Contains(linearProfiles, material.Category.Name) {
+		return true
+	}
+	profile := ParseProfile(material.PrimaryName)
+	return slices.Contains(linearProfiles, profile.Type) && len(profile.Dimensions) > 0 && len(profile.Problems) == 0
+}
+
+var cutPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(?:мм)?\s*(?:[xх×*]\s*(\d+))?(?:\s*шт\.?)?$`)
+
+// ParseCuts parses cut list, one cut per line or separated by ";": "1200x4", "1200 х 4", "1200*4"
+// or "1200" for one piece. Lengths are in mm, equal lengths are summed up.
+func ParseCuts(text string) ([]models.Cut, error) {
+	var cuts []models.Cut
+	for item := range strings.FieldsFuncSeq(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		match := cutPattern.FindStringSubmatch(item)
+		if match == nil {
+			return nil, fmt.Errorf("не удалось разобрать «%s», ожидается длина x количество, например 1200x4", item)
+		}
+		length, _ := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+		if length <= 0 {
+			return nil, fmt.Errorf("длина в «%s» должна быть больше нуля", item)
+		}
+		var quantity int64 = 1
+		if match[2] != "" {
+			q, err := strconv.ParseInt(match[2], 10, 64)
+			if err != nil || q <= 0 || q > MaxCuttingPieces {
+				return nil, fmt.Errorf("количество в «%s» должно быть от 1 до %d", item, MaxCuttingPieces)
+			}
+			quantity = q
+		}
+		if i := slices.IndexFunc(cuts, func(c models.Cut) bool { return c.Length == length }); i >= 0 {
+			cuts[i].Quantity += quantity
+			continue
+		}
+		cuts = append(cuts, models.Cut{Length: length, Quantity: quantity})
+	}
+	slices.SortStableFunc(cuts, func(a, b models.Cut) int { return compareDesc(a.Length, b.Length) })
+	return cuts, nil
+}
+
+// FormatCuts formats cut list the way ParseCuts reads it, one cut per line.
+func FormatCuts(cuts []models.Cut) string {
+	lines := make([]string, 0, len(cuts))
+	for _, cut := range cuts {
+		lines = append(lines, FormatLength(cut.Length)+"×"+strconv.FormatInt(cut.Quantity, 10))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatLength formats length in mm, whole lengths are printed without fraction.
+func FormatLength(length float64) string {
+	if length == float64(int64(length)) {
+		return strconv.FormatInt(int64(length), 10)
+	}
+	return strconv.FormatFloat(length, 'f', 1, 64)
+}
+
+// ParseStockLengths parses stock lengths in mm separated by commas, semicolons or spaces.
+// Lengths are returned longest first without duplicates.
+func ParseStockLengths(text string) ([]float64, error) {
+	var lengths []float64
+	for field := range strings.FieldsFuncSeq(text, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\n' }) {
+		length, err := strconv.ParseFloat(field, 64)
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("длина заготовки «%s» должна быть положительным числом", field)
+		}
+		if !slices.Contains(lengths, length) {
+			lengths = append(lengths, length)
+		}
+	}
+	if len(lengths) == 0 {
+		return nil, errors.New("укажите хотя бы одну длину заготовки")
+	}
+	slices.SortFunc(lengths, compareDesc)
+	return lengths, nil
+}
+
+// CutPieces expands cut list for batch of products into lengths of single pieces.
+// Number of pieces is checked before it's multiplied, so huge quantities can't overflow.
+func CutPieces(cuts []models.Cut, batch int64) ([]float64, error) {
+	if batch <= 0 {
+		return nil, errors.New("размер партии должен быть больше нуля")
+	}
+	var total int64
+	for _, cut := range cuts {
+		if cut.Quantity < 0 || cut.Quantity > (MaxCuttingPieces-total)/batch {
+			return nil, fmt.Errorf("слишком много деталей для раскроя, не больше %d", MaxCuttingPieces)
+		}
+		total += cut.Quantity * batch
+	}
+	pieces := make([]float64, 0, total)
+	for _, cut := range cuts {
+		for range cut.Quantity * batch {
+			pieces = append(pieces, cut.Length)
+		}
+	}
+	return pieces, nil
+}
+
+// CuttingBar is one stock bar and pieces cut from it, longest first.
+type CuttingBar struct {
+	Stock float64
+	Cuts  []float64
+}
+
+// CuttingPlan is cutting of pieces from stock bars, Kerf is width of saw cut in mm.
+// Unplaced are pieces longer than the longest stock bar.
+type CuttingPlan struct {
+	StockLengths []float64
+	Kerf         float64
+	Bars         []CuttingBar
+	Unplaced     []float64
+}
+
+// CuttingPattern is Count bars cut the same way.
+type CuttingPattern struct {
+	CuttingBar
+	Count int
+}
+
+// StockCount is number of bars of stock length to order.
+type StockCount struct {
+	Length float64
+	Count  int
+}
+
+// Pieces returns sum of lengths of pieces of bar.
+func (b CuttingBar) Pieces() float64 {
+	var sum float64
+	for _, cut := range b.Cuts {
+		sum += cut
+	}
+	return sum
+}
+
+// Used returns length of bar taken by pieces and saw cuts between them.
+func (b CuttingBar) Used(kerf float64) float64 {
+	if len(b.Cuts) == 0 {
+		return 0
+	}
+	return b.Pieces() + kerf*float64(len(b.Cuts)-1)
+}
+
+// Offcut returns length left from bar after cutting.
+func (b CuttingBar) Offcut(kerf float64) float64 {
+	return b.Stock - b.Used(kerf)
+}
+
+// TotalStock returns length of all bars.
+func (p CuttingPlan) TotalStock() float64 {
+	var sum float64
+	for _, bar := range p.Bars {
+		sum += bar.Stock
+	}
+	return sum
+}
+
+// TotalPieces returns length of all placed pieces.
+func (p CuttingPlan) TotalPieces() float64 {
+	var sum float64
+	for _, bar := range p.Bars {
+		sum += bar.Pieces()
+	}
+	return sum
+}
+
+// WastePercent returns share of stock that isn't pieces, saw cuts and offcuts are waste.
+func (p CuttingPlan) WastePercent() float64 {
+	stock := p.TotalStock()
+	if stock == 0 {
+		return 0
+	}
+	return (stock - p.TotalPieces()) / stock * 100
+}
+
+// StockCounts returns number of bars of each stock length, longest first.
+func (p CuttingPlan) StockCounts() []StockCount {
+	var counts []StockCount
+	for _, length := range p.StockLengths {
+		count := 0
+		for _, bar := range p.Bars {
+			if bar.Stock == length {
+				count++
+			}
+		}
+		if count > 0 {
+			counts = append(counts, StockCount{Length: length, Count: count})
+		}
+	}
+	return counts
+}
+
+// Patterns groups bars cut the same way, most frequent pattern first.
+func (p CuttingPlan) Patterns() []CuttingPattern {
+	var patterns []CuttingPattern
+	for _, bar := range p.Bars {
+		i := slices.IndexFunc(patterns, func(pattern CuttingPattern) bool {
+			return pattern.Stock == bar.Stock && slices.Equal(pattern.Cuts, bar.Cuts)
+		})
+		if i >= 0 {
+			patterns[i].Count++
+			continue
+		}
+		patterns = append(patterns, CuttingPattern{CuttingBar: bar, Count: 1})
+	}
+	slices.SortStableFunc(patterns, func(a, b CuttingPattern) int { return b.Count - a.Count })
+	return patterns
+}
+
+// maxImprovePasses bounds improvement phase, each pass either empties a bar or moves material between bars.
+const maxImprovePasses = 50
+
+// PlanCutting cuts pieces from stock bars minimizing waste. Pieces are placed by first fit decreasing
+// into the longest stock, then improvement phase moves and swaps pieces to fill bars tighter and empty
+// the least used ones. At last every bar is replaced by the shortest stock length that holds its pieces.
+func PlanCutting(pieces []float64, stockLengths []float64, kerf float64) CuttingPlan {
+	plan := CuttingPlan{StockLengths: slices.Clone(stockLengths), Kerf: kerf}
+	slices.SortFunc(plan.StockLengths, compareDesc)
+	if len(plan.StockLengths) == 0 {
+		plan.Unplaced = slices.Clone(pieces)
+		return plan
+	}
+	longest := plan.StockLengths[0]
+
+	sorted := slices.Clone(pieces)
+	slices.SortFunc(sorted, compareDesc)
+	for _, piece := range sorted {
+		if piece > longest {
+			plan.Unplaced = append(plan.Unplaced, piece)
+			continue
+		}
+		placed := false
+		for i := range plan.Bars {
+			if fits(plan.Bars[i], piece, kerf) {
+				plan.Bars[i].Cuts = append(plan.Bars[i].Cuts, piece)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			plan.Bars = append(plan.Bars, CuttingBar{Stock: longest, Cuts: []float64{piece}})
+		}
+	}
+
+	for range maxImprovePasses {
+		if !emptyBar(&plan) && !concentrate(&plan) {
+			break
+		}
+	}
+
+	for i := range plan.Bars {
+		bar := &plan.Bars[i]
+		slices.SortFunc(bar.Cuts, compareDesc)
+		used := bar.Used(kerf)
+		for _, length := range plan.StockLengths {
+			if length >= used {
+				bar.Stock = length
+			}
+		}
+	}
+	slices.SortStableFunc(plan.Bars, func(a, b CuttingBar) int { return compareDesc(a.Stock, b.Stock) })
+	return plan
+}
+
+// fits reports whether piece and saw cut before it fit into bar.
+func fits(bar CuttingBar, piece, kerf float64) bool {
+	if len(bar.Cuts) == 0 {
+		return piece <= bar.Stock
+	}
+	return bar.Used(kerf)+kerf+piece <= bar.Stock
+}
+
+// emptyBar tries to spread pieces of the least used bar over other bars, it reports whether bar was removed.
+func emptyBar(plan *CuttingPlan) bool {
+	if len(plan.Bars) < 2 {
+		return false
+	}
+	least := 0
+	for i, bar := range plan.Bars {
+		if bar.Pieces() < plan.Bars[least].Pieces() {
+			least = i
+		}
+	}
+	others := make([]CuttingBar, 0, len(plan.Bars)-1)
+	for i, bar := range plan.Bars {
+		if i != least {
+			others = append(others, CuttingBar{Stock: bar.Stock, Cuts: slices.Clone(bar.Cuts)})
+		}
+	}
+	moving := slices.Clone(plan.Bars[least].Cuts)
+	slices.SortFunc(moving, compareDesc)
+	for _, piece := range moving {
+		placed := false
+		// Best fit: the bar left with the shortest offcut keeps long offcuts of other bars free.
+		best := -1
+		for i := range others {
+			if fits(others[i], piece, plan.Kerf) && (best < 0 || others[i].Offcut(plan.Kerf) < others[best].Offcut(plan.Kerf)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			others[best].Cuts = append(others[best].Cuts, piece)
+			placed = true
+		}
+		if !placed {
+			return false
+		}
+	}
+	plan.Bars = others
+	return true
+}
+
+// concentrate moves a piece or swaps two pieces so that fuller bar gets more material and emptier bar less,
+// it reports whether anything changed. Each change makes fill of bars more uneven, so passes end.
+func concentrate(plan *CuttingPlan) bool {
+	bars := plan.Bars
+	kerf := plan.Kerf
+	slices.SortStableFunc(bars, func(a, b CuttingBar) int { return compareDesc(a.Pieces(), b.Pieces()) })
+	for i := range bars {
+		for j := len(bars) - 1; j > i; j-- {
+			free := bars[i].Offcut(kerf)
+			for bi, b := range bars[j].Cuts {
+				// Move b from emptier bar j into fuller bar i.
+				if fits(bars[i], b, kerf) {
+					bars[i].Cuts = append(bars[i].Cuts, b)
+					bars[j].Cuts = slices.Delete(bars[j].Cuts, bi, bi+1)
+					if len(bars[j].Cuts) == 0 {
+						plan.Bars = slices.Delete(bars, j, j+1)
+					}
+					return true
+				}
+				// Swap shorter a of bar i for longer b of bar j when difference fits into offcut of i.
+				for ai, a := range bars[i].Cuts {
+					if b > a && b-a <= free {
+						bars[i].Cuts[ai], bars[j].Cuts[bi] = b, a
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func compareDesc(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// MaterialCutting is cutting plan of one BOM line for batch of products.
+type MaterialCutting struct {
+	Material models.Material
+	Plan     CuttingPlan
+}
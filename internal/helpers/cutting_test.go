@@ -0,0 +1,139 @@
+package helpers
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+func TestIsLinearMaterial(t *testing.T) {
+	tests := []struct {
+		name     string
+		material models.Material
+		want     bool
+	}{
+		{"round bar", models.Material{PrimaryName: "Круг 20 Ст3", Unit: models.Unit{Name: "кг"}}, true},
+		{"pipe", models.Material{PrimaryName: "Труба 57х3,5", Unit: models.Unit{Name: "кг"}}, true},
+		{"cut-off disc", models.Material{PrimaryName: "Круг отрезной 125х1,2х22", Unit: models.Unit{Name: "шт"}}, false},
+		{"flap disc", models.Material{PrimaryName: "Круг зачистной лепестковый 125", Unit: models.Unit{Name: "шт"}}, false},
+		{"sheet", models.Material{PrimaryName: "Лист 5 Ст.3", Unit: models.Unit{Name: "кг"}}, false},
+		{"length unit", models.Material{PrimaryName: "Кабель ВВГ 3х2,5", Unit: models.Unit{Name: "м"}}, true},
+		{"linear category", models.Material{PrimaryName: "Пруток калиброванный", Unit: models.Unit{Name: "кг"},
+			Category: models.Category{Name: "Круг"}}, true},
+		{"has cuts", models.Material{PrimaryName: "Профиль", Unit: models.Unit{Name: "шт"},
+			Cuts: []models.Cut{{Length: 1200, Quantity: 2}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLinearMaterial(tt.material); got != tt.want {
+				t.Errorf("IsLinearMaterial(%q) = %v, want %v", tt.material.PrimaryName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCuts(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []models.Cut
+		wantErr bool
+	}{
+		{text: "1200x4", want: []models.Cut{{Length: 1200, Quantity: 4}}},
+		{text: "1200 х 4\n800*2; 500", want: []models.Cut{{Length: 1200, Quantity: 4}, {Length: 800, Quantity: 2}, {Length: 500, Quantity: 1}}},
+		{text: "500x1\n1200,5 мм x 2 шт", want: []models.Cut{{Length: 1200.5, Quantity: 2}, {Length: 500, Quantity: 1}}},
+		{text: "600x2\n600x3", want: []models.Cut{{Length: 600, Quantity: 5}}},
+		{text: "", want: nil},
+		{text: "0x2", wantErr: true},
+		{text: "1200x0", wantErr: true},
+		{text: "1200x10001", wantErr: true},
+		{text: "1200x99999999999999999999", wantErr: true},
+		{text: "длинная", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseCuts(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCuts(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseCuts(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCutPieces(t *testing.T) {
+	tests := []struct {
+		name    string
+		cuts    []models.Cut
+		batch   int64
+		want    int
+		wantErr bool
+	}{
+		{name: "batch of one", cuts: []models.Cut{{Length: 1200, Quantity: 2}, {Length: 500, Quantity: 1}}, batch: 1, want: 3},
+		{name: "batch multiplies", cuts: []models.Cut{{Length: 1200, Quantity: 2}}, batch: 3, want: 6},
+		{name: "exactly limit", cuts: []models.Cut{{Length: 100, Quantity: MaxCuttingPieces / 2}}, batch: 2, want: MaxCuttingPieces},
+		{name: "over limit", cuts: []models.Cut{{Length: 100, Quantity: MaxCuttingPieces/2 + 1}}, batch: 2, wantErr: true},
+		{name: "limit over several cuts", cuts: []models.Cut{{Length: 200, Quantity: MaxCuttingPieces}, {Length: 100, Quantity: 1}}, batch: 1, wantErr: true},
+		{name: "overflow", cuts: []models.Cut{{Length: 100, Quantity: math.MaxInt64 / 2}}, batch: 4, wantErr: true},
+		{name: "huge batch", cuts: []models.Cut{{Length: 100, Quantity: 1}}, batch: math.MaxInt64, wantErr: true},
+		{name: "negative quantity", cuts: []models.Cut{{Length: 100, Quantity: -1}}, batch: 1, wantErr: true},
+		{name: "zero batch", cuts: []models.Cut{{Length: 100, Quantity: 1}}, batch: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CutPieces(tt.cuts, tt.batch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CutPieces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("CutPieces() returned %d pieces, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanCutting(t *testing.T) {
+	tests := []struct {
+		name         string
+		pieces       []float64
+		stockLengths []float64
+		kerf         float64
+		wantBars     int
+		wantStock    float64
+		wantUnplaced int
+		wantWaste    float64
+	}{
+		{name: "exact fit without kerf", pieces: []float64{3000, 3000}, stockLengths: []float64{6000}, wantBars: 1, wantStock: 6000},
+		{name: "kerf needs second bar", pieces: []float64{3000, 3000}, stockLengths: []float64{6000}, kerf: 3, wantBars: 2, wantStock: 12000, wantWaste: 50},
+		{name: "kerf between pieces only", pieces: []float64{2000, 2000, 1994}, stockLengths: []float64{6000}, kerf: 3, wantBars: 1, wantStock: 6000, wantWaste: 0.1},
+		{name: "shorter stock for last bar", pieces: []float64{5000, 2000}, stockLengths: []float64{6000, 3000}, kerf: 3, wantBars: 2, wantStock: 9000, wantWaste: 2000.0 / 9000 * 100},
+		{name: "piece longer than stock", pieces: []float64{7000, 1000}, stockLengths: []float64{6000}, wantBars: 1, wantStock: 6000, wantUnplaced: 1, wantWaste: 5000.0 / 6000 * 100},
+		{name: "improvement empties bar", pieces: []float64{4000, 3000, 3000, 2000}, stockLengths: []float64{6000}, wantBars: 2, wantStock: 12000},
+		{name: "no stock", pieces: []float64{1000}, wantUnplaced: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanCutting(tt.pieces, tt.stockLengths, tt.kerf)
+			if len(plan.Bars) != tt.wantBars {
+				t.Errorf("bars = %v, want %d bars", plan.Bars, tt.wantBars)
+			}
+			if got := plan.TotalStock(); got != tt.wantStock {
+				t.Errorf("TotalStock() = %v, want %v", got, tt.wantStock)
+			}
+			if len(plan.Unplaced) != tt.wantUnplaced {
+				t.Errorf("Unplaced = %v, want %d pieces", plan.Unplaced, tt.wantUnplaced)
+			}
+			if got := plan.WastePercent(); math.Abs(got-tt.wantWaste) > 1e-9 {
+				t.Errorf("WastePercent() = %v, want %v", got, tt.wantWaste)
+			}
+			for _, bar := range plan.Bars {
+				if bar.Offcut(tt.kerf) < 0 {
+					t.Errorf("bar %v overflows stock with kerf %v", bar, tt.kerf)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// Table is rows of exported sheet, cells are strings or float64 numbers, empty row separates sections.
+type Table [][]any
+
+// CalculationTable lays out calculator results for export: product and batch, requirements of
+// calculable materials and materials with text quantities that don't take part in calculation.
+func CalculationTable(product string, desiredQuantity int64, results []models.CalculationResult, nonCalculable []models.Material) Table {
+	table := Table{
+		{"Изделие", product},
+		{"Количество, шт", float64(desiredQuantity)},
+		{},
+		{"Материал", "Ед. изм.", "На единицу", "Остаток на складе", "Нетто", "Брутто", "Выход, %", "Дополнительно нужно", "Можно произвести", "Примечание"},
+	}
+	for _, result := range results {
+		var perUnit any = result.RequiredPerUnit
+		if quantity, err := parseQuantity(result.RequiredPerUnit); err == nil {
+			perUnit = quantity
+		}
+		note := ""
+		if result.Sheets > 0 {
+			note = fmt.Sprintf("по раскрою: %d лист. %s", result.Sheets, result.SheetSize)
+		}
+		table = append(table, []any{
+			result.MaterialName, result.Unit, perUnit, result.Stock, result.Net, result.Total,
+			result.YieldPercent, result.Shortage, float64(result.CanProduce), note,
+		})
+	}
+	if len(nonCalculable) > 0 {
+		table = append(table,
+			[]any{},
+			[]any{"Материалы с нечисловым количеством, не участвуют в расчете"},
+			[]any{"Материал", "Ед. изм.", "На единицу"},
+		)
+		for _, material := range nonCalculable {
+			table = append(table, []any{material.PrimaryName, material.Unit.Name, material.Quantity})
+		}
+	}
+	return table
+}
+
+// WriteCSV writes table as CSV for spreadsheet with russian locale: UTF-8 with BOM,
+// semicolon as separator and decimal comma.
+func WriteCSV(w io.Writer, table Table) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	for _, row := range table {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case float64:
+				record[i] = strings.ReplaceAll(exportNumber(v), ".", ",")
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX writes table as workbook with one sheet. Strings are stored inline,
+// so workbook needs no shared strings or styles.
+func WriteXLSX(w io.Writer, sheet string, table Table) error {
+	archive := zip.NewWriter(w)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheet))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(table)},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, file.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+)
+
+// xlsxSheet returns worksheet with cells of table, first column is widened for names of materials.
+func xlsxSheet(table Table) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<cols><col min="1" max="1" width="40" customWidth="1"/><col min="2" max="10" width="14" customWidth="1"/></cols>`)
+	b.WriteString(`<sheetData>`)
+	for i, row := range table {
+		if len(row) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			ref := columnName(j) + strconv.Itoa(i+1)
+			switch v := cell.(type) {
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, exportNumber(v))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(fmt.Sprint(v)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName returns spreadsheet name of zero-based column: A, B, ..., Z, AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// exportNumber rounds amount to thousandths, precision of remaining inputs.
+func exportNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -62,13 +62,13 @@ func (c *FileUploadConfig) HandleFileUpload(r *http.Request, formFieldName strin
 
 	// Read first 512 bytes to detect MIME type
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
+	n, err := file.Read(buffer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Detect MIME type
-	mimeType := http.DetectContentType(buffer)
+	// Detect MIME type, zero padding of small files would be detected as binary data
+	mimeType := http.DetectContentType(buffer[:n])
 	if !c.isAllowedType(mimeType) {
 		return nil, fmt.Errorf("file type not allowed: %s", mimeType)
 	}
@@ -127,7 +127,12 @@ func (c *FileUploadConfig) HandleFileUpload(r *http.Request, formFieldName strin
 }
 
 func (c *FileUploadConfig) isAllowedType(mimeType string) bool {
-	return slices.Contains(AllowedTypes, mimeType)
+	// Detected text types have charset parameter, like "text/plain; charset=utf-8".
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(AllowedTypes, mediaType)
 }
 
 func generateUniqueFileName() string {
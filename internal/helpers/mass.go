@@ -0,0 +1,230 @@
+package helpers
+
+import (
+	"math"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// DefaultDensityName is density used for profiles whose grade and material aren't in density table.
+const DefaultDensityName = "Сталь"
+
+// Mass is theoretical mass of profile computed from its dimensions and density, zero fields are unknown.
+// PerMetre is known for long profiles and coils, PerSquareMetre for sheets and coils,
+// PerPiece for sheets of known size and for profiles with attribute "Длина".
+type Mass struct {
+	PerMetre       float64
+	PerSquareMetre float64
+	PerPiece       float64
+	// Sheet reports that piece is a sheet.
+	Sheet   bool
+	Density models.Density
+}
+
+// MaterialMass computes theoretical mass of material. Dimensions and grade are taken from attribute values,
+// parse of primary name fills those that aren't set. Type of profile without type in name is its category.
+func MaterialMass(material models.Material, densities []models.Density) (Mass, bool) {
+	profileType, sizes, grade, name := materialSizes(material)
+	if profileType == "" {
+		return Mass{}, false
+	}
+	density, ok := FindDensity(densities, grade, name)
+	if !ok {
+		return Mass{}, false
+	}
+
+	m := Mass{Density: density}
+	// Area of section is in mm², so mass of metre is area·density·10⁻⁶.
+	var area float64
+	switch profileType {
+	case "Круг":
+		area = math.Pi * sizes["Диаметр"] * sizes["Диаметр"] / 4
+	case "Квадрат":
+		area = sizes["Сторона"] * sizes["Сторона"]
+	case "Полоса":
+		area = sizes["Ширина"] * sizes["Толщина"]
+	case "Труба":
+		wall := sizes["Толщина стенки"]
+		if sizes["Высота"] > 0 && sizes["Ширина"] > 0 {
+			// Corner radii of shaped pipe are neglected.
+			area = 2 * wall * (sizes["Высота"] + sizes["Ширина"] - 2*wall)
+		} else if sizes["Диаметр"] > wall {
+			area = math.Pi * (sizes["Диаметр"] - wall) * wall
+		}
+	case "Уголок":
+		flange, second := sizes["Полка"], sizes["Вторая полка"]
+		if second == 0 {
+			second = flange
+		}
+		if flange > 0 {
+			area = sizes["Толщина"] * (flange + second - sizes["Толщина"])
+		}
+	case "Лист", "Рулон":
+		m.PerSquareMetre = sizes["Толщина"] * density.Density / 1000
+		if profileType == "Рулон" {
+			area = sizes["Толщина"] * sizes["Ширина"]
+		} else if sizes["Ширина"] > 0 && sizes["Длина"] > 0 {
+			m.PerPiece = m.PerSquareMetre * sizes["Ширина"] * sizes["Длина"] / 1e6
+			m.Sheet = true
+		}
+	}
+	if area > 0 {
+		m.PerMetre = area * density.Density / 1e6
+		if length := sizes["Длина"]; length > 0 && profileType != "Лист" {
+			m.PerPiece = m.PerMetre * length / 1000
+		}
+	}
+	if m.PerMetre <= 0 && m.PerSquareMetre <= 0 && m.PerPiece <= 0 {
+		return Mass{}, false
+	}
+	return m, true
+}
+
+// materialSizes returns type of profile, its dimensions by name and grade of material.
+// Attribute values override those parsed from primary name, type of profile without type in name is its category.
+func materialSizes(material models.Material) (profileType string, sizes map[string]float64, grade, name string) {
+	name = material.PrimaryName
+	if name == "" && len(material.Names) > 0 {
+		name = material.Names[0]
+	}
+	profile := ParseProfile(name)
+	profileType = profile.Type
+	if _, ok := profileDimensions[material.Category.Name]; profileType == "" && ok {
+		profileType = material.Category.Name
+	}
+	sizes = make(map[string]float64)
+	for _, dimension := range profile.Dimensions {
+		sizes[dimension.Name] = dimension.Value
+	}
+	grade = profile.Grade
+	for _, value := range material.Attributes {
+		switch {
+		case strings.EqualFold(value.Name, ProfileGradeAttribute):
+			grade = value.Value
+		case value.Kind == models.AttributeNumber:
+			if number, err := parseQuantity(value.Value); err == nil && number > 0 {
+				sizes[value.Name] = number
+			}
+		}
+	}
+	return profileType, sizes, grade, name
+}
+
+// FindDensity returns density of grade, longest name that starts the grade wins, so "08Х18Н10" matches "08Х18Н10Т".
+// Without such grade density named in material name is used, then DefaultDensityName.
+func FindDensity(densities []models.Density, grade, name string) (models.Density, bool) {
+	grade, name = densityKey(grade), densityKey(name)
+	var found models.Density
+	for _, d := range densities {
+		if key := densityKey(d.Name); key != "" && grade != "" && strings.HasPrefix(grade, key) && len(key) > len(densityKey(found.Name)) {
+			found = d
+		}
+	}
+	if found.ID != 0 {
+		return found, true
+	}
+	for _, d := range densities {
+		if key := densityKey(d.Name); key != "" && strings.Contains(name, key) && len(key) > len(densityKey(found.Name)) {
+			found = d
+		}
+	}
+	if found.ID != 0 {
+		return found, true
+	}
+	for _, d := range densities {
+		if strings.EqualFold(d.Name, DefaultDensityName) {
+			return d, true
+		}
+	}
+	return models.Density{}, false
+}
+
+// densityKey drops case, spaces and punctuation, so "Ст. 3" and "ПА 6" match "Ст3" and "ПА-6".
+func densityKey(s string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(s))
+}
+
+// Units that theoretical mass converts between.
+const (
+	UnitKg          = "кг"
+	UnitMetre       = "м"
+	UnitSquareMetre = "м²"
+	UnitPiece       = "шт"
+	UnitSheet       = "лист"
+)
+
+// kgPerUnit returns mass of one unit, unknown unit or unknown mass gives 0.
+func (m Mass) kgPerUnit(unit string) float64 {
+	switch unitKey(unit) {
+	case "кг":
+		return 1
+	case "т":
+		return 1000
+	case "г":
+		return 0.001
+	case "м", "м.п", "мп", "пог.м":
+		return m.PerMetre
+	case "см":
+		return m.PerMetre / 100
+	case "мм":
+		return m.PerMetre / 1000
+	case "м²", "м2", "кв.м":
+		return m.PerSquareMetre
+	case "шт", "лист":
+		return m.PerPiece
+	}
+	return 0
+}
+
+func unitKey(unit string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(unit)), ".")
+}
+
+// Convert converts amount of material from one unit to another through its mass.
+// It reports false when either unit isn't mass, length, area or piece, or mass of that unit is unknown.
+func (m Mass) Convert(amount float64, from, to string) (float64, bool) {
+	fromKg, toKg := m.kgPerUnit(from), m.kgPerUnit(to)
+	if fromKg <= 0 || toKg <= 0 {
+		return 0, false
+	}
+	return amount * fromKg / toKg, true
+}
+
+// Units returns units amount in unit can be converted to, unit itself isn't included.
+func (m Mass) Units(unit string) []string {
+	if m.kgPerUnit(unit) <= 0 {
+		return nil
+	}
+	piece := UnitPiece
+	if m.Sheet {
+		piece = UnitSheet
+	}
+	var units []string
+	for _, u := range []string{UnitKg, UnitMetre, UnitSquareMetre, piece} {
+		if m.kgPerUnit(u) > 0 && unitKey(u) != unitKey(unit) {
+			units = append(units, u)
+		}
+	}
+	return units
+}
+
+// LineMass returns theoretical mass in kg of quantity of BOM line.
+func LineMass(material models.Material, mass Mass) (float64, bool) {
+	quantity, err := parseQuantity(material.Quantity)
+	if err != nil {
+		return 0, false
+	}
+	return mass.Convert(quantity, material.Unit.Name, UnitKg)
+}
+
+// MaterialMasses computes masses of materials that have one.
+func MaterialMasses(materials []models.Material, densities []models.Density) map[int64]Mass {
+	masses := make(map[int64]Mass)
+	for _, material := range materials {
+		if mass, ok := MaterialMass(material, densities); ok {
+			masses[material.ID] = mass
+		}
+	}
+	return masses
+}
@@ -11,9 +11,14 @@ import (
 
 // FilterArgs contains all possible filter parameters for materials
 type MaterialFilterArgs struct {
-	PrimaryOnly  bool
-	ProductIDs   []int64
-	UnitIDs      []int64
+	PrimaryOnly bool
+	ProductIDs  []int64
+	UnitIDs     []int64
+	// CategoryIDs must already include subcategories, 0 matches materials without category.
+	CategoryIDs []int64
+	// Tags selects materials with any of tags.
+	Tags         []string
+	Attributes   []AttributeFilter // material must pass every attribute filter
 	MinQuantity  *float64
 	MaxQuantity  *float64
 	QuantityUnit string // to handle different units for quantity range
@@ -73,6 +78,30 @@ func passesMaterialFilters(material models.Material, filter MaterialFilterArgs)
 		}
 	}
 
+	// Category filter
+	if len(filter.CategoryIDs) > 0 {
+		if !contains(filter.CategoryIDs, material.Category.ID) {
+			slog.Debug("material is not passes the category filter", "material", material, "CategoryIDs", filter.CategoryIDs)
+			return false
+		}
+	}
+
+	// Tag filter
+	if len(filter.Tags) > 0 {
+		if !hasAnyTag(material, filter.Tags) {
+			slog.Debug("material is not passes the tag filter", "material", material, "Tags", filter.Tags)
+			return false
+		}
+	}
+
+	// Attribute filters
+	for _, attributeFilter := range filter.Attributes {
+		if !passesAttributeFilter(material, attributeFilter) {
+			slog.Debug("material is not passes the attribute filter", "material", material, "attribute", attributeFilter.AttributeID)
+			return false
+		}
+	}
+
 	// Quantity range filter
 	if filter.MinQuantity != nil || filter.MaxQuantity != nil {
 		if !passesQuantityFilter(material.Quantity, filter) {
@@ -139,6 +168,8 @@ func SortMaterials(materials []models.Material, config SortConfig) {
 			return compareStrings(materials[i].PrimaryName, materials[j].PrimaryName, config.Order)
 		case "unit":
 			return compareStrings(materials[i].Unit.Name, materials[j].Unit.Name, config.Order)
+		case "category":
+			return compareStrings(materials[i].Category.Name, materials[j].Category.Name, config.Order)
 		case "quantity":
 			return compareQuantities(materials[i].Quantity, materials[j].Quantity, config.Order)
 		case "id":
@@ -0,0 +1,364 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// Size of sheet in mm used for sheets whose name and attributes don't tell it.
+const (
+	DefaultSheetWidth  = 1500
+	DefaultSheetLength = 3000
+)
+
+// IsSheetMaterial reports whether BOM line is cut from sheets: it's a sheet or already has blanks.
+func IsSheetMaterial(material models.Material) bool {
+	if len(material.Blanks) > 0 {
+		return true
+	}
+	profileType, _, _, _ := materialSizes(material)
+	return profileType == "Лист"
+}
+
+// SheetSize returns width and length of sheet of material, ok is false when they aren't known
+// and DefaultSheetWidth×DefaultSheetLength is returned.
+func SheetSize(material models.Material) (width, length float64, ok bool) {
+	_, sizes, _, _ := materialSizes(material)
+	if sizes["Ширина"] > 0 && sizes["Длина"] > 0 {
+		return sizes["Ширина"], sizes["Длина"], true
+	}
+	return DefaultSheetWidth, DefaultSheetLength, false
+}
+
+var blankPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*[xх×*]\s*(\d+(?:[.,]\d+)?)\s*(?:мм)?\s*(?:[xх×*]\s*(\d+))?(?:\s*шт\.?)?$`)
+
+// ParseBlanks parses blanks, one per line or separated by ";": "500x300x4" is 4 blanks 500×300 mm,
+// "500x300" is one blank. Equal blanks are summed up.
+func ParseBlanks(text string) ([]models.Blank, error) {
+	var blanks []models.Blank
+	for item := range strings.FieldsFuncSeq(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		match := blankPattern.FindStringSubmatch(item)
+		if match == nil {
+			return nil, fmt.Errorf("не удалось разобрать «%s», ожидается ширина x высота x количество, например 500x300x4", item)
+		}
+		width, _ := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+		height, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", "."), 64)
+		if width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("размеры в «%s» должны быть больше нуля", item)
+		}
+		var quantity int64 = 1
+		if match[3] != "" {
+			q, err := strconv.ParseInt(match[3], 10, 64)
+			if err != nil || q <= 0 || q > MaxCuttingPieces {
+				return nil, fmt.Errorf("количество в «%s» должно быть от 1 до %d", item, MaxCuttingPieces)
+			}
+			quantity = q
+		}
+		if i := slices.IndexFunc(blanks, func(b models.Blank) bool { return b.Width == width && b.Height == height }); i >= 0 {
+			blanks[i].Quantity += quantity
+			continue
+		}
+		blanks = append(blanks, models.Blank{Width: width, Height: height, Quantity: quantity})
+	}
+	slices.SortStableFunc(blanks, func(a, b models.Blank) int { return compareDesc(a.Width*a.Height, b.Width*b.Height) })
+	return blanks, nil
+}
+
+// FormatBlanks formats blanks the way ParseBlanks reads them, one per line.
+func FormatBlanks(blanks []models.Blank) string {
+	lines := make([]string, 0, len(blanks))
+	for _, blank := range blanks {
+		lines = append(lines, FormatLength(blank.Width)+"×"+FormatLength(blank.Height)+"×"+strconv.FormatInt(blank.Quantity, 10))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseSheetSize parses size of sheet "1500x3000" in mm.
+func ParseSheetSize(text string) (width, length float64, err error) {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return strings.ContainsRune("xх×* ", r) })
+	if len(fields) != 2 {
+		return 0, 0, errors.New("размер листа должен быть задан как ширина x длина, например 1500x3000")
+	}
+	width, errW := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", "."), 64)
+	length, errL := strconv.ParseFloat(strings.ReplaceAll(fields[1], ",", "."), 64)
+	if errW != nil || errL != nil || width <= 0 || length <= 0 {
+		return 0, 0, errors.New("размеры листа должны быть положительными числами")
+	}
+	return width, length, nil
+}
+
+// Placement is blank placed on sheet, X and Y are position of its corner in mm.
+// Rotated blank is placed with width and height swapped.
+type Placement struct {
+	X, Y          float64
+	Width, Height float64
+	Rotated       bool
+}
+
+// NestedSheet is one sheet with blanks placed on it.
+type NestedSheet struct {
+	Placements []Placement
+}
+
+// NestingPlan is placement of blanks on sheets of Width×Length, Kerf is gap between blanks in mm.
+// Unplaced are blanks that don't fit on sheet in any orientation.
+type NestingPlan struct {
+	Width, Length float64
+	Kerf          float64
+	Sheets        []NestedSheet
+	Unplaced      []models.Blank
+}
+
+// BlankArea returns area of placed blanks in mm².
+func (p NestingPlan) BlankArea() float64 {
+	var area float64
+	for _, sheet := range p.Sheets {
+		for _, placement := range sheet.Placements {
+			area += placement.Width * placement.Height
+		}
+	}
+	return area
+}
+
+// Utilization returns share of area of sheets taken by blanks in percent.
+func (p NestingPlan) Utilization() float64 {
+	if len(p.Sheets) == 0 {
+		return 0
+	}
+	return p.BlankArea() / (float64(len(p.Sheets)) * p.Width * p.Length) * 100
+}
+
+// SheetArea returns area of all sheets in m².
+func (p NestingPlan) SheetArea() float64 {
+	return float64(len(p.Sheets)) * p.Width * p.Length / 1e6
+}
+
+// SheetLayout is Count sheets with the same placement of blanks.
+type SheetLayout struct {
+	NestedSheet
+	Count int
+}
+
+// Layouts groups sheets with the same placement of blanks in order of sheets.
+func (p NestingPlan) Layouts() []SheetLayout {
+	var layouts []SheetLayout
+	for _, sheet := range p.Sheets {
+		i := slices.IndexFunc(layouts, func(layout SheetLayout) bool { return slices.Equal(layout.Placements, sheet.Placements) })
+		if i >= 0 {
+			layouts[i].Count++
+			continue
+		}
+		layouts = append(layouts, SheetLayout{NestedSheet: sheet, Count: 1})
+	}
+	return layouts
+}
+
+// BlankPieces returns number of blanks for batch of products, it can't be more than MaxCuttingPieces.
+// Number of blanks is checked before it's multiplied, so huge quantities can't overflow.
+func BlankPieces(blanks []models.Blank, batch int64) (int64, error) {
+	if batch <= 0 {
+		return 0, errors.New("размер партии должен быть больше нуля")
+	}
+	var total int64
+	for _, blank := range blanks {
+		if blank.Quantity < 0 || blank.Quantity > (MaxCuttingPieces-total)/batch {
+			return 0, fmt.Errorf("слишком много заготовок для раскроя, не больше %d", MaxCuttingPieces)
+		}
+		total += blank.Quantity * batch
+	}
+	return total, nil
+}
+
+// shelf is row of blanks across sheet, the row is cut off the sheet by one guillotine cut, then
+// cut into columns, blanks lower than the row are stacked in columns.
+type shelf struct {
+	y, height, used float64
+	columns         []column
+}
+
+type column struct {
+	x, width, used float64
+}
+
+// place puts blank w×h into column of row or into new column at the end of row.
+func (row *shelf) place(w, h, kerf, width float64) (x, y float64, ok bool) {
+	for i := range row.columns {
+		col := &row.columns[i]
+		if w <= col.width && col.used+kerf+h <= row.height {
+			y = row.y + col.used + kerf
+			col.used += kerf + h
+			return col.x, y, true
+		}
+	}
+	x = 0
+	if row.used > 0 {
+		x = row.used + kerf
+	}
+	if x+w > width || h > row.height {
+		return 0, 0, false
+	}
+	row.columns = append(row.columns, column{x: x, width: w, used: h})
+	row.used = x + w
+	return x, row.y, true
+}
+
+type shelfSheet struct {
+	shelves    []shelf
+	used       float64
+	placements []Placement
+}
+
+// NestBlanks estimates sheets needed for batch of products by guillotine shelf packing:
+// blanks are sorted by height and placed first fit into rows of sheet. Rows across and along
+// the sheet, blanks laid on long and on short side are tried, the packing with fewer sheets wins.
+// More than MaxCuttingPieces blanks are reported as error.
+func NestBlanks(blanks []models.Blank, batch int64, width, length, kerf float64) (NestingPlan, error) {
+	if _, err := BlankPieces(blanks, batch); err != nil {
+		return NestingPlan{}, err
+	}
+	var best NestingPlan
+	for i, standing := range []bool{false, true, false, true} {
+		across := i >= 2
+		plan := shelfNest(blanks, batch, width, length, kerf, standing, across)
+		if i == 0 || len(plan.Sheets) < len(best.Sheets) || len(plan.Sheets) == len(best.Sheets) && lastSheetUse(plan) < lastSheetUse(best) {
+			best = plan
+		}
+	}
+	return best, nil
+}
+
+// lastSheetUse returns area of blanks on the last sheet, less used last sheet leaves bigger offcut.
+func lastSheetUse(plan NestingPlan) float64 {
+	if len(plan.Sheets) == 0 {
+		return 0
+	}
+	var used float64
+	for _, p := range plan.Sheets[len(plan.Sheets)-1].Placements {
+		used += p.Width * p.Height
+	}
+	return used
+}
+
+// shelfNest packs blanks into rows across sheet of width, rows are stacked along length.
+// Standing blanks are placed on their short side, across swaps sides of sheet.
+func shelfNest(blanks []models.Blank, batch int64, width, length, kerf float64, standing, across bool) NestingPlan {
+	plan := NestingPlan{Width: width, Length: length, Kerf: kerf}
+	if across {
+		width, length = length, width
+	}
+	type rect struct {
+		w, h    float64
+		rotated bool
+	}
+	var rects []rect
+	for _, blank := range blanks {
+		r := rect{w: blank.Width, h: blank.Height}
+		if (r.h > r.w) != standing {
+			r = rect{w: r.h, h: r.w, rotated: true}
+		}
+		if r.w > width || r.h > length {
+			r = rect{w: r.h, h: r.w, rotated: !r.rotated}
+		}
+		if r.w > width || r.h > length {
+			plan.Unplaced = append(plan.Unplaced, models.Blank{Width: blank.Width, Height: blank.Height, Quantity: blank.Quantity * batch})
+			continue
+		}
+		for range blank.Quantity * batch {
+			rects = append(rects, r)
+		}
+	}
+	slices.SortStableFunc(rects, func(a, b rect) int {
+		if c := compareDesc(a.h, b.h); c != 0 {
+			return c
+		}
+		return compareDesc(a.w, b.w)
+	})
+
+	var sheets []shelfSheet
+	for _, r := range rects {
+		placed := false
+		for si := range sheets {
+			sheet := &sheets[si]
+			for hi := range sheet.shelves {
+				row := &sheet.shelves[hi]
+				if x, y, ok := row.place(r.w, r.h, kerf, width); ok {
+					sheet.placements = append(sheet.placements, Placement{X: x, Y: y, Width: r.w, Height: r.h, Rotated: r.rotated})
+				} else if x, y, ok := row.place(r.h, r.w, kerf, width); ok {
+					// Turned blank fills the rest of a higher row.
+					sheet.placements = append(sheet.placements, Placement{X: x, Y: y, Width: r.h, Height: r.w, Rotated: !r.rotated})
+				} else {
+					continue
+				}
+				placed = true
+				break
+			}
+			if placed {
+				break
+			}
+			y := sheet.used
+			if y > 0 {
+				y += kerf
+			}
+			if y+r.h <= length {
+				sheet.shelves = append(sheet.shelves, shelf{y: y, height: r.h, used: r.w, columns: []column{{x: 0, width: r.w, used: r.h}}})
+				sheet.placements = append(sheet.placements, Placement{X: 0, Y: y, Width: r.w, Height: r.h, Rotated: r.rotated})
+				sheet.used = y + r.h
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			sheets = append(sheets, shelfSheet{
+				shelves:    []shelf{{y: 0, height: r.h, used: r.w, columns: []column{{x: 0, width: r.w, used: r.h}}}},
+				used:       r.h,
+				placements: []Placement{{X: 0, Y: 0, Width: r.w, Height: r.h, Rotated: r.rotated}},
+			})
+		}
+	}
+	for _, sheet := range sheets {
+		if across {
+			// Rows ran along the other side, coordinates are turned back to the sheet.
+			for i := range sheet.placements {
+				p := &sheet.placements[i]
+				p.X, p.Y = p.Y, p.X
+				p.Width, p.Height = p.Height, p.Width
+				p.Rotated = !p.Rotated
+			}
+		}
+		plan.Sheets = append(plan.Sheets, NestedSheet{Placements: sheet.placements})
+	}
+	return plan
+}
+
+// SheetAmount returns amount of sheets of width×length in unit of material: sheets, m² or mass.
+// It reports false when unit isn't one of them or mass of sheet is unknown.
+func SheetAmount(sheets float64, width, length float64, mass Mass, unit string) (float64, bool) {
+	area := width * length / 1e6
+	switch unitKey(unit) {
+	case "лист", "шт":
+		return sheets, true
+	case "м²", "м2", "кв.м":
+		return sheets * area, true
+	}
+	if mass.PerSquareMetre <= 0 {
+		return 0, false
+	}
+	sheet := Mass{PerPiece: mass.PerSquareMetre * area}
+	return sheet.Convert(sheets, UnitSheet, unit)
+}
+
+// MaterialNesting is nesting of blanks of one BOM line for batch of products.
+type MaterialNesting struct {
+	Material models.Material
+	Plan     NestingPlan
+}
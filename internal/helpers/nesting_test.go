@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+func TestParseBlanks(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []models.Blank
+		wantErr bool
+	}{
+		{text: "500x300x4", want: []models.Blank{{Width: 500, Height: 300, Quantity: 4}}},
+		{text: "200х100\n500*300*2; 250,5 x 100 мм x 3 шт", want: []models.Blank{
+			{Width: 500, Height: 300, Quantity: 2}, {Width: 250.5, Height: 100, Quantity: 3}, {Width: 200, Height: 100, Quantity: 1}}},
+		{text: "500x300x1\n500x300x2", want: []models.Blank{{Width: 500, Height: 300, Quantity: 3}}},
+		{text: "", want: nil},
+		{text: "0x300", wantErr: true},
+		{text: "500x300x0", wantErr: true},
+		{text: "500x300x10001", wantErr: true},
+		{text: "500", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseBlanks(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBlanks(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseBlanks(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlankPieces(t *testing.T) {
+	tests := []struct {
+		name    string
+		blanks  []models.Blank
+		batch   int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "batch multiplies", blanks: []models.Blank{{Width: 500, Height: 300, Quantity: 4}, {Width: 100, Height: 100, Quantity: 1}}, batch: 3, want: 15},
+		{name: "exactly limit", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: MaxCuttingPieces / 4}}, batch: 4, want: MaxCuttingPieces},
+		{name: "over limit", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: MaxCuttingPieces}, {Width: 20, Height: 20, Quantity: 1}}, batch: 1, wantErr: true},
+		{name: "overflow", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: math.MaxInt64 / 3}}, batch: 8, wantErr: true},
+		{name: "huge batch", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: 2}}, batch: math.MaxInt64, wantErr: true},
+		{name: "negative quantity", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: -1}}, batch: 1, wantErr: true},
+		{name: "zero batch", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: 1}}, batch: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BlankPieces(tt.blanks, tt.batch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BlankPieces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BlankPieces() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNestBlanks(t *testing.T) {
+	tests := []struct {
+		name          string
+		blanks        []models.Blank
+		batch         int64
+		width, length float64
+		kerf          float64
+		wantSheets    int
+		wantUnplaced  int
+		wantErr       bool
+	}{
+		{name: "quarters of sheet", blanks: []models.Blank{{Width: 750, Height: 1500, Quantity: 4}}, batch: 1,
+			width: 1500, length: 3000, wantSheets: 1},
+		{name: "kerf needs second sheet", blanks: []models.Blank{{Width: 750, Height: 1500, Quantity: 4}}, batch: 1,
+			width: 1500, length: 3000, kerf: 3, wantSheets: 2},
+		{name: "batch multiplies", blanks: []models.Blank{{Width: 1500, Height: 1000, Quantity: 1}}, batch: 6,
+			width: 1500, length: 3000, kerf: 3, wantSheets: 3},
+		{name: "rotated to fit", blanks: []models.Blank{{Width: 2500, Height: 1000, Quantity: 1}}, batch: 1,
+			width: 1500, length: 3000, wantSheets: 1},
+		{name: "too big for sheet", blanks: []models.Blank{{Width: 2000, Height: 2000, Quantity: 2}, {Width: 100, Height: 100, Quantity: 1}}, batch: 2,
+			width: 1500, length: 3000, wantSheets: 1, wantUnplaced: 4},
+		{name: "too many blanks", blanks: []models.Blank{{Width: 10, Height: 10, Quantity: MaxCuttingPieces}}, batch: 2,
+			width: 1500, length: 3000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := NestBlanks(tt.blanks, tt.batch, tt.width, tt.length, tt.kerf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NestBlanks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(plan.Sheets) != tt.wantSheets {
+				t.Errorf("NestBlanks() used %d sheets, want %d", len(plan.Sheets), tt.wantSheets)
+			}
+			var unplaced int64
+			for _, blank := range plan.Unplaced {
+				unplaced += blank.Quantity
+			}
+			if unplaced != int64(tt.wantUnplaced) {
+				t.Errorf("NestBlanks() left %d blanks unplaced, want %d", unplaced, tt.wantUnplaced)
+			}
+			for i, sheet := range plan.Sheets {
+				for j, p := range sheet.Placements {
+					if p.X < 0 || p.Y < 0 || p.X+p.Width > tt.width || p.Y+p.Height > tt.length {
+						t.Errorf("sheet %d: blank %+v is outside of sheet", i, p)
+					}
+					for _, q := range sheet.Placements[j+1:] {
+						// Blanks must be apart by kerf at least.
+						apart := p.X+p.Width+tt.kerf <= q.X || q.X+q.Width+tt.kerf <= p.X ||
+							p.Y+p.Height+tt.kerf <= q.Y || q.Y+q.Height+tt.kerf <= p.Y
+						if !apart {
+							t.Errorf("sheet %d: blanks %+v and %+v overlap", i, p, q)
+						}
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"math"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// OrderLines explodes BOM of product for quantity of order. Norm is gross quantity rounded to thousandths,
+// lines with text quantity have no norm and are issued by hand.
+func OrderLines(product models.Product, quantity int64) []models.ProductionOrderLine {
+	lines := make([]models.ProductionOrderLine, 0, len(product.Materials))
+	for _, material := range product.Materials {
+		line := models.ProductionOrderLine{
+			MaterialID:   material.ID,
+			MaterialName: material.PrimaryName,
+			Unit:         material.Unit.Name,
+			BOMQuantity:  material.Quantity,
+		}
+		if perUnit, err := parseQuantity(material.Quantity); err == nil && perUnit > 0 {
+			norm := math.Round(GrossQuantity(perUnit, material)*float64(quantity)*1000) / 1000
+			if norm > 0 {
+				line.Norm = &norm
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Overuse returns how much actual consumption of line exceeds norm, it's false when line has no norm,
+// no actual consumption or actual consumption is within norm.
+func Overuse(line models.ProductionOrderLine) (float64, bool) {
+	if line.Norm == nil || line.Actual == nil || *line.Actual <= *line.Norm {
+		return 0, false
+	}
+	return *line.Actual - *line.Norm, true
+}
@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// PlanLine is product of production plan and quantity to make.
+type PlanLine struct {
+	Product  models.Product
+	Quantity int64
+}
+
+// PlanRequirement is requirement of material summed over all products of plan.
+type PlanRequirement struct {
+	Material models.Material
+	Net      float64
+	Gross    float64
+	Stock    float64
+	Shortage float64
+	// Products is number of plan lines using the material.
+	Products int
+}
+
+// PlanProduct is result of plan line, stock is given to lines in order of plan.
+type PlanProduct struct {
+	PlanLine
+	// CanProduce is number of products that can be made from stock left by lines before it.
+	CanProduce int64
+	// Limiting is material that allows fewest products, zero when product has no calculable lines.
+	Limiting models.Material
+	// NonCalculable are lines with text quantities, they don't take part in plan.
+	NonCalculable []models.Material
+}
+
+// ProductionPlan is aggregated requirements of several products sharing one stock.
+type ProductionPlan struct {
+	Requirements []PlanRequirement
+	Products     []PlanProduct
+}
+
+// Shortages returns number of materials stock isn't enough for.
+func (p ProductionPlan) Shortages() int {
+	n := 0
+	for _, requirement := range p.Requirements {
+		if requirement.Shortage > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// PlanProduction sums gross requirements of plan lines by material and compares them with stock.
+// Requirements keep order in which materials first appear in plan.
+func PlanProduction(lines []PlanLine, stock map[int64]float64) ProductionPlan {
+	var plan ProductionPlan
+	index := make(map[int64]int)
+	left := make(map[int64]float64, len(stock))
+	for id, amount := range stock {
+		left[id] = amount
+	}
+
+	for _, line := range lines {
+		result := PlanProduct{PlanLine: line, CanProduce: line.Quantity}
+		perUnit := make(map[int64]float64)
+		var calculable []models.Material
+		limit := int64(math.MaxInt64)
+		for _, material := range line.Product.Materials {
+			quantity, err := parseQuantity(material.Quantity)
+			if err != nil || quantity <= 0 {
+				result.NonCalculable = append(result.NonCalculable, material)
+				continue
+			}
+			gross := GrossQuantity(quantity, material)
+			if _, ok := perUnit[material.ID]; !ok {
+				calculable = append(calculable, material)
+			}
+			perUnit[material.ID] += gross
+
+			i, ok := index[material.ID]
+			if !ok {
+				i = len(plan.Requirements)
+				index[material.ID] = i
+				plan.Requirements = append(plan.Requirements, PlanRequirement{Material: material, Stock: stock[material.ID]})
+			}
+			plan.Requirements[i].Net += quantity * float64(line.Quantity)
+			plan.Requirements[i].Gross += gross * float64(line.Quantity)
+			plan.Requirements[i].Products++
+		}
+
+		for _, material := range calculable {
+			n := wholeUnits(left[material.ID], perUnit[material.ID])
+			if n < limit {
+				limit, result.Limiting = n, material
+			}
+		}
+		if len(calculable) > 0 {
+			result.CanProduce = min(result.CanProduce, limit)
+		}
+		for _, material := range calculable {
+			left[material.ID] = max(left[material.ID]-perUnit[material.ID]*float64(result.CanProduce), 0)
+		}
+		plan.Products = append(plan.Products, result)
+	}
+
+	for i := range plan.Requirements {
+		requirement := &plan.Requirements[i]
+		requirement.Shortage = max(requirement.Gross-requirement.Stock, 0)
+	}
+	return plan
+}
+
+// wholeUnits returns how many whole units of per amount is enough for. Small tolerance keeps exact amount
+// from losing a unit to rounding. Huge amount is clamped to math.MaxInt64 instead of overflowing.
+func wholeUnits(amount, per float64) int64 {
+	n := math.Floor(amount/per + 1e-9)
+	if n >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(n)
+}
+
+// MaterialCapacity is how many sets of plan stock of material is enough for.
+type MaterialCapacity struct {
+	Material models.Material
+	// PerSet is gross requirement of one set of plan.
+	PerSet float64
+	Stock  float64
+	Sets   int64
+	// Extra is amount of material to add to make Capacity.Next more sets.
+	Extra float64
+}
+
+// Capacity is maximum number of sets of plan stock is enough for, quantities of plan lines make one set.
+type Capacity struct {
+	Max  int64
+	Next int64
+	// Materials are ordered from bottleneck, materials without requirement are left out.
+	Materials []MaterialCapacity
+}
+
+// Bottlenecks returns materials that allow exactly Max sets.
+func (c Capacity) Bottlenecks() []MaterialCapacity {
+	var bottlenecks []MaterialCapacity
+	for _, material := range c.Materials {
+		if material.Sets == c.Max {
+			bottlenecks = append(bottlenecks, material)
+		}
+	}
+	return bottlenecks
+}
+
+// Unlocking returns materials that must be added to make Next more sets.
+func (c Capacity) Unlocking() []MaterialCapacity {
+	var unlocking []MaterialCapacity
+	for _, material := range c.Materials {
+		if material.Extra > 0 {
+			unlocking = append(unlocking, material)
+		}
+	}
+	return unlocking
+}
+
+// ProductionCapacity computes maximum number of sets of plan that can be made from stock
+// and amounts of materials that would allow next sets.
+func ProductionCapacity(lines []PlanLine, stock map[int64]float64, next int64) Capacity {
+	plan := PlanProduction(lines, stock)
+	capacity := Capacity{Next: next}
+	for _, requirement := range plan.Requirements {
+		if requirement.Gross <= 0 {
+			continue
+		}
+		capacity.Materials = append(capacity.Materials, MaterialCapacity{
+			Material: requirement.Material,
+			PerSet:   requirement.Gross,
+			Stock:    requirement.Stock,
+			Sets:     wholeUnits(requirement.Stock, requirement.Gross),
+		})
+	}
+	if len(capacity.Materials) == 0 {
+		return capacity
+	}
+	slices.SortStableFunc(capacity.Materials, func(a, b MaterialCapacity) int {
+		return cmp.Compare(a.Sets, b.Sets)
+	})
+	capacity.Max = capacity.Materials[0].Sets
+	// Sum is taken in float, so huge Max and next don't overflow.
+	target := float64(capacity.Max) + float64(next)
+	for i := range capacity.Materials {
+		material := &capacity.Materials[i]
+		material.Extra = max(material.PerSet*target-material.Stock, 0)
+	}
+	return capacity
+}
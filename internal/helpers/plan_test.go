@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+func TestPlanProduction(t *testing.T) {
+	sheet := models.Material{ID: 1, PrimaryName: "Лист 5 Ст.3", Quantity: "2"}
+	bolt := models.Material{ID: 2, PrimaryName: "Болт М10х40", Quantity: "0,001"}
+	frame := models.Product{ID: 1, Name: "Рама", Materials: []models.Material{sheet, bolt}}
+	cover := models.Product{ID: 2, Name: "Крышка", Materials: []models.Material{sheet}}
+	tests := []struct {
+		name           string
+		lines          []PlanLine
+		stock          map[int64]float64
+		wantCanProduce []int64
+		wantShortages  int
+	}{
+		{name: "enough stock", lines: []PlanLine{{frame, 3}}, stock: map[int64]float64{1: 6, 2: 1},
+			wantCanProduce: []int64{3}},
+		{name: "stock goes to lines in order", lines: []PlanLine{{frame, 3}, {cover, 4}}, stock: map[int64]float64{1: 10, 2: 1},
+			wantCanProduce: []int64{3, 2}, wantShortages: 1},
+		{name: "no stock", lines: []PlanLine{{cover, 2}}, wantCanProduce: []int64{0}, wantShortages: 1},
+		{name: "huge stock", lines: []PlanLine{{frame, 5}}, stock: map[int64]float64{1: 1e17, 2: 1e17},
+			wantCanProduce: []int64{5}},
+		{name: "huge quantity", lines: []PlanLine{{frame, math.MaxInt64}}, stock: map[int64]float64{1: 1e300, 2: 1e300},
+			wantCanProduce: []int64{math.MaxInt64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanProduction(tt.lines, tt.stock)
+			for i, product := range plan.Products {
+				if product.CanProduce != tt.wantCanProduce[i] {
+					t.Errorf("%s: CanProduce = %d, want %d", product.Product.Name, product.CanProduce, tt.wantCanProduce[i])
+				}
+			}
+			if got := plan.Shortages(); got != tt.wantShortages {
+				t.Errorf("Shortages() = %d, want %d", got, tt.wantShortages)
+			}
+		})
+	}
+}
+
+func TestProductionCapacity(t *testing.T) {
+	sheet := models.Material{ID: 1, PrimaryName: "Лист 5 Ст.3", Quantity: "2"}
+	bolt := models.Material{ID: 2, PrimaryName: "Болт М10х40", Quantity: "0,001"}
+	frame := models.Product{ID: 1, Name: "Рама", Materials: []models.Material{sheet, bolt}}
+	tests := []struct {
+		name           string
+		stock          map[int64]float64
+		next           int64
+		wantMax        int64
+		wantBottleneck int64
+		wantExtra      float64
+	}{
+		{name: "sheet is bottleneck", stock: map[int64]float64{1: 7, 2: 1}, next: 1,
+			wantMax: 3, wantBottleneck: 1, wantExtra: 1},
+		{name: "no stock", next: 2, wantMax: 0, wantBottleneck: 1, wantExtra: 4},
+		{name: "huge stock", stock: map[int64]float64{1: 1e17, 2: 1e17}, next: 1,
+			wantMax: 5e16, wantBottleneck: 1},
+		{name: "huge next", stock: map[int64]float64{1: 1e300, 2: 1e300}, next: math.MaxInt64,
+			wantMax: math.MaxInt64, wantBottleneck: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity := ProductionCapacity([]PlanLine{{frame, 1}}, tt.stock, tt.next)
+			if capacity.Max != tt.wantMax {
+				t.Errorf("Max = %d, want %d", capacity.Max, tt.wantMax)
+			}
+			bottlenecks := capacity.Bottlenecks()
+			if len(bottlenecks) == 0 || bottlenecks[0].Material.ID != tt.wantBottleneck {
+				t.Fatalf("Bottlenecks() = %v, want material %d first", bottlenecks, tt.wantBottleneck)
+			}
+			if got := bottlenecks[0].Extra; math.Abs(got-tt.wantExtra) > 1e-9 {
+				t.Errorf("Extra = %v, want %v", got, tt.wantExtra)
+			}
+		})
+	}
+}
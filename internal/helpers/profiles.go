@@ -0,0 +1,237 @@
+package helpers
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// Profile is steel profile recognized in material name like "Уголок 75х5 ГОСТ 8509-93 Ст.3кп".
+// Type is empty when name isn't a profile, then only Standard can be found.
+type Profile struct {
+	Type       string
+	Dimensions []ProfileDimension
+	Grade      string
+	Standard   string
+	// Problems explain why parse needs review, parse without problems is confident.
+	Problems []string
+}
+
+// ProfileDimension is a size of profile in millimeters, Name is the name of attribute it fills.
+type ProfileDimension struct {
+	Name  string
+	Value float64
+}
+
+// profileDimensions are names of dimensions of every profile type by number of numbers in name.
+// Names of types and dimensions match seeded categories and attributes.
+var profileDimensions = map[string]map[int][]string{
+	"Лист":    {1: {"Толщина"}, 3: {"Толщина", "Ширина", "Длина"}},
+	"Рулон":   {1: {"Толщина"}, 2: {"Толщина", "Ширина"}},
+	"Труба":   {2: {"Диаметр", "Толщина стенки"}, 3: {"Высота", "Ширина", "Толщина стенки"}},
+	"Круг":    {1: {"Диаметр"}},
+	"Квадрат": {1: {"Сторона"}},
+	"Полоса":  {2: {"Ширина", "Толщина"}},
+	"Уголок":  {2: {"Полка", "Толщина"}, 3: {"Полка", "Вторая полка", "Толщина"}},
+}
+
+var (
+	// profileSizeSeparator joins numbers of "2х1250 х2500", letter х may be Cyrillic or Latin.
+	profileSizeSeparator = regexp.MustCompile(`(\d)\s*[хХxX×*]\s*(Ø?\d)`)
+	profileSizes         = regexp.MustCompile(`Ø?\d+(?:[.,]\d+)?(?:x\d+(?:[.,]\d+)?)*`)
+	profileStandard      = regexp.MustCompile(`(?:ГОСТ Р|ГОСТ|ОСТ|ТУ|DIN|ISO|EN)\s?\d[\d.\-]*\d`)
+	profileGrade         = regexp.MustCompile(`Ст\.?\s?\d{1,2}(?:сп|пс|кп)?|\d{2}Х\d{1,2}Н\d{1,2}Т?|\d{2}Г2С|AISI\s?\d{3}L?|[Сс]таль\s?\d{2}|ПА-?\d{1,2}[А-Я]?`)
+	// profileNoise is punctuation left between parsed parts.
+	profileNoise = regexp.MustCompile(`[\s,;.\-–()]+`)
+)
+
+// profileQualifiers are words of names that are known but don't fill any attribute.
+var profileQualifiers = []string{"мм", "ВГП", "проф", "ОЦ", "оцинк", "г/к", "х/к", "эл/св", "полиамид"}
+
+// ParseProfile recognizes profile type, dimensions, steel grade and standard in material name.
+func ParseProfile(name string) Profile {
+	var p Profile
+	// Standard and grade are cut out first, "08Х18Н10" must not be read as sizes.
+	rest := strings.TrimSpace(name)
+	if standard := profileStandard.FindString(rest); standard != "" {
+		p.Standard = standard
+		rest = strings.Replace(rest, standard, " ", 1)
+	}
+	grade := profileGrade.FindString(rest)
+	if grade != "" {
+		rest = strings.Replace(rest, grade, " ", 1)
+	}
+	rest = profileSizeSeparator.ReplaceAllString(rest, "${1}x${2}")
+	rest = profileSizeSeparator.ReplaceAllString(rest, "${1}x${2}")
+
+	// Type is usually the first word, but it may follow material like "Полиамид ПА-6Б круг Ø45".
+	words := strings.Fields(rest)
+	var tail string
+	for i, word := range words {
+		for profileType := range profileDimensions {
+			if strings.EqualFold(word, profileType) {
+				p.Type = profileType
+			}
+		}
+		if p.Type != "" {
+			tail = strings.Join(slices.Concat(words[:i], words[i+1:]), " ")
+			break
+		}
+	}
+	if p.Type == "" {
+		return p
+	}
+
+	p.Grade = grade
+	sizes := profileSizes.FindString(tail)
+	if sizes == "" {
+		p.Problems = append(p.Problems, "размеры не найдены")
+	} else {
+		tail = strings.Replace(tail, sizes, " ", 1)
+		var values []float64
+		for _, size := range strings.Split(strings.TrimPrefix(sizes, "Ø"), "x") {
+			value, err := parseQuantity(strings.TrimPrefix(size, "Ø"))
+			if err != nil {
+				p.Problems = append(p.Problems, "неверный размер "+size)
+				continue
+			}
+			values = append(values, value)
+		}
+		names, ok := profileDimensions[p.Type][len(values)]
+		if !ok {
+			p.Problems = append(p.Problems, fmt.Sprintf("непривычное число размеров: %s", sizes))
+		}
+		for i := range names {
+			p.Dimensions = append(p.Dimensions, ProfileDimension{Name: names[i], Value: values[i]})
+		}
+	}
+	unparsed := slices.DeleteFunc(strings.Fields(profileNoise.ReplaceAllString(tail, " ")), func(word string) bool {
+		return slices.ContainsFunc(profileQualifiers, func(q string) bool { return strings.EqualFold(word, q) })
+	})
+	if len(unparsed) > 0 {
+		p.Problems = append(p.Problems, "не распознано: "+strings.Join(unparsed, " "))
+	}
+	return p
+}
+
+// Values returns parsed fields by names of attributes they fill.
+func (p Profile) Values() map[string]string {
+	values := make(map[string]string)
+	for _, dimension := range p.Dimensions {
+		values[dimension.Name] = strconv.FormatFloat(dimension.Value, 'f', -1, 64)
+	}
+	if p.Grade != "" {
+		values[ProfileGradeAttribute] = p.Grade
+	}
+	if p.Standard != "" {
+		values[ProfileStandardAttribute] = p.Standard
+	}
+	return values
+}
+
+// Names of seeded attributes that hold steel grade and standard.
+const (
+	ProfileGradeAttribute    = "Марка стали"
+	ProfileStandardAttribute = "Стандарт"
+)
+
+// ProfileProposal is a change of material proposed by parse of its primary name.
+type ProfileProposal struct {
+	Material models.Material
+	Profile  Profile
+	// Category is category of profile type for material without category, zero otherwise.
+	Category models.Category
+	// Values are proposed values of attributes that material doesn't have yet.
+	Values    []ProposedValue
+	Problems  []string
+	Confident bool
+}
+
+// ProposedValue is a value of attribute proposed by parse.
+type ProposedValue struct {
+	Attribute models.Attribute
+	Value     string
+}
+
+// ProposeProfiles parses names of materials and matches parsed fields to their attributes by name.
+// Values already set are never proposed, so material disappears from proposals once they are applied.
+// Proposals that need review go first.
+func ProposeProfiles(materials []models.Material, categories []models.Category, attributes []models.Attribute) []ProfileProposal {
+	var proposals []ProfileProposal
+	for _, material := range materials {
+		name := material.PrimaryName
+		if name == "" && len(material.Names) > 0 {
+			name = material.Names[0]
+		}
+		profile := ParseProfile(name)
+		fields := profile.Values()
+		proposal := ProfileProposal{Material: material, Profile: profile, Problems: slices.Clone(profile.Problems)}
+
+		categoryID := material.Category.ID
+		// Category is proposed only for recognized sizes, "Труба нПВХ" isn't a steel pipe.
+		if categoryID == 0 && len(profile.Dimensions) > 0 {
+			i := slices.IndexFunc(categories, func(c models.Category) bool { return strings.EqualFold(c.Name, profile.Type) })
+			if i >= 0 {
+				proposal.Category = categories[i]
+				categoryID = categories[i].ID
+			}
+		}
+		applicable := ApplicableAttributes(attributes, categories, categoryID)
+		for _, fieldName := range profileFieldNames(profile.Type) {
+			value, parsed := fields[fieldName]
+			// When no sizes were parsed they are offered empty for review, other missing fields are skipped.
+			if !parsed && (len(profile.Dimensions) > 0 || !slices.Contains(profileDimensionNames(profile.Type), fieldName)) {
+				continue
+			}
+			i := slices.IndexFunc(applicable, func(a models.Attribute) bool { return strings.EqualFold(a.Name, fieldName) })
+			if i < 0 {
+				if parsed {
+					proposal.Problems = append(proposal.Problems, fmt.Sprintf("нет атрибута «%s» для категории", fieldName))
+				}
+				continue
+			}
+			if AttributeValueOf(material.Attributes, applicable[i].ID) != "" {
+				continue
+			}
+			proposal.Values = append(proposal.Values, ProposedValue{Attribute: applicable[i], Value: value})
+		}
+		if len(proposal.Values) == 0 && proposal.Category.ID == 0 {
+			continue
+		}
+		proposal.Confident = len(proposal.Problems) == 0
+		proposals = append(proposals, proposal)
+	}
+	slices.SortStableFunc(proposals, func(a, b ProfileProposal) int {
+		if a.Confident != b.Confident {
+			if a.Confident {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.Material.PrimaryName, b.Material.PrimaryName)
+	})
+	return proposals
+}
+
+// profileDimensionNames returns names of all dimensions of profile type in order of appearance.
+func profileDimensionNames(profileType string) []string {
+	var names []string
+	for _, count := range slices.Sorted(maps.Keys(profileDimensions[profileType])) {
+		for _, name := range profileDimensions[profileType][count] {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// profileFieldNames returns names of all fields that parse of profile type can fill.
+func profileFieldNames(profileType string) []string {
+	return append(profileDimensionNames(profileType), ProfileGradeAttribute, ProfileStandardAttribute)
+}
@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantType     string
+		wantDims     []ProfileDimension
+		wantGrade    string
+		wantStandard string
+		wantProblems bool
+	}{
+		{name: "Уголок 75х5 ГОСТ 8509-93 Ст.3кп", wantType: "Уголок",
+			wantDims:  []ProfileDimension{{"Полка", 75}, {"Толщина", 5}},
+			wantGrade: "Ст.3кп", wantStandard: "ГОСТ 8509-93"},
+		{name: "Лист 2х1250 х2500 08Х18Н10", wantType: "Лист",
+			wantDims:  []ProfileDimension{{"Толщина", 2}, {"Ширина", 1250}, {"Длина", 2500}},
+			wantGrade: "08Х18Н10"},
+		{name: "Труба 57х3,5", wantType: "Труба",
+			wantDims: []ProfileDimension{{"Диаметр", 57}, {"Толщина стенки", 3.5}}},
+		{name: "Труба проф. 40x20x2", wantType: "Труба",
+			wantDims: []ProfileDimension{{"Высота", 40}, {"Ширина", 20}, {"Толщина стенки", 2}}},
+		{name: "Полиамид ПА-6Б круг Ø45", wantType: "Круг",
+			wantDims: []ProfileDimension{{"Диаметр", 45}}, wantGrade: "ПА-6Б"},
+		{name: "Круг 20 Ст3", wantType: "Круг",
+			wantDims: []ProfileDimension{{"Диаметр", 20}}, wantGrade: "Ст3"},
+		{name: "Круг отрезной 125х1,2х22", wantType: "Круг", wantProblems: true},
+		{name: "Труба ВГП", wantType: "Труба", wantProblems: true},
+		{name: "Болт М10х40 ГОСТ 7798-70", wantStandard: "ГОСТ 7798-70"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseProfile(tt.name)
+			if p.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", p.Type, tt.wantType)
+			}
+			if !slices.Equal(p.Dimensions, tt.wantDims) {
+				t.Errorf("Dimensions = %v, want %v", p.Dimensions, tt.wantDims)
+			}
+			if p.Grade != tt.wantGrade {
+				t.Errorf("Grade = %q, want %q", p.Grade, tt.wantGrade)
+			}
+			if p.Standard != tt.wantStandard {
+				t.Errorf("Standard = %q, want %q", p.Standard, tt.wantStandard)
+			}
+			if (len(p.Problems) > 0) != tt.wantProblems {
+				t.Errorf("Problems = %q, want problems %v", p.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+var testDensities = []models.Density{
+	{ID: 1, Name: "Сталь", Density: 7850},
+	{ID: 2, Name: "08Х18Н10", Density: 7900},
+	{ID: 3, Name: "ПА-6", Density: 1130},
+}
+
+func TestFindDensity(t *testing.T) {
+	tests := []struct {
+		grade, name string
+		want        int64
+	}{
+		{grade: "08Х18Н10Т", want: 2},
+		{grade: "Ст3", name: "Круг 20 Ст3", want: 1},
+		{name: "Полиамид ПА 6 круг Ø45", want: 3},
+		{want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.grade+tt.name, func(t *testing.T) {
+			got, ok := FindDensity(testDensities, tt.grade, tt.name)
+			if !ok || got.ID != tt.want {
+				t.Errorf("FindDensity(%q, %q) = %v, %v, want density %d", tt.grade, tt.name, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaterialMass(t *testing.T) {
+	tests := []struct {
+		name       string
+		material   models.Material
+		wantMetre  float64
+		wantSquare float64
+		wantPiece  float64
+		wantOK     bool
+	}{
+		{name: "round bar", material: models.Material{PrimaryName: "Круг 20 Ст3"},
+			wantMetre: math.Pi * 100 * 7850 / 1e6, wantOK: true},
+		{name: "pipe", material: models.Material{PrimaryName: "Труба 57х3,5"},
+			wantMetre: math.Pi * 53.5 * 3.5 * 7850 / 1e6, wantOK: true},
+		{name: "shaped pipe", material: models.Material{PrimaryName: "Труба 40х20х2"},
+			wantMetre: 2 * 2 * (40 + 20 - 4) * 7850 / 1e6, wantOK: true},
+		{name: "angle", material: models.Material{PrimaryName: "Уголок 50х5"},
+			wantMetre: 5 * (50 + 50 - 5) * 7850 / 1e6, wantOK: true},
+		{name: "sheet of known size", material: models.Material{PrimaryName: "Лист 2х1250х2500 08Х18Н10"},
+			wantSquare: 2 * 7.9, wantPiece: 2 * 7.9 * 1.25 * 2.5, wantOK: true},
+		{name: "sizes from attributes", material: models.Material{PrimaryName: "Пруток",
+			Category:   models.Category{Name: "Круг"},
+			Attributes: []models.AttributeValue{{Name: "Диаметр", Kind: models.AttributeNumber, Value: "20"}, {Name: "Длина", Kind: models.AttributeNumber, Value: "3000"}}},
+			wantMetre: math.Pi * 100 * 7850 / 1e6, wantPiece: math.Pi * 100 * 7850 / 1e6 * 3, wantOK: true},
+		{name: "disc", material: models.Material{PrimaryName: "Круг отрезной 125х1,2х22"}},
+		{name: "not a profile", material: models.Material{PrimaryName: "Болт М10х40"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := MaterialMass(tt.material, testDensities)
+			if ok != tt.wantOK {
+				t.Fatalf("MaterialMass(%q) ok = %v, want %v", tt.material.PrimaryName, ok, tt.wantOK)
+			}
+			for _, c := range []struct {
+				field     string
+				got, want float64
+			}{
+				{"PerMetre", m.PerMetre, tt.wantMetre},
+				{"PerSquareMetre", m.PerSquareMetre, tt.wantSquare},
+				{"PerPiece", m.PerPiece, tt.wantPiece},
+			} {
+				if math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMassConvert(t *testing.T) {
+	sheet := Mass{PerSquareMetre: 15.8, PerPiece: 49.375, Sheet: true}
+	bar := Mass{PerMetre: 2.5}
+	tests := []struct {
+		name     string
+		mass     Mass
+		amount   float64
+		from, to string
+		want     float64
+		wantOK   bool
+	}{
+		{name: "metres to kg", mass: bar, amount: 6, from: "м", to: "кг", want: 15, wantOK: true},
+		{name: "kg to metres", mass: bar, amount: 15, from: "кг.", to: "пог.м", want: 6, wantOK: true},
+		{name: "mm to tonnes", mass: bar, amount: 2000, from: "мм", to: "т", want: 0.005, wantOK: true},
+		{name: "sheets to m²", mass: sheet, amount: 2, from: "лист", to: "м2", want: 6.25, wantOK: true},
+		{name: "unknown piece mass", mass: bar, amount: 1, from: "шт", to: "кг"},
+		{name: "unknown unit", mass: bar, amount: 1, from: "упак", to: "кг"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.mass.Convert(tt.amount, tt.from, tt.to)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Convert(%v, %q, %q) = %v, %v, want %v, %v", tt.amount, tt.from, tt.to, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
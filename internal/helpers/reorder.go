@@ -0,0 +1,172 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// reorderTolerance keeps stock equal to level from alerting because of rounding.
+const reorderTolerance = 1e-9
+
+// ReorderLine is stock of material with levels and demand of open production orders and saved scenarios.
+// All quantities are in current unit of material.
+type ReorderLine struct {
+	Material  models.Material
+	Level     models.StockLevel
+	Stock     float64
+	Orders    float64
+	Scenarios float64
+}
+
+// Projected is stock left after open orders and scenarios are made.
+func (l ReorderLine) Projected() float64 {
+	return l.Stock - l.Orders - l.Scenarios
+}
+
+// BelowMinimum reports that stock is below minimum now.
+func (l ReorderLine) BelowMinimum() bool {
+	return l.Stock < l.Level.Minimum-reorderTolerance
+}
+
+// ProjectedBelowMinimum reports that stock falls below minimum, or below zero when minimum isn't set,
+// after open orders and scenarios.
+func (l ReorderLine) ProjectedBelowMinimum() bool {
+	return l.Projected() < l.Level.Minimum-reorderTolerance
+}
+
+// AtReorderPoint reports that projected stock reached reorder point.
+func (l ReorderLine) AtReorderPoint() bool {
+	return l.Level.ReorderPoint > 0 && l.Projected() <= l.Level.ReorderPoint+reorderTolerance
+}
+
+// Alert reports that material has to be ordered.
+func (l ReorderLine) Alert() bool {
+	return l.BelowMinimum() || l.ProjectedBelowMinimum() || l.AtReorderPoint()
+}
+
+// OrderQuantity is quantity to order for material with alert: reorder quantity, or more when it isn't enough
+// to bring projected stock back to minimum.
+func (l ReorderLine) OrderQuantity() float64 {
+	if !l.Alert() {
+		return 0
+	}
+	return max(l.Level.ReorderQuantity, l.Level.Minimum-l.Projected(), 0)
+}
+
+// ReorderLines compares stock of materials that have levels with demand of open orders and scenarios.
+// Demand in unit material had before is ignored like balances in MaterialStock. Lines keep order of materials.
+func ReorderLines(materials []models.Material, levels []models.StockLevel, stock map[int64]float64,
+	orders []models.MaterialDemand, scenarios []models.Scenario) []ReorderLine {
+	byMaterial := make(map[int64]models.StockLevel, len(levels))
+	for _, level := range levels {
+		byMaterial[level.MaterialID] = level
+	}
+	units := make(map[int64]string, len(materials))
+	for _, material := range materials {
+		units[material.ID] = material.Unit.Name
+	}
+	orderDemand := make(map[int64]float64)
+	for _, demand := range orders {
+		if units[demand.MaterialID] == demand.Unit {
+			orderDemand[demand.MaterialID] += demand.Quantity
+		}
+	}
+	scenarioDemand := make(map[int64]float64)
+	for _, scenario := range scenarios {
+		for _, material := range scenario.Result.Materials {
+			if units[material.ID] == material.Unit {
+				scenarioDemand[material.ID] += material.Gross
+			}
+		}
+	}
+
+	var lines []ReorderLine
+	for _, material := range materials {
+		level, ok := byMaterial[material.ID]
+		if !ok {
+			continue
+		}
+		lines = append(lines, ReorderLine{
+			Material:  material,
+			Level:     level,
+			Stock:     stock[material.ID],
+			Orders:    orderDemand[material.ID],
+			Scenarios: scenarioDemand[material.ID],
+		})
+	}
+	return lines
+}
+
+// ReorderAlerts returns lines of materials that have to be ordered.
+func ReorderAlerts(lines []ReorderLine) []ReorderLine {
+	var alerts []ReorderLine
+	for _, line := range lines {
+		if line.Alert() {
+			alerts = append(alerts, line)
+		}
+	}
+	return alerts
+}
+
+// Digest is daily summary of materials that have to be ordered, it's posted to webhook as JSON.
+type Digest struct {
+	Date      string           `json:"date"`
+	Text      string           `json:"text"`
+	Materials []DigestMaterial `json:"materials"`
+}
+
+type DigestMaterial struct {
+	ID            int64   `json:"id"`
+	Name          string  `json:"name"`
+	Unit          string  `json:"unit"`
+	Stock         float64 `json:"stock"`
+	Orders        float64 `json:"orders"`
+	Scenarios     float64 `json:"scenarios"`
+	Projected     float64 `json:"projected"`
+	Minimum       float64 `json:"minimum"`
+	ReorderPoint  float64 `json:"reorder_point"`
+	OrderQuantity float64 `json:"order_quantity"`
+	BelowMinimum  bool    `json:"below_minimum"`
+}
+
+// NewDigest makes digest of alerts, text lists one material per line.
+func NewDigest(alerts []ReorderLine, at time.Time) Digest {
+	digest := Digest{Date: at.Format(time.DateTime), Materials: make([]DigestMaterial, 0, len(alerts))}
+	var text strings.Builder
+	fmt.Fprintf(&text, "Сводка по запасам на %s\n", at.Format("2006-01-02 15:04"))
+	if len(alerts) == 0 {
+		text.WriteString("Все материалы выше минимума и точки заказа.\n")
+	} else {
+		fmt.Fprintf(&text, "Материалов к заказу: %d\n\n", len(alerts))
+	}
+	for _, line := range alerts {
+		fmt.Fprintf(&text, "%s, %s: остаток %s, заказы %s, сценарии %s, прогноз %s, минимум %s, точка заказа %s, заказать %s\n",
+			line.Material.PrimaryName, line.Material.Unit.Name,
+			exportNumber(line.Stock), exportNumber(line.Orders), exportNumber(line.Scenarios), exportNumber(line.Projected()),
+			exportNumber(line.Level.Minimum), exportNumber(line.Level.ReorderPoint), exportNumber(line.OrderQuantity()))
+		digest.Materials = append(digest.Materials, DigestMaterial{
+			ID:            line.Material.ID,
+			Name:          line.Material.PrimaryName,
+			Unit:          line.Material.Unit.Name,
+			Stock:         digestNumber(line.Stock),
+			Orders:        digestNumber(line.Orders),
+			Scenarios:     digestNumber(line.Scenarios),
+			Projected:     digestNumber(line.Projected()),
+			Minimum:       line.Level.Minimum,
+			ReorderPoint:  line.Level.ReorderPoint,
+			OrderQuantity: digestNumber(line.OrderQuantity()),
+			BelowMinimum:  line.BelowMinimum(),
+		})
+	}
+	digest.Text = text.String()
+	return digest
+}
+
+// digestNumber rounds amount to thousandths like exportNumber.
+func digestNumber(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// revisionLength is number of hex digits of BOM revision, enough to tell revisions of few products apart.
+const revisionLength = 12
+
+// BOMRevision returns fingerprint of BOM lines of plan products. It changes when a line is added or removed,
+// or when quantity, scrap or yield of a line changes.
+func BOMRevision(lines []PlanLine) string {
+	products := make([]models.Product, len(lines))
+	for i, line := range lines {
+		products[i] = line.Product
+	}
+	slices.SortFunc(products, func(a, b models.Product) int { return cmp.Compare(a.ID, b.ID) })
+
+	hash := sha256.New()
+	for _, product := range products {
+		materials := slices.Clone(product.Materials)
+		slices.SortFunc(materials, func(a, b models.Material) int { return cmp.Compare(a.ID, b.ID) })
+		fmt.Fprintf(hash, "product %d\n", product.ID)
+		for _, m := range materials {
+			scrap := ""
+			if m.ScrapPercent != nil {
+				scrap = strconv.FormatFloat(*m.ScrapPercent, 'g', -1, 64)
+			}
+			fmt.Fprintf(hash, "%d %s %s %g\n", m.ID, strings.TrimSpace(m.Quantity), scrap, m.YieldPercent)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:revisionLength]
+}
+
+// ScenarioResultOf takes snapshot of plan to store with scenario.
+func ScenarioResultOf(plan ProductionPlan) models.ScenarioResult {
+	var result models.ScenarioResult
+	for _, p := range plan.Products {
+		result.Products = append(result.Products, models.ScenarioProduct{
+			ID:         p.Product.ID,
+			Name:       p.Product.Name,
+			Quantity:   p.Quantity,
+			CanProduce: p.CanProduce,
+			Limiting:   p.Limiting.PrimaryName,
+		})
+	}
+	for _, r := range plan.Requirements {
+		result.Materials = append(result.Materials, models.ScenarioMaterial{
+			ID:       r.Material.ID,
+			Name:     r.Material.PrimaryName,
+			Unit:     r.Material.Unit.Name,
+			Gross:    r.Gross,
+			Stock:    r.Stock,
+			Shortage: r.Shortage,
+		})
+	}
+	return result
+}
+
+// MaterialChange is requirement of material in saved scenario and in its re-run.
+// Material missing on one side has zero amounts there.
+type MaterialChange struct {
+	Name          string
+	Unit          string
+	SavedGross    float64
+	Gross         float64
+	SavedShortage float64
+	Shortage      float64
+}
+
+// Changed reports whether requirement or shortage differs, differences after rounding to display precision are ignored.
+func (c MaterialChange) Changed() bool {
+	return rounded(c.SavedGross) != rounded(c.Gross) || rounded(c.SavedShortage) != rounded(c.Shortage)
+}
+
+// ProductChange is result of product in saved scenario and in its re-run.
+// Removed product was deleted after saving, it's missing in re-run and has zero current values.
+type ProductChange struct {
+	Name            string
+	Quantity        int64
+	SavedCanProduce int64
+	CanProduce      int64
+	SavedLimiting   string
+	Limiting        string
+	Removed         bool
+}
+
+// Changed reports whether product was removed or number of products or limiting material differs.
+func (c ProductChange) Changed() bool {
+	return c.Removed || c.SavedCanProduce != c.CanProduce || c.SavedLimiting != c.Limiting
+}
+
+// CompareScenario pairs saved result with re-run by IDs of products and materials.
+// Saved order is kept, items that appeared in re-run follow. Saved product missing in re-run is removed.
+func CompareScenario(saved, current models.ScenarioResult) ([]ProductChange, []MaterialChange) {
+	var products []ProductChange
+	productIndex := make(map[int64]int)
+	for _, p := range saved.Products {
+		productIndex[p.ID] = len(products)
+		products = append(products, ProductChange{Name: p.Name, Quantity: p.Quantity, SavedCanProduce: p.CanProduce, SavedLimiting: p.Limiting, Removed: true})
+	}
+	for _, p := range current.Products {
+		i, ok := productIndex[p.ID]
+		if !ok {
+			i = len(products)
+			products = append(products, ProductChange{Quantity: p.Quantity})
+		}
+		products[i].Removed = false
+		products[i].Name = p.Name
+		products[i].CanProduce = p.CanProduce
+		products[i].Limiting = p.Limiting
+	}
+
+	var materials []MaterialChange
+	materialIndex := make(map[int64]int)
+	for _, m := range saved.Materials {
+		materialIndex[m.ID] = len(materials)
+		materials = append(materials, MaterialChange{Name: m.Name, Unit: m.Unit, SavedGross: m.Gross, SavedShortage: m.Shortage})
+	}
+	for _, m := range current.Materials {
+		i, ok := materialIndex[m.ID]
+		if !ok {
+			i = len(materials)
+			materials = append(materials, MaterialChange{})
+		}
+		materials[i].Name = m.Name
+		materials[i].Unit = m.Unit
+		materials[i].Gross = m.Gross
+		materials[i].Shortage = m.Shortage
+	}
+	return products, materials
+}
+
+// RemovedProducts returns names of products of saved result with IDs from removed.
+func RemovedProducts(saved models.ScenarioResult, removed []int64) []string {
+	var names []string
+	for _, p := range saved.Products {
+		if slices.Contains(removed, p.ID) {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// rounded rounds amount to hundredths like amounts are shown.
+func rounded(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// StockRow is balance of material in unit at every location and in total.
+type StockRow struct {
+	MaterialID   int64
+	MaterialName string
+	Unit         string
+	ByLocation   map[int64]float64
+	Total        float64
+}
+
+// StockRows groups balances by material and unit, balances must be ordered by material and unit.
+func StockRows(balances []models.StockBalance) []StockRow {
+	var rows []StockRow
+	for _, balance := range balances {
+		n := len(rows)
+		if n == 0 || rows[n-1].MaterialID != balance.MaterialID || rows[n-1].Unit != balance.Unit {
+			rows = append(rows, StockRow{
+				MaterialID:   balance.MaterialID,
+				MaterialName: balance.MaterialName,
+				Unit:         balance.Unit,
+				ByLocation:   make(map[int64]float64),
+			})
+			n++
+		}
+		rows[n-1].ByLocation[balance.Location.ID] += balance.Quantity
+		rows[n-1].Total += balance.Quantity
+	}
+	return rows
+}
+
+// MaterialStock sums balances of materials over locations. Only balances in current unit of material
+// are counted, balances left in unit material had before are ignored.
+// Balances at location are summed when location ID isn't 0.
+func MaterialStock(balances []models.StockBalance, materials []models.Material, locationID int64) map[int64]float64 {
+	units := make(map[int64]string, len(materials))
+	for _, material := range materials {
+		units[material.ID] = material.Unit.Name
+	}
+	stock := make(map[int64]float64)
+	for _, balance := range balances {
+		unit, ok := units[balance.MaterialID]
+		if !ok || unit != balance.Unit || (locationID != 0 && balance.Location.ID != locationID) {
+			continue
+		}
+		stock[balance.MaterialID] += balance.Quantity
+	}
+	return stock
+}
@@ -0,0 +1,225 @@
+package helpers
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s-588/BOMViewer/internal/models"
+)
+
+// PageSizes are sizes offered for tables, 0 shows all rows.
+var PageSizes = []int{0, 20, 50, 100}
+
+// TableView is state of materials or products table. It's kept in query parameters,
+// so a link or a saved view restores the same filters, sort, columns and page.
+type TableView struct {
+	Sort        string
+	UnitIDs     []int64
+	ProductIDs  []int64
+	MaterialIDs []int64
+	// CategoryIDs are categories with their subcategories, 0 selects materials without category.
+	CategoryIDs []int64
+	Tags        []string
+	// Attributes are kept in parameters attr_<id>, attr_<id>_min and attr_<id>_max.
+	Attributes  []AttributeFilter
+	PrimaryOnly bool
+	// Columns are optional columns shown besides name and actions.
+	Columns  []string
+	PageSize int
+	// Page starts from 1.
+	Page int
+}
+
+// ParseTableView reads table state from query or form values, invalid values are ignored.
+func ParseTableView(values url.Values) TableView {
+	view := TableView{
+		Sort:        values.Get("sort"),
+		PrimaryOnly: values.Get("primary_only") == "1",
+		Page:        1,
+	}
+	view.UnitIDs, _ = StringToInt64Slice(values["units"])
+	view.ProductIDs, _ = StringToInt64Slice(values["products"])
+	view.MaterialIDs, _ = StringToInt64Slice(values["materials"])
+	view.CategoryIDs, _ = StringToInt64Slice(values["categories"])
+	for _, tag := range values["tags"] {
+		if tag != "" && !slices.Contains(view.Tags, tag) {
+			view.Tags = append(view.Tags, tag)
+		}
+	}
+	view.Attributes = parseAttributeFilters(values)
+	for _, column := range values["cols"] {
+		if column != "" && !slices.Contains(view.Columns, column) {
+			view.Columns = append(view.Columns, column)
+		}
+	}
+	if size, err := strconv.Atoi(values.Get("size")); err == nil && slices.Contains(PageSizes, size) {
+		view.PageSize = size
+	}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 1 {
+		view.Page = page
+	}
+	return view
+}
+
+// Values encodes state back to query parameters, default values are omitted.
+func (v TableView) Values() url.Values {
+	values := url.Values{}
+	if v.Sort != "" {
+		values.Set("sort", v.Sort)
+	}
+	addIDs(values, "units", v.UnitIDs)
+	addIDs(values, "products", v.ProductIDs)
+	addIDs(values, "materials", v.MaterialIDs)
+	addIDs(values, "categories", v.CategoryIDs)
+	for _, tag := range v.Tags {
+		values.Add("tags", tag)
+	}
+	for _, filter := range v.Attributes {
+		key := "attr_" + strconv.FormatInt(filter.AttributeID, 10)
+		for _, value := range filter.Values {
+			values.Add(key, value)
+		}
+		if filter.Min != nil {
+			values.Set(key+"_min", strconv.FormatFloat(*filter.Min, 'f', -1, 64))
+		}
+		if filter.Max != nil {
+			values.Set(key+"_max", strconv.FormatFloat(*filter.Max, 'f', -1, 64))
+		}
+	}
+	if v.PrimaryOnly {
+		values.Set("primary_only", "1")
+	}
+	for _, column := range v.Columns {
+		values.Add("cols", column)
+	}
+	if v.PageSize != 0 {
+		values.Set("size", strconv.Itoa(v.PageSize))
+	}
+	if v.Page > 1 {
+		values.Set("page", strconv.Itoa(v.Page))
+	}
+	return values
+}
+
+// WithPage returns copy of view that shows page.
+func (v TableView) WithPage(page int) TableView {
+	v.Page = page
+	return v
+}
+
+// AttributeFilter returns filter of attribute, zero filter when attribute isn't filtered.
+func (v TableView) AttributeFilter(attributeID int64) AttributeFilter {
+	for _, filter := range v.Attributes {
+		if filter.AttributeID == attributeID {
+			return filter
+		}
+	}
+	return AttributeFilter{AttributeID: attributeID}
+}
+
+// parseAttributeFilters collects attr_<id> parameters, filters are ordered by attribute ID.
+// Empty values are sent by unfilled inputs of controls form and are ignored.
+func parseAttributeFilters(values url.Values) []AttributeFilter {
+	filters := make(map[int64]*AttributeFilter)
+	get := func(id int64) *AttributeFilter {
+		if filters[id] == nil {
+			filters[id] = &AttributeFilter{AttributeID: id}
+		}
+		return filters[id]
+	}
+	for key, list := range values {
+		name, ok := strings.CutPrefix(key, "attr_")
+		if !ok {
+			continue
+		}
+		name, bound, _ := strings.Cut(name, "_")
+		id, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch bound {
+		case "":
+			for _, value := range list {
+				if value = strings.TrimSpace(value); value != "" {
+					get(id).Values = append(get(id).Values, value)
+				}
+			}
+		case "min", "max":
+			number, err := parseQuantity(values.Get(key))
+			if err != nil {
+				continue
+			}
+			if bound == "min" {
+				get(id).Min = &number
+			} else {
+				get(id).Max = &number
+			}
+		}
+	}
+	result := make([]AttributeFilter, 0, len(filters))
+	for _, filter := range filters {
+		result = append(result, *filter)
+	}
+	slices.SortFunc(result, func(a, b AttributeFilter) int { return cmp.Compare(a.AttributeID, b.AttributeID) })
+	return result
+}
+
+// HasColumn reports whether optional column is shown.
+func (v TableView) HasColumn(column string) bool {
+	return slices.Contains(v.Columns, column)
+}
+
+// PageCount returns number of pages for total rows.
+func (v TableView) PageCount(total int) int {
+	if v.PageSize == 0 || total == 0 {
+		return 1
+	}
+	return (total + v.PageSize - 1) / v.PageSize
+}
+
+// Paginate returns rows of current page. Page after the last one is moved to the last page.
+func Paginate[T any](rows []T, view *TableView) []T {
+	if view.PageSize == 0 {
+		view.Page = 1
+		return rows
+	}
+	view.Page = min(max(view.Page, 1), view.PageCount(len(rows)))
+	start := (view.Page - 1) * view.PageSize
+	return rows[start:min(start+view.PageSize, len(rows))]
+}
+
+// SearchValues encodes query and filter of search results page.
+func SearchValues(itemType, q string, filter models.SearchFilter) url.Values {
+	values := url.Values{}
+	values.Set("view", "page")
+	values.Set("type", itemType)
+	values.Set("q", q)
+	addIDs(values, "units", filter.UnitIDs)
+	addIDs(values, "products", filter.ProductIDs)
+	addIDs(values, "materials", filter.MaterialIDs)
+	addIDs(values, "categories", filter.CategoryIDs)
+	if filter.HasFiles {
+		values.Set("files", "1")
+	}
+	return values
+}
+
+// ParseSearchFilter reads filter of search results from query or form values.
+func ParseSearchFilter(values url.Values) models.SearchFilter {
+	var filter models.SearchFilter
+	filter.UnitIDs, _ = StringToInt64Slice(values["units"])
+	filter.ProductIDs, _ = StringToInt64Slice(values["products"])
+	filter.MaterialIDs, _ = StringToInt64Slice(values["materials"])
+	filter.CategoryIDs, _ = StringToInt64Slice(values["categories"])
+	filter.HasFiles = values.Get("files") == "1"
+	return filter
+}
+
+func addIDs(values url.Values, key string, ids []int64) {
+	for _, id := range ids {
+		values.Add(key, strconv.FormatInt(id, 10))
+	}
+}
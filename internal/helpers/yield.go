@@ -0,0 +1,20 @@
+package helpers
+
+import "github.com/s-588/BOMViewer/internal/models"
+
+// LineYield returns yield of material in BOM line as fraction of 1.
+// Scrap percentage of line overrides default yield of material.
+func LineYield(material models.Material) float64 {
+	if material.ScrapPercent != nil {
+		return 1 - *material.ScrapPercent/100
+	}
+	if material.YieldPercent > 0 && material.YieldPercent < 100 {
+		return material.YieldPercent / 100
+	}
+	return 1
+}
+
+// GrossQuantity returns quantity to issue for net quantity of material in BOM line.
+func GrossQuantity(net float64, material models.Material) float64 {
+	return net / LineYield(material)
+}
@@ -1,28 +1,105 @@
 package models
 
+import "time"
+
 type Material struct {
-	ID          int64
-	Names       []string
-	PrimaryName string
-	Unit        Unit
-	Description string
-	Quantity    string
-	Products    []Product
+	ID          int64     `json:"id"`
+	Names       []string  `json:"names,omitempty"`
+	PrimaryName string    `json:"primary_name,omitempty"`
+	Unit        Unit      `json:"unit,omitzero"`
+	Category    Category  `json:"category,omitzero"`
+	Description string    `json:"description,omitempty"`
+	Quantity    string    `json:"quantity,omitempty"`
+	Products    []Product `json:"products,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	// Attributes are values of custom attributes, attributes without value are omitted.
+	Attributes []AttributeValue `json:"attributes,omitempty"`
+	// Cuts are pieces cut from stock bars for one product, only BOM lines have them.
+	Cuts []Cut `json:"cuts,omitempty"`
+	// Blanks are rectangles cut from sheets for one product, only BOM lines have them.
+	Blanks []Blank `json:"blanks,omitempty"`
+	// YieldPercent is share of gross quantity that goes into products, 100 is material without technological waste.
+	YieldPercent float64 `json:"yield_percent,omitempty"`
+	// ScrapPercent is technological waste of BOM line, nil means that yield of material is used.
+	ScrapPercent *float64 `json:"scrap_percent,omitempty"`
+}
+
+// Cut is Quantity pieces of Length in mm.
+type Cut struct {
+	Length   float64 `json:"length"`
+	Quantity int64   `json:"quantity"`
+}
+
+// Blank is Quantity rectangles of Width×Height in mm.
+type Blank struct {
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Quantity int64   `json:"quantity"`
 }
 
 type Unit struct {
-	ID   int64
-	Name string
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Category groups materials, categories form a tree by ParentID, 0 is the root.
+type Category struct {
+	ID       int64  `json:"id"`
+	ParentID int64  `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+	// MaterialCount is number of materials directly in category, it isn't exported.
+	MaterialCount int64 `json:"-"`
 }
 
 type Product struct {
-	ID          int64
-	Name        string
-	Description string
+	ID          int64  `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
 	// How many of material used in this product.
 	// This field used only in MaterialView situation where list of products use same material.
-	Quantity  string
-	Materials []Material
+	Quantity   string           `json:"quantity,omitempty"`
+	Materials  []Material       `json:"materials,omitempty"`
+	Tags       []string         `json:"tags,omitempty"`
+	Attributes []AttributeValue `json:"attributes,omitempty"`
+}
+
+// Attribute is a definition of typed custom property of materials and products.
+// Attribute with CategoryID applies to materials of the category and its subcategories,
+// attribute without category applies to all materials and products.
+type Attribute struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Unit is shown after number value.
+	Unit string `json:"unit,omitempty"`
+	// Options are allowed values of enum attribute.
+	Options    []string `json:"options,omitempty"`
+	CategoryID int64    `json:"category_id,omitempty"`
+}
+
+// Values of Attribute.Kind.
+const (
+	AttributeText   = "text"
+	AttributeNumber = "number"
+	AttributeEnum   = "enum"
+	AttributeBool   = "bool"
+)
+
+// AttributeValue is a value of attribute set for material or product.
+// Number is stored without unit, boolean is "true" or "false".
+type AttributeValue struct {
+	AttributeID int64  `json:"attribute_id"`
+	Name        string `json:"-"`
+	Kind        string `json:"-"`
+	Unit        string `json:"-"`
+	Value       string `json:"value"`
+}
+
+// Density is density in kg/m³ of steel grade or material, Name is matched against grade or material name.
+type Density struct {
+	ID      int64   `json:"id"`
+	Name    string  `json:"name"`
+	Density float64 `json:"density"`
 }
 
 type File struct {
@@ -37,6 +114,7 @@ type CalculationResult struct {
 	MaterialID       int64
 	MaterialName     string
 	RequiredPerUnit  string
+	RequiredNet      string
 	RequiredTotal    string
 	Remaining        string
 	CanProduce       int
@@ -44,4 +122,262 @@ type CalculationResult struct {
 	Unit             string
 	ProductID        int64
 	IsCalculable     bool
+	// CategoryID and numeric amounts in unit of material are used to sum requirements by category
+	// and to export results, Stock is remaining as entered converted to unit of material.
+	CategoryID int64
+	Net        float64
+	Total      float64
+	Stock      float64
+	Shortage   float64
+	// Conversions are totals in other units by theoretical mass, they are empty when mass is unknown.
+	Conversions []Conversion
+	// RemainingUnits are units remaining can be entered in, RemainingUnit is the one it was entered in.
+	RemainingUnits []string
+	RemainingUnit  string
+	// Sheets is number of sheets of SheetSize counted by nesting of blanks, totals of such result are those sheets.
+	Sheets      int
+	SheetSize   string
+	Utilization float64
+	// YieldPercent is yield of BOM line, totals are gross requirements net quantity is divided by it.
+	YieldPercent float64
+}
+
+// Conversion is total and shortage of material converted to other unit.
+type Conversion struct {
+	Unit     string
+	Total    float64
+	Shortage float64
+}
+
+// SearchResult is a material or product found by search.
+// Name and Snippet are split to fragments, so matched words can be highlighted.
+type SearchResult struct {
+	Type string // "material" or "product"
+	ID   int64
+	Name []TextFragment
+	// Snippet is an excerpt of field that matched query, it's empty when only name matched
+	// and item has no description.
+	Snippet      []TextFragment
+	MatchedField string
+	// File is name of attached document with matched text, snippet is taken from it.
+	File string
+}
+
+// Values of SearchResult.MatchedField.
+const (
+	MatchedName        = "название"
+	MatchedOtherName   = "другое название"
+	MatchedDescription = "описание"
+	MatchedRelated     = "связанные данные"
+	MatchedFile        = "вложение"
+)
+
+// TextFragment is a part of text, Match is set for words that matched search query.
+type TextFragment struct {
+	Text  string
+	Match bool
+}
+
+// SearchPage is one page of search results with total counts of found items by type.
+type SearchPage struct {
+	Results       []SearchResult
+	MaterialCount int64
+	ProductCount  int64
+	// Facets are counts of all found items, they narrow search when added to SearchFilter.
+	Units    []SearchFacet
+	Products []SearchFacet
+	// Categories are counts of found materials directly in category, ID 0 is materials without category.
+	Categories []SearchFacet
+	WithFiles  int64
+	// Fuzzy is set when nothing was found by query as is and results are found by similar words.
+	Fuzzy bool
+}
+
+// SearchFilter narrows search results, empty filter finds everything.
+// Fields have the same meaning as in material and product table filters.
+type SearchFilter struct {
+	UnitIDs    []int64 // materials with any of units
+	ProductIDs []int64 // materials used in any of products
+	// Products that use any of materials.
+	MaterialIDs []int64
+	// Materials in any of categories or their subcategories, 0 matches materials without category.
+	CategoryIDs []int64
+	HasFiles    bool
+}
+
+// SearchFacet is a value of unit or product with number of found items that have it.
+type SearchFacet struct {
+	ID    int64
+	Name  string
+	Count int64
+}
+
+// CatalogFormatVersion is a version of Catalog document layout.
+// Increase it only when the layout changes in incompatible way.
+const CatalogFormatVersion = 1
+
+// Catalog is a portable dump of the whole database content.
+// All entities keep their database IDs, so references between them stay valid after loading.
+// Only file metadata is included, file contents must be copied separately.
+type Catalog struct {
+	FormatVersion int       `json:"format_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Units         []Unit    `json:"units"`
+	// Categories is missing in dumps made before categories were added, then seeded categories are kept.
+	Categories []Category `json:"categories,omitempty"`
+	// Attributes is missing in dumps made before attributes were added, then seeded attributes are kept.
+	Attributes []Attribute `json:"attributes,omitempty"`
+	// Densities is missing in dumps made before densities were added, then seeded densities are kept.
+	Densities []Density  `json:"densities,omitempty"`
+	Materials []Material `json:"materials"`
+	// Products contains BOM lines in Materials field, each line holds only material ID and quantity.
+	Products  []Product  `json:"products"`
+	Files     []File     `json:"files"`
+	FileLinks []FileLink `json:"file_links"`
+	// StockLevels is missing in dumps made before stock levels were added, then materials have no levels.
+	StockLevels []StockLevel `json:"stock_levels,omitempty"`
+}
+
+// FileLink attaches file to material or product. Exactly one of MaterialID and ProductID is set.
+type FileLink struct {
+	FileID     int64 `json:"file_id"`
+	MaterialID int64 `json:"material_id,omitempty"`
+	ProductID  int64 `json:"product_id,omitempty"`
+}
+
+// SavedView is a named state of materials or products table or a saved search.
+// Query holds URL query parameters of the page.
+type SavedView struct {
+	ID    int64
+	Kind  string
+	Name  string
+	Query string
+}
+
+// Scenario is saved input of production plan with result computed against BOM revision.
+// Query holds plan form as URL query parameters. Planned scenario is demand of production plan,
+// reorder widget and digest count only planned scenarios.
+type Scenario struct {
+	ID       int64
+	Name     string
+	Query    string
+	Revision string
+	SavedAt  string
+	Planned  bool
+	Result   ScenarioResult
+}
+
+// ScenarioResult is snapshot of plan, it's kept to compare scenario with re-run after BOM changes.
+type ScenarioResult struct {
+	Products  []ScenarioProduct  `json:"products"`
+	Materials []ScenarioMaterial `json:"materials"`
+}
+
+type ScenarioProduct struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Quantity   int64  `json:"quantity"`
+	CanProduce int64  `json:"can_produce"`
+	Limiting   string `json:"limiting,omitempty"`
+}
+
+type ScenarioMaterial struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Unit     string  `json:"unit"`
+	Gross    float64 `json:"gross"`
+	Stock    float64 `json:"stock"`
+	Shortage float64 `json:"shortage"`
+}
+
+// Values of SavedView.Kind, they are also paths of pages.
+const (
+	ViewMaterials = "materials"
+	ViewProducts  = "products"
+	ViewSearch    = "search"
+)
+
+// Location is warehouse or other place materials are stored at.
+type Location struct {
+	ID   int64
+	Name string
+}
+
+// Kinds of stock movements.
+const (
+	MovementReceipt    = "receipt"
+	MovementIssue      = "issue"
+	MovementTransfer   = "transfer"
+	MovementAdjustment = "adjustment"
+)
+
+// StockMovement moves Quantity of material in Unit out of From location into To location.
+// Receipt has no From, issue has no To, adjustment has one of them. Missing location has zero ID.
+// OrderID is production order that consumed material, it's 0 for other movements.
+type StockMovement struct {
+	ID           int64
+	MaterialID   int64
+	MaterialName string
+	Unit         string
+	Kind         string
+	Quantity     float64
+	From         Location
+	To           Location
+	Date         string
+	Comment      string
+	OrderID      int64
+}
+
+// StockBalance is quantity of material in unit at location.
+type StockBalance struct {
+	MaterialID   int64
+	MaterialName string
+	Unit         string
+	Location     Location
+	Quantity     float64
+}
+
+// ProductionOrder is order to build Quantity of product with materials issued from Location.
+// Order is open while CompletedOn is empty. ProductID is 0 when product was deleted.
+type ProductionOrder struct {
+	ID          int64
+	ProductID   int64
+	ProductName string
+	Quantity    int64
+	Location    Location
+	CreatedOn   string
+	CompletedOn string
+	Comment     string
+	Lines       []ProductionOrderLine
+	// Overused is number of lines whose actual consumption exceeds norm, it's filled in list of orders.
+	Overused int
+}
+
+// ProductionOrderLine is BOM line exploded for quantity of order. BOMQuantity is quantity per product
+// as written in BOM. Norm is nil for text quantity and Actual is nil until order is completed.
+// MaterialID is 0 when material was deleted.
+type ProductionOrderLine struct {
+	ID           int64
+	MaterialID   int64
+	MaterialName string
+	Unit         string
+	BOMQuantity  string
+	Norm         *float64
+	Actual       *float64
+}
+
+// StockLevel is minimum stock, reorder point and reorder quantity of material in its current unit.
+// Zero level isn't checked.
+type StockLevel struct {
+	MaterialID      int64   `json:"material_id"`
+	Minimum         float64 `json:"minimum"`
+	ReorderPoint    float64 `json:"reorder_point"`
+	ReorderQuantity float64 `json:"reorder_quantity"`
+}
+
+// MaterialDemand is quantity of material in unit that is going to be consumed.
+type MaterialDemand struct {
+	MaterialID int64
+	Unit       string
+	Quantity   float64
 }
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCandidates limits number of similar terms used for one word of query.
+const maxCandidates = 10
+
+// FuzzyQuery builds FTS5 query like Query, but every word is replaced by similar terms
+// from vocabulary of the index, so query with a typo still finds something.
+// Short words and numbers are kept as is. Empty string is returned
+// when some word has no similar terms.
+func FuzzyQuery(input string, vocabulary []string) string {
+	var groups []string
+	for _, word := range words(input) {
+		for _, term := range wordTerms(word, false) {
+			if isNumber(term) || isDimension(term) || utf8.RuneCountInString(term) < 4 {
+				groups = append(groups, quote(term)+"*")
+				continue
+			}
+			candidates := similarTerms(term, vocabulary)
+			if len(candidates) == 0 {
+				return ""
+			}
+			parts := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				parts = append(parts, quote(candidate)+"*")
+			}
+			groups = append(groups, "("+strings.Join(parts, " OR ")+")")
+		}
+	}
+	return strings.Join(groups, " AND ")
+}
+
+// similarTerms returns terms of vocabulary within edit distance from term, the closest first.
+// Term is compared with whole vocabulary term and with its prefix of the same length,
+// because user may be still typing the word.
+func similarTerms(term string, vocabulary []string) []string {
+	word := []rune(term)
+	limit := 1
+	if len(word) >= 6 {
+		limit = 2
+	}
+
+	type candidate struct {
+		term     string
+		distance int
+	}
+	var candidates []candidate
+	for _, v := range vocabulary {
+		other := []rune(v)
+		distance := editDistance(word, other)
+		if len(other) > len(word) {
+			distance = min(distance, editDistance(word, other[:len(word)]))
+		}
+		if distance <= limit {
+			candidates = append(candidates, candidate{v, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	result := make([]string, 0, min(len(candidates), maxCandidates))
+	for _, c := range candidates[:min(len(candidates), maxCandidates)] {
+		result = append(result, c.term)
+	}
+	return result
+}
+
+// editDistance returns Damerau-Levenshtein distance (optimal string alignment variant),
+// swapped neighbour letters are counted as one edit, as it's the most common typo.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
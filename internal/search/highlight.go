@@ -0,0 +1,124 @@
+package search
+
+import "strings"
+
+// Markers that FTS5 highlight() function must put around matched terms,
+// they are passed to it as char(2) and char(3).
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// Fragment is a part of text, Match is set for matched words.
+type Fragment struct {
+	Text  string
+	Match bool
+}
+
+// MatchedTerms returns terms marked in output of FTS5 highlight() over terms column.
+// Index keeps only normalized terms, so words of original text are found by these terms,
+// see Highlight.
+func MatchedTerms(highlighted string) map[string]bool {
+	matched := make(map[string]bool)
+	for {
+		start := strings.Index(highlighted, HighlightStart)
+		if start < 0 {
+			return matched
+		}
+		highlighted = highlighted[start+len(HighlightStart):]
+		end := strings.Index(highlighted, HighlightEnd)
+		if end < 0 {
+			end = len(highlighted)
+		}
+		for _, term := range strings.Fields(highlighted[:end]) {
+			matched[term] = true
+		}
+		highlighted = highlighted[end:]
+	}
+}
+
+// Matches reports whether text has any of matched terms.
+func Matches(text string, matched map[string]bool) bool {
+	for _, t := range tokenize(text) {
+		if tokenMatches(t, matched) {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlight splits text to fragments, words with matched terms are marked.
+func Highlight(text string, matched map[string]bool) []Fragment {
+	return fragments(text, tokenize(text), matched, 0, len(text))
+}
+
+// Snippet returns excerpt of text about size words long around the first matched word.
+// Ellipsis is added where text is cut. Nil is returned for text without words.
+func Snippet(text string, matched map[string]bool, size int) []Fragment {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+	first := 0
+	for i, t := range tokens {
+		if tokenMatches(t, matched) {
+			first = i
+			break
+		}
+	}
+	// Keep a few words before the match, so it's seen in its context.
+	from := max(0, first-size/3)
+	to := min(len(tokens), from+size)
+	from = max(0, to-size)
+
+	result := fragments(text, tokens[from:to], matched, tokens[from].start, tokens[to-1].end)
+	if from > 0 {
+		if result[0].Match {
+			result = append([]Fragment{{}}, result...)
+		}
+		result[0].Text = "… " + result[0].Text
+	}
+	if to < len(tokens) {
+		if result[len(result)-1].Match {
+			result = append(result, Fragment{})
+		}
+		result[len(result)-1].Text += " …"
+	}
+	return result
+}
+
+// fragments splits text[start:end] by tokens, adjacent fragments with the same Match are merged.
+func fragments(text string, tokens []token, matched map[string]bool, start, end int) []Fragment {
+	var result []Fragment
+	add := func(s string, match bool) {
+		if s == "" {
+			return
+		}
+		if n := len(result); n > 0 && result[n-1].Match == match {
+			result[n-1].Text += s
+			return
+		}
+		result = append(result, Fragment{Text: s, Match: match})
+	}
+
+	last := start
+	for _, t := range tokens {
+		if !tokenMatches(t, matched) {
+			continue
+		}
+		add(text[last:t.start], false)
+		add(text[t.start:t.end], true)
+		last = t.end
+	}
+	add(text[last:end], false)
+	return result
+}
+
+func tokenMatches(t token, matched map[string]bool) bool {
+	for _, term := range wordTerms(t.word, true) {
+		if matched[term] {
+			return true
+		}
+	}
+	return false
+}
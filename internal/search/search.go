@@ -0,0 +1,219 @@
+// Package search converts text of materials and products to search terms
+// and user input to FTS5 queries over them.
+//
+// Both sides are normalized the same way: words are lowercased and Russian words are stemmed,
+// so "трубы" finds "Труба". Grade names like "Ст.3сп" are indexed joined and by parts,
+// and dimensions like "20х20х1,5" or "20 x 20 x 1.5" are converted to "20x20x1.5".
+package search
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// dimensionRe matches sizes like "20х20х1,5", "40*4" or "100 × 50", with Cyrillic or Latin "x".
+var dimensionRe = regexp.MustCompile(`\d+(?:[.,]\d+)?(?:\s*[xх×*]\s*\d+(?:[.,]\d+)?)+`)
+
+// Terms splits text to normalized search terms.
+// Term consists only of letters, digits and dot, so FTS5 table must use
+// unicode61 tokenizer with dot in tokenchars.
+func Terms(text string) []string {
+	var terms []string
+	for _, word := range words(text) {
+		terms = append(terms, wordTerms(word, true)...)
+	}
+	return terms
+}
+
+// IndexText returns terms of text joined by space, it's stored in the indexed column of search table.
+func IndexText(text string) string {
+	return strings.Join(Terms(text), " ")
+}
+
+// Query builds FTS5 query that finds items containing all words of input as prefixes.
+// Empty string is returned when input has no words.
+func Query(input string) string {
+	var terms []string
+	for _, word := range words(input) {
+		terms = append(terms, wordTerms(word, false)...)
+	}
+	return prefixQuery(terms)
+}
+
+func prefixQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, quote(term)+"*")
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote makes FTS5 string from term, so dots and keywords like OR are not treated as syntax.
+func quote(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
+
+// token is a normalized word of text, start and end are its byte offsets in original text.
+type token struct {
+	word       string
+	start, end int
+}
+
+// words lowercases text, normalizes dimensions and splits it by spaces and punctuation.
+// Punctuation inside word, like in "ст.3сп" or "1.5", is kept.
+func words(text string) []string {
+	tokens := tokenize(text)
+	result := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, t.word)
+	}
+	return result
+}
+
+// tokenize splits text like words, but also returns position of every word in text,
+// so matched words can be highlighted in original text.
+func tokenize(text string) []token {
+	// offsets[i] is offset in text of i-th byte of normalized text, the last one is end of text.
+	var b strings.Builder
+	offsets := make([]int, 0, len(text)+1)
+	for i, r := range text {
+		r = unicode.ToLower(r)
+		if r == 'ё' {
+			r = 'е'
+		}
+		n, _ := b.WriteRune(r)
+		for range n {
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(text))
+	norm := b.String()
+
+	b.Reset()
+	mapped := make([]int, 0, len(offsets))
+	last := 0
+	for _, m := range dimensionRe.FindAllStringIndex(norm, -1) {
+		b.WriteString(norm[last:m[0]])
+		mapped = append(mapped, offsets[last:m[0]]...)
+		dimension := normalizeDimension(norm[m[0]:m[1]])
+		b.WriteString(dimension)
+		for range len(dimension) {
+			mapped = append(mapped, offsets[m[0]])
+		}
+		last = m[1]
+	}
+	b.WriteString(norm[last:])
+	mapped = append(mapped, offsets[last:]...)
+	norm = b.String()
+
+	var tokens []token
+	start := -1
+	for i, r := range norm + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || isInnerPunct(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		word := norm[start:i]
+		trimmed := strings.TrimLeftFunc(word, isInnerPunct)
+		first := start + len(word) - len(trimmed)
+		trimmed = strings.TrimRightFunc(trimmed, isInnerPunct)
+		if trimmed != "" {
+			tokens = append(tokens, token{
+				word:  trimmed,
+				start: mapped[first],
+				end:   mapped[first+len(trimmed)],
+			})
+		}
+		start = -1
+	}
+	return tokens
+}
+
+// isInnerPunct reports whether r can join parts of one word.
+func isInnerPunct(r rune) bool {
+	switch r {
+	case '.', ',', '-', '/':
+		return true
+	}
+	return false
+}
+
+func normalizeDimension(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+		case r == ',' || r == '.':
+			b.WriteRune('.')
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('x')
+		}
+	}
+	return b.String()
+}
+
+// wordTerms returns terms of one word. Word with punctuation is indexed as a whole without
+// punctuation and by parts, but in query only the whole form is used, because it's the most precise.
+func wordTerms(word string, index bool) []string {
+	if isNumber(word) {
+		return []string{strings.ReplaceAll(word, ",", ".")}
+	}
+	if isDimension(word) {
+		terms := []string{word}
+		if index {
+			terms = append(terms, strings.Split(word, "x")...)
+		}
+		return terms
+	}
+	parts := strings.FieldsFunc(word, isInnerPunct)
+	if len(parts) == 1 {
+		return []string{normalizeWord(word)}
+	}
+
+	terms := []string{strings.Join(parts, "")}
+	if index {
+		for _, part := range parts {
+			terms = append(terms, normalizeWord(part))
+		}
+	}
+	return terms
+}
+
+// normalizeWord stems Russian words and keeps other words as is.
+func normalizeWord(word string) string {
+	for _, r := range word {
+		if !unicode.Is(unicode.Cyrillic, r) {
+			return word
+		}
+	}
+	return Stem(word)
+}
+
+// isNumber reports whether word is an integer or decimal number like "1,5".
+func isNumber(word string) bool {
+	separators := 0
+	for _, r := range word {
+		switch {
+		case unicode.IsDigit(r):
+		case r == '.' || r == ',':
+			separators++
+		default:
+			return false
+		}
+	}
+	return separators <= 1
+}
+
+// isDimension reports whether word was produced by normalizeDimension.
+func isDimension(word string) bool {
+	return strings.Contains(word, "x") && strings.Trim(word, "0123456789.x") == "" &&
+		word[0] != 'x' && word[len(word)-1] != 'x'
+}
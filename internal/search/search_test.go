@@ -0,0 +1,98 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"труба", "труб"},
+		{"трубы", "труб"},
+		{"трубами", "труб"},
+		{"болтов", "болт"},
+		{"гайки", "гайк"},
+		{"изделия", "издел"},
+		{"материалов", "материал"},
+		{"стальная", "стальн"},
+		{"круглые", "кругл"},
+		{"оцинкованный", "оцинкова"},
+		{"нержавеющей", "нержавеющ"},
+		{"сварочная", "сварочн"},
+		{"крепеж", "крепеж"},
+		{"уголок", "уголок"},
+		{"уголки", "уголк"},
+		{"резьбы", "резьб"},
+		{"ст", "ст"},
+		{"лист", "лист"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := Stem(tt.word); got != tt.want {
+				t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Труба 20х20х1,5", []string{"труб", "20x20x1.5", "20", "20", "1.5"}},
+		{"Лист 5 Ст.3сп", []string{"лист", "5", "ст3сп", "ст", "3сп"}},
+		{"Круг 20 x 20 mm", []string{"круг", "20x20", "20", "20", "mm"}},
+		{"Болт М10х40 ГОСТ 7798-70", []string{"болт", "м10x40", "гост", "779870", "7798", "70"}},
+		{"Ёрш, щётка", []string{"ерш", "щетк"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Terms(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"трубы", `"труб"*`},
+		{"Трубы 20 x 20", `"труб"* "20x20"*`},
+		{"ст.3сп", `"ст3сп"*`},
+		{"1,5", `"1.5"*`},
+		{`болт OR "гайка`, `"болт"* "or"* "гайк"*`},
+		{"  ,.- ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Query(tt.input); got != tt.want {
+				t.Errorf("Query(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyQuery(t *testing.T) {
+	vocabulary := []string{"труб", "трубк", "лист", "болт", "20x20"}
+	tests := []struct {
+		input, want string
+	}{
+		{"трбуы", `("труб"* OR "трубк"*)`},
+		{"трубы 20х20", `("труб"* OR "трубк"*) AND "20x20"*`},
+		{"лис", `"лис"*`},
+		{"шайба", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := FuzzyQuery(tt.input, vocabulary); got != tt.want {
+				t.Errorf("FuzzyQuery(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
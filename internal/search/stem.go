@@ -0,0 +1,158 @@
+package search
+
+// Stem returns stem of lowercase Russian word by Snowball algorithm
+// (https://snowballstem.org/algorithms/russian/stemmer.html).
+// Word must use "е" instead of "ё".
+func Stem(word string) string {
+	w := []rune(word)
+	rv, r2 := regions(w)
+	if rv >= len(w) {
+		return word
+	}
+
+	// Step 1
+	if n := matchSuffix(w, rv, perfectiveGerund1, perfectiveGerund2); n > 0 {
+		w = w[:len(w)-n]
+	} else {
+		if n := matchSuffix(w, rv, nil, reflexive); n > 0 {
+			w = w[:len(w)-n]
+		}
+		if n := matchAdjectival(w, rv); n > 0 {
+			w = w[:len(w)-n]
+		} else if n := matchSuffix(w, rv, verb1, verb2); n > 0 {
+			w = w[:len(w)-n]
+		} else if n := matchSuffix(w, rv, nil, noun); n > 0 {
+			w = w[:len(w)-n]
+		}
+	}
+
+	// Step 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Step 3
+	if n := matchSuffix(w, r2, nil, derivational); n > 0 {
+		w = w[:len(w)-n]
+	}
+
+	// Step 4
+	if n := matchSuffix(w, rv, nil, superlative); n > 0 {
+		w = w[:len(w)-n]
+		if hasSuffix(w, rv, "нн") {
+			w = w[:len(w)-1]
+		}
+	} else if hasSuffix(w, rv, "нн") || hasSuffix(w, rv, "ь") {
+		w = w[:len(w)-1]
+	}
+	return string(w)
+}
+
+// Endings of the first groups must be preceded by "а" or "я".
+var (
+	perfectiveGerund1 = []string{"в", "вши", "вшись"}
+	perfectiveGerund2 = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+	adjective         = []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	participle1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle2 = []string{"ивш", "ывш", "ующ"}
+	reflexive   = []string{"ся", "сь"}
+	verb1       = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	verb2       = []string{
+		"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+	}
+	noun = []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	}
+	superlative  = []string{"ейш", "ейше"}
+	derivational = []string{"ост", "ость"}
+)
+
+func isVowel(r rune) bool {
+	switch r {
+	case 'а', 'е', 'и', 'о', 'у', 'ы', 'э', 'ю', 'я':
+		return true
+	}
+	return false
+}
+
+// regions returns start of RV and R2 regions of the word.
+func regions(w []rune) (rv, r2 int) {
+	rv, r1 := len(w), len(w)
+	r2 = len(w)
+	for i, r := range w {
+		if isVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	for i := rv; i < len(w); i++ {
+		if !isVowel(w[i]) {
+			r1 = i + 1
+			break
+		}
+	}
+	for i := r1; i < len(w); i++ {
+		if isVowel(w[i]) {
+			for j := i + 1; j < len(w); j++ {
+				if !isVowel(w[j]) {
+					r2 = j + 1
+					break
+				}
+			}
+			break
+		}
+	}
+	return rv, r2
+}
+
+func hasSuffix(w []rune, limit int, suffix string) bool {
+	s := []rune(suffix)
+	start := len(w) - len(s)
+	if start < limit {
+		return false
+	}
+	for i, r := range s {
+		if w[start+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSuffix returns length of the longest ending from both groups that lies in region starting at limit.
+// Ending from the first group must be preceded by "а" or "я" in the same region.
+// Zero is returned when nothing matches.
+func matchSuffix(w []rune, limit int, group1, group2 []string) int {
+	best, preceded := 0, false
+	for _, suffix := range group1 {
+		if n := len([]rune(suffix)); n > best && hasSuffix(w, limit, suffix) {
+			best, preceded = n, true
+		}
+	}
+	for _, suffix := range group2 {
+		if n := len([]rune(suffix)); n > best && hasSuffix(w, limit, suffix) {
+			best, preceded = n, false
+		}
+	}
+	if preceded {
+		i := len(w) - best - 1
+		if i < limit || (w[i] != 'а' && w[i] != 'я') {
+			return 0
+		}
+	}
+	return best
+}
+
+// matchAdjectival returns length of adjective ending optionally preceded by participle ending.
+func matchAdjectival(w []rune, limit int) int {
+	n := matchSuffix(w, limit, nil, adjective)
+	if n == 0 {
+		return 0
+	}
+	return n + matchSuffix(w[:len(w)-n], limit, participle1, participle2)
+}
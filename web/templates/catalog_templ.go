@@ -0,0 +1,40 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.960
+package templates
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+func CatalogTransfer() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"card shadow-sm mb-4\" id=\"catalog-transfer\"><div class=\"card-header bg-info text-dark\"><h5 class=\"mb-0\"><i class=\"fas fa-exchange-alt me-2\"></i>Перенос данных</h5></div><div class=\"card-body\"><p class=\"form-text mt-0\">Выгрузка содержит единицы измерения, категории, материалы со всеми названиями, изделия с нормами и сведения о файлах. Сами файлы не выгружаются, папку загрузок нужно скопировать отдельно.</p><a href=\"/catalog/export\" class=\"btn btn-outline-primary\" download><i class=\"fas fa-download me-1\"></i> Выгрузить в JSON</a></div><div class=\"card-body border-top\"><form hx-post=\"/catalog/import\" hx-encoding=\"multipart/form-data\" hx-swap=\"none\" hx-confirm=\"Загрузить данные из файла?\" class=\"d-flex flex-column gap-2\"><label for=\"catalog_file\" class=\"form-label mb-0\">Загрузка из JSON</label> <input type=\"file\" class=\"form-control\" id=\"catalog_file\" name=\"file\" accept=\".json,application/json\" required><div class=\"form-check\"><input type=\"checkbox\" class=\"form-check-input\" id=\"catalog_replace\" name=\"replace\" value=\"1\"> <label for=\"catalog_replace\" class=\"form-check-label\">Заменить все текущие данные</label></div><div class=\"form-text mt-0\">Без замены загрузка возможна только в пустую базу данных.</div><div><button type=\"submit\" class=\"btn btn-primary\"><i class=\"fas fa-upload me-1\"></i> Загрузить</button></div></form></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -63,7 +63,15 @@ func SettingsForm(config *config.Config) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div></div><div class=\"card shadow-sm mb-4\"><div class=\"card-header bg-success text-white\"><h5 class=\"mb-0\"><i class=\"fas fa-database me-2\"></i>Настройки базы данных</h5></div><div class=\"card-body\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div><div class=\"card-body\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = HeadlessField(config).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div></div><div class=\"card shadow-sm mb-4\"><div class=\"card-header bg-success text-white\"><h5 class=\"mb-0\"><i class=\"fas fa-database me-2\"></i>Настройки базы данных</h5></div><div class=\"card-body\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -71,7 +79,15 @@ func SettingsForm(config *config.Config) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div></div><div class=\"card shadow-sm mb-4\"><div class=\"card-header bg-warning text-dark\"><h5 class=\"mb-0\"><i class=\"fas fa-clipboard-list me-2\"></i>Настройки логирования</h5></div><div class=\"card-body\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</div><div class=\"card-body\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = AppSearchIndexingField(config).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</div></div><div class=\"card shadow-sm mb-4\"><div class=\"card-header bg-warning text-dark\"><h5 class=\"mb-0\"><i class=\"fas fa-clipboard-list me-2\"></i>Настройки логирования</h5></div><div class=\"card-body\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -79,7 +95,31 @@ func SettingsForm(config *config.Config) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</div></div><div class=\"d-flex justify-content-between align-items-center\"><button type=\"button\" class=\"btn btn-outline-secondary\" hx-delete=\"/config\" hx-target=\"#settings-form\" hx-swap=\"outerHTML\" hx-confirm=\"Сбросить все настройки к значениям по умолчанию?\"><i class=\"fas fa-undo me-1\"></i> Сбросить все</button><div class=\"d-flex gap-2\"><button type=\"submit\" class=\"btn btn-primary\" hx-indicator=\"#settings-spinner\"><span id=\"settings-spinner\" class=\"spinner-border spinner-border-sm htmx-indicator me-1\" role=\"status\"></span> <i class=\"fas fa-save me-1\"></i> Сохранить</button></div></div><div id=\"settings-message\" class=\"mt-3\"></div></form>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</div></div><div class=\"card shadow-sm mb-4\"><div class=\"card-header bg-info text-dark\"><h5 class=\"mb-0\"><i class=\"fas fa-boxes me-2\"></i>Сводка по запасам</h5></div><div class=\"card-body\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = DigestFileField(config).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</div><div class=\"card-body\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = DigestWebhookField(config).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</div><div class=\"card-body\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = DigestTimeField(config).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</div></div><div class=\"d-flex justify-content-between align-items-center\"><button type=\"button\" class=\"btn btn-outline-secondary\" hx-delete=\"/config\" hx-target=\"#settings-form\" hx-swap=\"outerHTML\" hx-confirm=\"Сбросить все настройки к значениям по умолчанию?\"><i class=\"fas fa-undo me-1\"></i> Сбросить все</button><div class=\"d-flex gap-2\"><button type=\"submit\" class=\"btn btn-primary\" hx-indicator=\"#settings-spinner\"><span id=\"settings-spinner\" class=\"spinner-border spinner-border-sm htmx-indicator me-1\" role=\"status\"></span> <i class=\"fas fa-save me-1\"></i> Сохранить</button></div></div><div id=\"settings-message\" class=\"mt-3\"></div></form>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -108,20 +148,20 @@ func BaseDirectoryField(config *config.Config) templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<div id=\"base-directory-field\" class=\"mb-3\"><label for=\"base_directory\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Корневая директория</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/base_directory\" hx-target=\"#base-directory-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-folder-open\"></i></span> <input type=\"text\" class=\"form-control\" id=\"base_directory\" name=\"base_directory\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<div id=\"base-directory-field\" class=\"mb-3\"><label for=\"base_directory\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Корневая директория</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/base_directory\" hx-target=\"#base-directory-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-folder-open\"></i></span> <input type=\"text\" class=\"form-control\" id=\"base_directory\" name=\"base_directory\" value=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var3 string
 		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(config.BaseDirectory)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 97, Col: 32}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 118, Col: 32}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "\" required placeholder=\"e.g., data\"></div><div class=\"form-text\">Название папки где будут храниться данные приложения</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "\" required placeholder=\"e.g., data\"></div><div class=\"form-text\">Название папки где будут храниться данные приложения</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -150,58 +190,58 @@ func WebUIPasswordField(config *config.Config) templ.Component {
 			templ_7745c5c3_Var4 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<div id=\"web-ui-password-field\" class=\"mb-3\"><label class=\"form-label d-flex justify-content-between align-items-center\"><span>Пароль веб интерфейса</span> ")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div id=\"web-ui-password-field\" class=\"mb-3\"><label class=\"form-label d-flex justify-content-between align-items-center\"><span>Пароль веб интерфейса</span> ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if config.WebUIPassword != "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/web_ui_password\" hx-target=\"#web-ui-password-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "<button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/web_ui_password\" hx-target=\"#web-ui-password-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</label> ")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</label> ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if config.WebUIPassword != "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<div class=\"d-flex align-items-center gap-2\"><span class=\"text-muted\">••••••••</span> <button type=\"button\" class=\"btn btn-outline-secondary btn-sm\" hx-delete=\"/config/web_ui_password\" hx-target=\"#web-ui-password-field\" hx-swap=\"outerHTML\" hx-confirm=\"Сбросить пароль веб интерфейса?\"><i class=\"fas fa-trash me-1\"></i> Удалить пароль</button></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<div class=\"d-flex align-items-center gap-2\"><span class=\"text-muted\">••••••••</span> <button type=\"button\" class=\"btn btn-outline-secondary btn-sm\" hx-delete=\"/config/web_ui_password\" hx-target=\"#web-ui-password-field\" hx-swap=\"outerHTML\" hx-confirm=\"Сбросить пароль веб интерфейса?\"><i class=\"fas fa-trash me-1\"></i> Удалить пароль</button></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		} else {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<div class=\"row g-2\"><div class=\"col-md-6\"><label for=\"web_ui_password\" class=\"form-label\">Новый пароль</label><div class=\"input-group\"><input type=\"password\" class=\"form-control\" id=\"web_ui_password\" name=\"web_ui_password\" value=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<div class=\"row g-2\"><div class=\"col-md-6\"><label for=\"web_ui_password\" class=\"form-label\">Новый пароль</label><div class=\"input-group\"><input type=\"password\" class=\"form-control\" id=\"web_ui_password\" name=\"web_ui_password\" value=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var5 string
 			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(config.WebUIPassword)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 147, Col: 35}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 168, Col: 35}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "\" placeholder=\"введите пароль\"></div></div><div class=\"col-md-6\"><label for=\"web_ui_password_confirm\" class=\"form-label\">Подтверждение</label><div class=\"input-group\"><input type=\"password\" class=\"form-control\" id=\"web_ui_password_confirm\" name=\"web_ui_password_confirm\" value=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "\" placeholder=\"введите пароль\"></div></div><div class=\"col-md-6\"><label for=\"web_ui_password_confirm\" class=\"form-label\">Подтверждение</label><div class=\"input-group\"><input type=\"password\" class=\"form-control\" id=\"web_ui_password_confirm\" name=\"web_ui_password_confirm\" value=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var6 string
 			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(config.WebUIPassword)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 160, Col: 35}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 181, Col: 35}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\" placeholder=\"повторите пароль\"></div></div></div><div class=\"form-text mt-1\">Оставьте пустым, чтобы не устанавливать пароль</div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "\" placeholder=\"повторите пароль\"></div></div></div><div class=\"form-text mt-1\">Оставьте пустым, чтобы не устанавливать пароль</div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -230,20 +270,20 @@ func ServerPortField(config *config.Config) templ.Component {
 			templ_7745c5c3_Var7 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<div id=\"server-port-field\" class=\"mb-3\"><label for=\"server_port\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Порт</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/server_port\" hx-target=\"#server-port-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-network-wired\"></i></span> <input type=\"number\" class=\"form-control\" id=\"server_port\" name=\"server.server_port\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<div id=\"server-port-field\" class=\"mb-3\"><label for=\"server_port\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Порт</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/server_port\" hx-target=\"#server-port-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-network-wired\"></i></span> <input type=\"number\" class=\"form-control\" id=\"server_port\" name=\"server.server_port\" value=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var8 string
 		templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(config.ServerCfg.ServerPort)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 193, Col: 39}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 214, Col: 39}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "\" required placeholder=\"8080\" min=\"0\" max=\"65535\"></div><div class=\"form-text\">Порт который использует сервер. Если указано значение 0 - порт будет назначаться операционной системой</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "\" required placeholder=\"8080\" min=\"0\" max=\"65535\"></div><div class=\"form-text\">Порт который использует сервер. Если указано значение 0 - порт будет назначаться операционной системой</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -272,20 +312,20 @@ func UploadsDirectoryField(config *config.Config) templ.Component {
 			templ_7745c5c3_Var9 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<div id=\"uploads-directory-field\" class=\"mb-3\"><label for=\"uploads_directory\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Директория загрузок</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/uploads_directory\" hx-target=\"#uploads-directory-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-upload\"></i></span> <input type=\"text\" class=\"form-control\" id=\"uploads_directory\" name=\"server.uploads_directory\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<div id=\"uploads-directory-field\" class=\"mb-3\"><label for=\"uploads_directory\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Директория загрузок</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/uploads_directory\" hx-target=\"#uploads-directory-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-upload\"></i></span> <input type=\"text\" class=\"form-control\" id=\"uploads_directory\" name=\"server.uploads_directory\" value=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var10 string
 		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(config.ServerCfg.UploadsDir)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 226, Col: 39}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 247, Col: 39}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "\" required placeholder=\"e.g., uploads\"></div><div class=\"form-text\">Название папки в которой будут хранится прикреплённые файлы, относительно корневой директории.</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "\" required placeholder=\"e.g., uploads\"></div><div class=\"form-text\">Название папки в которой будут хранится прикреплённые файлы, относительно корневой директории.</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -293,7 +333,7 @@ func UploadsDirectoryField(config *config.Config) templ.Component {
 	})
 }
 
-func DatabaseNameField(config *config.Config) templ.Component {
+func HeadlessField(config *config.Config) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -314,20 +354,98 @@ func DatabaseNameField(config *config.Config) templ.Component {
 			templ_7745c5c3_Var11 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<div id=\"database-name-field\" class=\"mb-3\"><label for=\"database_name\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Название базы данных</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/database_name\" hx-target=\"#database-name-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-database\"></i></span> <input type=\"text\" class=\"form-control\" id=\"database_name\" name=\"database.database_name\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<div id=\"headless-field\" class=\"mb-3\"><label class=\"form-label d-flex justify-content-between align-items-center\"><span>Серверный режим</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/headless\" hx-target=\"#headless-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"form-check form-switch\"><input type=\"checkbox\" class=\"form-check-input\" id=\"headless\" name=\"server.headless\" value=\"1\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var12 string
-		templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(config.DBCfg.DBName)
+		if config.ServerCfg.Headless {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, " checked")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "> <label class=\"form-check-label\" for=\"headless\">Не открывать браузер при запуске</label></div><div class=\"form-text\">В этом режиме закрытие вкладки браузера не останавливает сервер. Используйте, если приложение работает на общем компьютере для нескольких пользователей. Вступает в силу после перезапуска.</div></div>")
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 257, Col: 31}
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func DatabaseNameField(config *config.Config) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var12 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var12 == nil {
+			templ_7745c5c3_Var12 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "<div id=\"database-name-field\" class=\"mb-3\"><label for=\"database_name\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Название базы данных</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/database_name\" hx-target=\"#database-name-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-database\"></i></span> <input type=\"text\" class=\"form-control\" id=\"database_name\" name=\"database.database_name\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var13 string
+		templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(config.DBCfg.DBName)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 308, Col: 31}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "\" required placeholder=\"e.g., database.db\"></div><div class=\"form-text\">Название файла базы данных, относительно корневой директории.</div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func AppSearchIndexingField(config *config.Config) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var14 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var14 == nil {
+			templ_7745c5c3_Var14 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "<div id=\"app-search-indexing-field\" class=\"mb-3\"><label class=\"form-label d-flex justify-content-between align-items-center\"><span>Индексация поиска</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/app_search_indexing\" hx-target=\"#app-search-indexing-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"form-check form-switch\"><input type=\"checkbox\" class=\"form-check-input\" id=\"app_search_indexing\" name=\"database.app_search_indexing\" value=\"1\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" required placeholder=\"e.g., database.db\"></div><div class=\"form-text\">Название файла базы данных, относительно корневой директории.</div></div>")
+		if config.DBCfg.AppIndexing {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, " checked")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "> <label class=\"form-check-label\" for=\"app_search_indexing\">Расширенный поисковый индекс</label></div><div class=\"form-text\">Поиск материалов также учитывает единицу измерения, изделия в которых используется материал и названия прикреплённых файлов. Индекс будет перестроен при следующем запуске.</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -351,12 +469,12 @@ func LogLevelField(config *config.Config) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var13 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var13 == nil {
-			templ_7745c5c3_Var13 = templ.NopComponent
+		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var15 == nil {
+			templ_7745c5c3_Var15 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<div id=\"log-level-field\" class=\"mb-3\"><label for=\"log_level\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Уровень логирования</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/log_level\" hx-target=\"#log-level-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label> <select class=\"form-select\" id=\"log_level\" name=\"log.log_level\" required>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "<div id=\"log-level-field\" class=\"mb-3\"><label for=\"log_level\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Уровень логирования</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/log_level\" hx-target=\"#log-level-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label> <select class=\"form-select\" id=\"log_level\" name=\"log.log_level\" required>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -364,7 +482,133 @@ func LogLevelField(config *config.Config) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</select><div class=\"form-text\">Минимальная значимость для записи лога.</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "</select><div class=\"form-text\">Минимальная значимость для записи лога.</div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func DigestFileField(config *config.Config) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var16 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var16 == nil {
+			templ_7745c5c3_Var16 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "<div id=\"digest-file-field\" class=\"mb-3\"><label for=\"digest_file\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Файл сводки</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/digest_file\" hx-target=\"#digest-file-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-file-alt\"></i></span> <input type=\"text\" class=\"form-control\" id=\"digest_file\" name=\"digest.file\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var17 string
+		templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(config.DigestCfg.File)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 396, Col: 33}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "\" placeholder=\"e.g., data/reorder.txt\"></div><div class=\"form-text\">Файл, в который ежедневно записывается список материалов к заказу. Оставьте пустым, чтобы не записывать.</div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func DigestWebhookField(config *config.Config) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var18 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var18 == nil {
+			templ_7745c5c3_Var18 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "<div id=\"digest-webhook-field\" class=\"mb-3\"><label for=\"digest_webhook\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Вебхук сводки</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/digest_webhook\" hx-target=\"#digest-webhook-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-link\"></i></span> <input type=\"url\" class=\"form-control\" id=\"digest_webhook\" name=\"digest.webhook\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var19 string
+		templ_7745c5c3_Var19, templ_7745c5c3_Err = templ.JoinStringErrs(config.DigestCfg.Webhook)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 426, Col: 36}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "\" placeholder=\"e.g., http://localhost:9000/reorder\"></div><div class=\"form-text\">Адрес в локальной сети, на который сводка отправляется POST-запросом в формате JSON. Оставьте пустым, чтобы не отправлять.</div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func DigestTimeField(config *config.Config) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var20 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var20 == nil {
+			templ_7745c5c3_Var20 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "<div id=\"digest-time-field\" class=\"mb-3\"><label for=\"digest_time\" class=\"form-label d-flex justify-content-between align-items-center\"><span>Время сводки</span> <button type=\"button\" class=\"btn btn-sm btn-outline-secondary\" hx-delete=\"/config/digest_time\" hx-target=\"#digest-time-field\" hx-swap=\"outerHTML\" title=\"Сбросить к значению по умолчанию\"><i class=\"fas fa-undo fa-xs\"></i></button></label><div class=\"input-group\"><span class=\"input-group-text\"><i class=\"fas fa-clock\"></i></span> <input type=\"time\" class=\"form-control\" id=\"digest_time\" name=\"digest.time\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var21 string
+		templ_7745c5c3_Var21, templ_7745c5c3_Err = templ.JoinStringErrs(config.DigestCfg.Time)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 456, Col: 33}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var21))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "\" required></div><div class=\"form-text\">Сводка формируется ежедневно в это время, пока приложение запущено.</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -388,52 +632,52 @@ func logLevelOptions(currentLevel string) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var14 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var14 == nil {
-			templ_7745c5c3_Var14 = templ.NopComponent
+		templ_7745c5c3_Var22 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var22 == nil {
+			templ_7745c5c3_Var22 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<option value=\"DEBUG\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "<option value=\"DEBUG\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if "DEBUG" == currentLevel {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, " selected")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, " selected")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, ">DEBUG - Все сообщения</option> <option value=\"INFO\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, ">DEBUG - Все сообщения</option> <option value=\"INFO\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if "INFO" == currentLevel {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, " selected")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, " selected")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, ">INFO - Информационные, предупреждения и ошибки</option> <option value=\"WARN\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, ">INFO - Информационные, предупреждения и ошибки</option> <option value=\"WARN\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if "WARN" == currentLevel {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, " selected")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, " selected")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, ">WARN - Предупреждения и ошибки</option> <option value=\"ERROR\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, ">WARN - Предупреждения и ошибки</option> <option value=\"ERROR\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if "ERROR" == currentLevel {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, " selected")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, " selected")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, ">ERROR - Только ошибки</option>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, ">ERROR - Только ошибки</option>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -457,40 +701,40 @@ func SettingsMessage(success bool, message string) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var15 == nil {
-			templ_7745c5c3_Var15 = templ.NopComponent
+		templ_7745c5c3_Var23 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var23 == nil {
+			templ_7745c5c3_Var23 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "<div")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, "<div")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if success {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, " class=\"alert alert-success alert-dismissible fade show\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, " class=\"alert alert-success alert-dismissible fade show\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		} else {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, " class=\"alert alert-danger alert-dismissible fade show\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, " class=\"alert alert-danger alert-dismissible fade show\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, " role=\"alert\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, " role=\"alert\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var16 string
-		templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(message)
+		var templ_7745c5c3_Var24 string
+		templ_7745c5c3_Var24, templ_7745c5c3_Err = templ.JoinStringErrs(message)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 329, Col: 11}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 500, Col: 11}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var24))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -514,9 +758,9 @@ func SettingsField(field string, config *config.Config) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var17 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var17 == nil {
-			templ_7745c5c3_Var17 = templ.NopComponent
+		templ_7745c5c3_Var25 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var25 == nil {
+			templ_7745c5c3_Var25 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		switch field {
@@ -540,31 +784,56 @@ func SettingsField(field string, config *config.Config) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
+		case "headless":
+			templ_7745c5c3_Err = HeadlessField(config).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
 		case "database_name":
 			templ_7745c5c3_Err = DatabaseNameField(config).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
+		case "app_search_indexing":
+			templ_7745c5c3_Err = AppSearchIndexingField(config).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
 		case "log_level":
 			templ_7745c5c3_Err = LogLevelField(config).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
+		case "digest_file":
+			templ_7745c5c3_Err = DigestFileField(config).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		case "digest_webhook":
+			templ_7745c5c3_Err = DigestWebhookField(config).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		case "digest_time":
+			templ_7745c5c3_Err = DigestTimeField(config).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
 		default:
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "<div class=\"alert alert-warning\" role=\"alert\">Неизвестное поле настройки: ")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 57, "<div class=\"alert alert-warning\" role=\"alert\">Неизвестное поле настройки: ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var18 string
-			templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs(field)
+			var templ_7745c5c3_Var26 string
+			templ_7745c5c3_Var26, templ_7745c5c3_Err = templ.JoinStringErrs(field)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 349, Col: 71}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/settings.templ`, Line: 530, Col: 71}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var26))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "</div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 58, "</div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}